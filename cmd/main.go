@@ -3,59 +3,92 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"time"
 
-	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"surf_challenge/internal/api/router"
+	"surf_challenge/internal/config"
 	"surf_challenge/internal/container"
-)
-
-const (
-	port              = 3000
-	readTimeout       = 15 * time.Second
-	readHeaderTimeout = 5 * time.Second
-	writeTimeout      = 15 * time.Second
-	idleTimeout       = 60 * time.Second
-	headerBytes       = 1 << 20
-	duration          = 20 * time.Second
+	"surf_challenge/internal/logger"
+	"surf_challenge/internal/rpc"
 )
 
 func main() {
-	logger, err := zap.NewProduction()
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
 	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+
 		log.Fatal(err)
 	}
 
-	sugar := logger.Sugar()
+	zapLogger, err := logger.New(cfg.Log)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sugar := zapLogger.Sugar()
 
 	defer func() {
-		_ = logger.Sync()
+		_ = zapLogger.Sync()
 	}()
 
-	sugar.Info("Logger initialized")
+	sugar.Infow("Logger initialized", "level", cfg.Log.Level, "format", cfg.Log.Format)
+
+	dependencies, err := container.NewAppContainer(sugar, cfg)
+	if err != nil {
+		sugar.Fatalw("failed to initialize dependencies", "error", err)
+	}
 
-	dependencies := container.NewAppContainer(sugar)
+	// Load the datasets upfront so the first requests are fast. /readyz reports not ready
+	// until they are loaded.
+	go func() {
+		report := dependencies.Health.Load(context.Background())
+		for _, dataset := range report.Datasets {
+			if dataset.Err != nil {
+				sugar.Errorw("failed to load dataset", "dataset", dataset.Name, "error", dataset.Err)
+
+				continue
+			}
+
+			sugar.Infow("Dataset loaded", "dataset", dataset.Name, "records", dataset.Records)
+		}
+	}()
+
+	// Publish the actions ingested from now on to the stream subscribers and webhooks.
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+
+	if (cfg.Stream.Enabled && cfg.Features.Analytics) || cfg.Webhooks.Enabled {
+		go dependencies.Feed.Watch(watchCtx, sugar, dependencies.FeedSource, cfg.Stream.PollInterval)
+	}
+
+	if cfg.Webhooks.Enabled {
+		go dependencies.WebhookDispatcher.Run(watchCtx, dependencies.Feed)
+	}
 
 	mux := router.New(sugar, dependencies)
 
-	addr := fmt.Sprintf(":%d", port)
+	addr := fmt.Sprintf(":%d", cfg.Server.Port)
 
 	sugar.Infof("Starting server on %s", addr)
 
 	srv := &http.Server{
 		Addr:              addr,
 		Handler:           mux,
-		ReadTimeout:       readTimeout,
-		ReadHeaderTimeout: readHeaderTimeout,
-		WriteTimeout:      writeTimeout,
-		IdleTimeout:       idleTimeout,
-		MaxHeaderBytes:    headerBytes,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
 
 	go func() {
@@ -65,6 +98,28 @@ func main() {
 		}
 	}()
 
+	var grpcServer *grpc.Server
+
+	if cfg.GRPC.Enabled {
+		grpcAddr := fmt.Sprintf(":%d", cfg.GRPC.Port)
+
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			sugar.Fatalw("failed to listen for gRPC", "address", grpcAddr, "error", err)
+		}
+
+		grpcServer = rpc.NewServer(sugar, dependencies)
+
+		sugar.Infof("Starting gRPC server on %s", grpcAddr)
+
+		go func() {
+			err := grpcServer.Serve(listener)
+			if err != nil {
+				log.Fatalf("gRPC Serve: %v", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt)
@@ -72,7 +127,7 @@ func main() {
 
 	sugar.Info("Received interrupt signal, shutting down...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	err = srv.Shutdown(ctx)
@@ -80,5 +135,31 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
-	logger.Info("Server exiting")
+	if grpcServer != nil {
+		stopGRPC(ctx, grpcServer)
+	}
+
+	err = dependencies.Tracing.Shutdown(ctx)
+	if err != nil {
+		sugar.Warnw("failed to flush traces", "error", err)
+	}
+
+	zapLogger.Info("Server exiting")
+}
+
+// stopGRPC waits for the in-flight calls to complete, closing the remaining streams
+// when ctx expires.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
 }
@@ -0,0 +1,20 @@
+// Command surfctl queries the Surf datasets, from a running server or directly from the
+// data files. Run surfctl -h for the commands.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"surf_challenge/internal/cli"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	code := cli.Run(ctx, os.Args[1:], os.LookupEnv, os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,103 @@
+package action
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"surf_challenge/internal/cache"
+)
+
+// CacheName labels the analytics result cache in metrics.
+const CacheName = "analytics"
+
+// VersionFunc returns the current version of the actions dataset.
+type VersionFunc func(ctx context.Context) (string, error)
+
+// CacheObserver is told whether each lookup in the named cache was a hit.
+type CacheObserver func(cache string, hit bool)
+
+// cachedService decorates a Service caching the analytics results by operation,
+// normalized parameters and dataset version, so a dataset change is never served stale
+// results. Cached maps are shared between callers and must not be modified.
+type cachedService struct {
+	Service
+	results *cache.Cache[string, any]
+	version VersionFunc
+	observe CacheObserver
+
+	mu          sync.Mutex
+	lastVersion string
+}
+
+func NewCachedService(next Service, results *cache.Cache[string, any], version VersionFunc, observe CacheObserver) Service {
+	return &cachedService{
+		Service: next,
+		results: results,
+		version: version,
+		observe: observe,
+	}
+}
+
+func (s *cachedService) GetNextActionProbability(ctx context.Context, action string) (map[string]string, error) {
+	// The computation compares action types case-insensitively.
+	params := strings.ToUpper(strings.TrimSpace(action))
+
+	return cached(
+		ctx, s, OperationNextActionProbability, params, func() (map[string]string, error) {
+			return s.Service.GetNextActionProbability(ctx, action)
+		},
+	)
+}
+
+func (s *cachedService) GetUsersReferrals(ctx context.Context) (map[int]int, error) {
+	return cached(
+		ctx, s, OperationUsersReferrals, "", func() (map[int]int, error) {
+			return s.Service.GetUsersReferrals(ctx)
+		},
+	)
+}
+
+func cached[T any](ctx context.Context, s *cachedService, operation, params string, compute func() (T, error)) (T, error) {
+	version, err := s.version(ctx)
+	if err != nil {
+		// Let the wrapped service report the dataset error.
+		return compute()
+	}
+
+	s.invalidate(version)
+
+	key := operation + "|" + params + "|" + version
+
+	if value, ok := s.results.Get(key); ok {
+		if result, ok := value.(T); ok {
+			s.observe(CacheName, true)
+
+			return result, nil
+		}
+	}
+
+	s.observe(CacheName, false)
+
+	result, err := compute()
+	if err != nil {
+		return result, err
+	}
+
+	s.results.Set(key, result)
+
+	return result, nil
+}
+
+// invalidate drops every entry computed from a previous dataset version, which could
+// otherwise only leave the cache by expiring.
+func (s *cachedService) invalidate(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastVersion != "" && s.lastVersion != version {
+		s.results.Purge()
+	}
+
+	s.lastVersion = version
+}
@@ -0,0 +1,69 @@
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"surf_challenge/internal/cache"
+)
+
+func Test_cachedService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	next := NewMockService(ctrl)
+
+	version := "v1"
+	versionErr := error(nil)
+
+	var lookups []bool
+
+	results := cache.New[string, any](time.Minute, 10)
+	s := NewCachedService(
+		next, results,
+		func(context.Context) (string, error) { return version, versionErr },
+		func(name string, hit bool) {
+			assert.Equal(t, CacheName, name)
+
+			lookups = append(lookups, hit)
+		},
+	)
+
+	next.EXPECT().GetNextActionProbability(gomock.Any(), "refer_user").Return(map[string]string{"A": "1.00"}, nil)
+
+	got, err := s.GetNextActionProbability(t.Context(), "refer_user")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1.00"}, got)
+
+	got, err = s.GetNextActionProbability(t.Context(), " REFER_USER ")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1.00"}, got, "normalized parameters should share the entry")
+	assert.Equal(t, []bool{false, true}, lookups)
+
+	next.EXPECT().GetUsersReferrals(gomock.Any()).Return(nil, assert.AnError)
+
+	_, err = s.GetUsersReferrals(t.Context())
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 1, results.Len(), "errors should not be cached")
+
+	version = "v2"
+
+	next.EXPECT().GetNextActionProbability(gomock.Any(), "REFER_USER").Return(map[string]string{"B": "1.00"}, nil)
+
+	got, err = s.GetNextActionProbability(t.Context(), "REFER_USER")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"B": "1.00"}, got, "a new dataset version should not be served stale results")
+	assert.Equal(t, 1, results.Len(), "entries of the previous version should be purged")
+
+	versionErr = assert.AnError
+
+	next.EXPECT().GetUsersReferrals(gomock.Any()).Return(nil, ErrDataUnavailable)
+
+	_, err = s.GetUsersReferrals(t.Context())
+	assert.ErrorIs(t, err, ErrDataUnavailable, "the wrapped service should report dataset errors")
+}
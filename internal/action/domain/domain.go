@@ -1,11 +1,25 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 const (
-	ActionTypeReferUser = "REFER_USER"
+	ActionTypeWelcome      = "WELCOME"
+	ActionTypeConnectCRM   = "CONNECT_CRM"
+	ActionTypeViewContacts = "VIEW_CONTACTS"
+	ActionTypeAddContact   = "ADD_CONTACT"
+	ActionTypeEditContact  = "EDIT_CONTACT"
+	ActionTypeReferUser    = "REFER_USER"
 )
 
+// ActionTypes lists the action types the product emits.
+var ActionTypes = []string{
+	ActionTypeWelcome, ActionTypeConnectCRM, ActionTypeViewContacts, ActionTypeAddContact, ActionTypeEditContact,
+	ActionTypeReferUser,
+}
+
 type Action struct {
 	ID         int
 	Type       string
@@ -14,6 +28,21 @@ type Action struct {
 	CreatedAt  time.Time
 }
 
+// Filter selects actions. Zero values match every action.
+type Filter struct {
+	UserID *int64
+	// Type is compared case-insensitively.
+	Type string
+}
+
+func (f Filter) Matches(a *Action) bool {
+	if f.UserID != nil && int64(a.UserID) != *f.UserID {
+		return false
+	}
+
+	return f.Type == "" || strings.EqualFold(a.Type, f.Type)
+}
+
 // Graph represents a directed graph where each node is a user and edges represent invitations.
 // If simplification is needed, needs to change nodes map to map[int][]int
 type Graph struct {
@@ -68,6 +97,21 @@ func (g *Graph) getOrCreate(userID int) *Node {
 	return n
 }
 
+// Children returns the users directly invited by userID, in invitation order.
+func (g *Graph) Children(userID int) []int {
+	n, ok := g.nodes[userID]
+	if !ok {
+		return nil
+	}
+
+	children := make([]int, len(n.Children))
+	for i, c := range n.Children {
+		children[i] = c.UserID
+	}
+
+	return children
+}
+
 func (g *Graph) ReferralCount(userID int) int {
 	root, ok := g.nodes[userID]
 	if !ok {
@@ -0,0 +1,46 @@
+package action
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OperationNextActionProbability = "next_action_probability"
+	OperationUsersReferrals        = "users_referrals"
+)
+
+// ComputationObserver receives how long an analytics computation took and its outcome.
+type ComputationObserver func(operation string, duration time.Duration, err error)
+
+// instrumentedService decorates a Service timing the analytics computations, which scan
+// the whole dataset. Lookups by user are passed through untouched.
+type instrumentedService struct {
+	Service
+	observe ComputationObserver
+}
+
+func NewInstrumentedService(next Service, observe ComputationObserver) Service {
+	return &instrumentedService{
+		Service: next,
+		observe: observe,
+	}
+}
+
+func (s *instrumentedService) GetNextActionProbability(ctx context.Context, action string) (map[string]string, error) {
+	start := time.Now()
+
+	probability, err := s.Service.GetNextActionProbability(ctx, action)
+	s.observe(OperationNextActionProbability, time.Since(start), err)
+
+	return probability, err
+}
+
+func (s *instrumentedService) GetUsersReferrals(ctx context.Context) (map[int]int, error) {
+	start := time.Now()
+
+	referrals, err := s.Service.GetUsersReferrals(ctx)
+	s.observe(OperationUsersReferrals, time.Since(start), err)
+
+	return referrals, err
+}
@@ -0,0 +1,85 @@
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_instrumentedService(t *testing.T) {
+	type observation struct {
+		operation string
+		err       error
+	}
+
+	tests := []struct {
+		name string
+		mock func(m *MockService)
+		call func(s Service) error
+		want []observation
+	}{
+		{
+			name: "should observe next action probability computation",
+			mock: func(m *MockService) {
+				m.EXPECT().GetNextActionProbability(gomock.Any(), "A").Return(map[string]string{}, nil)
+			},
+			call: func(s Service) error {
+				_, err := s.GetNextActionProbability(t.Context(), "A")
+
+				return err
+			},
+			want: []observation{{operation: OperationNextActionProbability}},
+		},
+		{
+			name: "should observe failed referrals computation",
+			mock: func(m *MockService) {
+				m.EXPECT().GetUsersReferrals(gomock.Any()).Return(nil, assert.AnError)
+			},
+			call: func(s Service) error {
+				_, err := s.GetUsersReferrals(t.Context())
+
+				return err
+			},
+			want: []observation{{operation: OperationUsersReferrals, err: assert.AnError}},
+		},
+		{
+			name: "should not observe lookups by user",
+			mock: func(m *MockService) {
+				m.EXPECT().GetActionByUserID(gomock.Any(), int64(1)).Return(nil, nil)
+			},
+			call: func(s Service) error {
+				_, err := s.GetActionByUserID(t.Context(), 1)
+
+				return err
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				next := NewMockService(ctrl)
+				tt.mock(next)
+
+				var got []observation
+
+				s := NewInstrumentedService(
+					next, func(operation string, duration time.Duration, err error) {
+						assert.GreaterOrEqual(t, duration, time.Duration(0))
+
+						got = append(got, observation{operation: operation, err: err})
+					},
+				)
+
+				_ = tt.call(s)
+
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
@@ -2,6 +2,7 @@ package action
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -11,15 +12,35 @@ import (
 	"surf_challenge/internal/action/domain"
 	"surf_challenge/internal/action/mapper"
 	"surf_challenge/internal/action/storage"
+	"surf_challenge/internal/logger"
 )
 
 const indexActionsNeeded = 2
 
+var (
+	// ErrDataUnavailable is returned when the actions dataset cannot be read.
+	ErrDataUnavailable = errors.New("actions data unavailable")
+	// ErrInvalidData is returned when stored actions cannot be mapped to the domain.
+	ErrInvalidData = errors.New("invalid actions data")
+)
+
+// Service computes the actions analytics. The maps returned by GetNextActionProbability
+// and GetUsersReferrals may be shared with other callers, when cached, and must not be
+// modified.
+//
 //go:generate mockgen -source=service.go -destination=service_mock.go -package=action
 type Service interface {
 	GetActionByUserID(ctx context.Context, userID int64) ([]*domain.Action, error)
 	GetNextActionProbability(ctx context.Context, action string) (map[string]string, error)
 	GetUsersReferrals(ctx context.Context) (map[int]int, error)
+	// ListActions returns the actions matching filter in chronological order.
+	ListActions(ctx context.Context, filter domain.Filter) ([]*domain.Action, error)
+	// GetActionsByUserIDs returns the actions of each user in chronological order, users
+	// without actions being left out, reading the dataset once for the whole batch.
+	GetActionsByUserIDs(ctx context.Context, userIDs []int64) (map[int64][]*domain.Action, error)
+	// GetReferredUsers returns the users directly referred by each user, in referral
+	// order, users without referrals being left out.
+	GetReferredUsers(ctx context.Context, userIDs []int64) (map[int64][]int64, error)
 }
 
 type service struct {
@@ -35,32 +56,51 @@ func NewService(logger *zap.SugaredLogger, repo storage.Repository) Service {
 }
 
 func (s service) GetActionByUserID(ctx context.Context, userID int64) ([]*domain.Action, error) {
-	s.logger.Infow("GetActionByUserID called", "userID", userID)
+	logger.FromContext(ctx, s.logger).Infow("GetActionByUserID called", "userID", userID)
 
 	actions, err := s.repo.GetActionsByUserID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get actions by userID %d: %w", userID, err)
+		if errors.Is(err, storage.ErrActionsNotFound) {
+			return []*domain.Action{}, nil
+		}
+
+		return nil, fmt.Errorf("%w: failed to get actions by userID %d: %w", ErrDataUnavailable, userID, err)
 	}
 
 	actionsDomain, err := mapper.MapActionsEntToDomain(actions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to map actions to domain: %w", err)
+		return nil, fmt.Errorf("%w: failed to map actions to domain: %w", ErrInvalidData, err)
 	}
 
 	return actionsDomain, nil
 }
 
-func (s service) GetNextActionProbability(ctx context.Context, action string) (map[string]string, error) {
-	s.logger.Infow("GetNextActionProbability called", "action", action)
-
+// getAllActions returns every stored action mapped to the domain. An empty dataset
+// is not an error, callers get an empty slice.
+func (s service) getAllActions(ctx context.Context) ([]*domain.Action, error) {
 	actions, err := s.repo.GetAllActions(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all actions: %w", err)
+		if errors.Is(err, storage.ErrActionsNotFound) {
+			return []*domain.Action{}, nil
+		}
+
+		return nil, fmt.Errorf("%w: failed to get all actions: %w", ErrDataUnavailable, err)
 	}
 
 	domainActions, err := mapper.MapActionsEntToDomain(actions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to map actions to domain: %w", err)
+		return nil, fmt.Errorf("%w: failed to map actions to domain: %w", ErrInvalidData, err)
+	}
+
+	return domainActions, nil
+}
+
+func (s service) GetNextActionProbability(ctx context.Context, action string) (map[string]string, error) {
+	logger.FromContext(ctx, s.logger).Infow("GetNextActionProbability called", "action", action)
+
+	domainActions, err := s.getAllActions(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	userActionsMap := make(map[int64][]*domain.Action)
@@ -106,6 +146,27 @@ func (s service) GetNextActionProbability(ctx context.Context, action string) (m
 	return probabilityMap, nil
 }
 
+func (s service) ListActions(ctx context.Context, filter domain.Filter) ([]*domain.Action, error) {
+	logger.FromContext(ctx, s.logger).Infow("ListActions called", "userID", filter.UserID, "type", filter.Type)
+
+	domainActions, err := s.getAllActions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]*domain.Action, 0, len(domainActions))
+
+	for _, act := range domainActions {
+		if filter.Matches(act) {
+			actions = append(actions, act)
+		}
+	}
+
+	slices.SortFunc(actions, sortByCreatedAt())
+
+	return actions, nil
+}
+
 func sortByCreatedAt() func(i *domain.Action, j *domain.Action) int {
 	return func(i, j *domain.Action) int {
 		if i.CreatedAt.Equal(j.CreatedAt) { // tie breaker
@@ -124,28 +185,64 @@ func sortByCreatedAt() func(i *domain.Action, j *domain.Action) int {
 	}
 }
 
-func (s service) GetUsersReferrals(ctx context.Context) (map[int]int, error) {
-	s.logger.Infow("GetUsersReferrals called")
+func (s service) GetActionsByUserIDs(ctx context.Context, userIDs []int64) (map[int64][]*domain.Action, error) {
+	logger.FromContext(ctx, s.logger).Infow("GetActionsByUserIDs called", "userIDs", userIDs)
 
-	actions, err := s.repo.GetAllActions(ctx)
+	domainActions, err := s.getAllActions(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all actions: %w", err)
+		return nil, err
 	}
 
-	domainActions, err := mapper.MapActionsEntToDomain(actions)
-	if err != nil {
-		return nil, fmt.Errorf("failed to map actions to domain: %w", err)
+	actions := make(map[int64][]*domain.Action, len(userIDs))
+	for _, userID := range userIDs {
+		actions[userID] = nil
 	}
 
-	graph := domain.NewGraph()
-	usersSet := make(map[int]struct{})
-
 	for _, act := range domainActions {
-		usersSet[act.UserID] = struct{}{}
+		userID := int64(act.UserID)
+		if _, ok := actions[userID]; ok {
+			actions[userID] = append(actions[userID], act)
+		}
+	}
 
-		if strings.EqualFold(act.Type, domain.ActionTypeReferUser) {
-			graph.AddEdge(act.UserID, act.TargetUser)
+	for userID, userActions := range actions {
+		if len(userActions) == 0 {
+			delete(actions, userID)
+
+			continue
 		}
+
+		slices.SortFunc(userActions, sortByCreatedAt())
+	}
+
+	return actions, nil
+}
+
+func (s service) GetReferredUsers(ctx context.Context, userIDs []int64) (map[int64][]int64, error) {
+	logger.FromContext(ctx, s.logger).Infow("GetReferredUsers called", "userIDs", userIDs)
+
+	graph, _, err := s.referralGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referred := make(map[int64][]int64, len(userIDs))
+
+	for _, userID := range userIDs {
+		for _, child := range graph.Children(int(userID)) {
+			referred[userID] = append(referred[userID], int64(child))
+		}
+	}
+
+	return referred, nil
+}
+
+func (s service) GetUsersReferrals(ctx context.Context) (map[int]int, error) {
+	logger.FromContext(ctx, s.logger).Infow("GetUsersReferrals called")
+
+	graph, usersSet, err := s.referralGraph(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	referralCount := make(map[int]int)
@@ -159,3 +256,25 @@ func (s service) GetUsersReferrals(ctx context.Context) (map[int]int, error) {
 
 	return referralCount, nil
 }
+
+// referralGraph builds the graph of REFER_USER actions, and returns it along with the
+// set of users having performed any action.
+func (s service) referralGraph(ctx context.Context) (*domain.Graph, map[int]struct{}, error) {
+	domainActions, err := s.getAllActions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	graph := domain.NewGraph()
+	usersSet := make(map[int]struct{})
+
+	for _, act := range domainActions {
+		usersSet[act.UserID] = struct{}{}
+
+		if strings.EqualFold(act.Type, domain.ActionTypeReferUser) {
+			graph.AddEdge(act.UserID, act.TargetUser)
+		}
+	}
+
+	return graph, usersSet, nil
+}
@@ -56,6 +56,21 @@ func (mr *MockServiceMockRecorder) GetActionByUserID(ctx, userID any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActionByUserID", reflect.TypeOf((*MockService)(nil).GetActionByUserID), ctx, userID)
 }
 
+// GetActionsByUserIDs mocks base method.
+func (m *MockService) GetActionsByUserIDs(ctx context.Context, userIDs []int64) (map[int64][]*domain.Action, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActionsByUserIDs", ctx, userIDs)
+	ret0, _ := ret[0].(map[int64][]*domain.Action)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActionsByUserIDs indicates an expected call of GetActionsByUserIDs.
+func (mr *MockServiceMockRecorder) GetActionsByUserIDs(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActionsByUserIDs", reflect.TypeOf((*MockService)(nil).GetActionsByUserIDs), ctx, userIDs)
+}
+
 // GetNextActionProbability mocks base method.
 func (m *MockService) GetNextActionProbability(ctx context.Context, action string) (map[string]string, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +86,21 @@ func (mr *MockServiceMockRecorder) GetNextActionProbability(ctx, action any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextActionProbability", reflect.TypeOf((*MockService)(nil).GetNextActionProbability), ctx, action)
 }
 
+// GetReferredUsers mocks base method.
+func (m *MockService) GetReferredUsers(ctx context.Context, userIDs []int64) (map[int64][]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferredUsers", ctx, userIDs)
+	ret0, _ := ret[0].(map[int64][]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferredUsers indicates an expected call of GetReferredUsers.
+func (mr *MockServiceMockRecorder) GetReferredUsers(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferredUsers", reflect.TypeOf((*MockService)(nil).GetReferredUsers), ctx, userIDs)
+}
+
 // GetUsersReferrals mocks base method.
 func (m *MockService) GetUsersReferrals(ctx context.Context) (map[int]int, error) {
 	m.ctrl.T.Helper()
@@ -85,3 +115,18 @@ func (mr *MockServiceMockRecorder) GetUsersReferrals(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersReferrals", reflect.TypeOf((*MockService)(nil).GetUsersReferrals), ctx)
 }
+
+// ListActions mocks base method.
+func (m *MockService) ListActions(ctx context.Context, filter domain.Filter) ([]*domain.Action, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActions", ctx, filter)
+	ret0, _ := ret[0].([]*domain.Action)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActions indicates an expected call of ListActions.
+func (mr *MockServiceMockRecorder) ListActions(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActions", reflect.TypeOf((*MockService)(nil).ListActions), ctx, filter)
+}
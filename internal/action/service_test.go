@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 
@@ -53,6 +54,17 @@ func Test_service_GetActionByUserID(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name:   "should return empty slice when user has no actions",
+			userID: 1,
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetActionsByUserID(gomock.Any(), int64(1)).Return(
+					nil, storage.ErrActionsNotFound,
+				)
+			},
+			want:    []*domain.Action{},
+			wantErr: assert.NoError,
+		},
 		{
 			name:   "should return error when repo fails",
 			userID: 1,
@@ -61,8 +73,25 @@ func Test_service_GetActionByUserID(t *testing.T) {
 					nil, assert.AnError,
 				)
 			},
-			want:    nil,
-			wantErr: assert.Error,
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrDataUnavailable)
+			},
+		},
+		{
+			name:   "should return error when stored action is malformed",
+			userID: 1,
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetActionsByUserID(gomock.Any(), int64(1)).Return(
+					[]*entity.Action{
+						{ID: 1, Type: "click", UserID: 1, CreatedAt: "not-a-date"},
+					}, nil,
+				)
+			},
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidData)
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -158,6 +187,15 @@ func Test_service_GetNextActionProbability(t *testing.T) {
 			want:    map[string]string{},
 			wantErr: assert.NoError,
 		},
+		{
+			name:   "should return empty map when repo has no actions",
+			action: "action_1",
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetAllActions(gomock.Any()).Return(nil, storage.ErrActionsNotFound)
+			},
+			want:    map[string]string{},
+			wantErr: assert.NoError,
+		},
 		{
 			name:   "should return empty map when only one action in repo",
 			action: "action_1",
@@ -276,6 +314,28 @@ func Test_service_GetUsersReferrals(t *testing.T) {
 			want:    map[int]int{},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "should return empty map when repo has no actions",
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetAllActions(gomock.Any()).Return(nil, storage.ErrActionsNotFound)
+			},
+			want:    map[int]int{},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "should return error when stored action is malformed",
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetAllActions(gomock.Any()).Return(
+					[]*entity.Action{
+						{Type: domain.ActionTypeReferUser, UserID: 1, TargetUser: 2, CreatedAt: "not-a-date"},
+					}, nil,
+				)
+			},
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidData)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(
@@ -303,3 +363,189 @@ func Test_service_GetUsersReferrals(t *testing.T) {
 		)
 	}
 }
+
+func Test_service_ListActions(t *testing.T) {
+	type mocks struct {
+		logger *zap.SugaredLogger
+		repo   *storage.MockRepository
+	}
+
+	stored := []*entity.Action{
+		{ID: 1, Type: "VIEW_CONTACTS", UserID: 1, CreatedAt: "2023-10-01T12:00:00Z"},
+		{ID: 2, Type: domain.ActionTypeReferUser, UserID: 1, TargetUser: 2, CreatedAt: "2023-10-01T10:00:00Z"},
+		{ID: 3, Type: "VIEW_CONTACTS", UserID: 2, CreatedAt: "2023-10-01T11:00:00Z"},
+	}
+
+	userID := int64(1)
+
+	tests := []struct {
+		name    string
+		filter  domain.Filter
+		mock    func(m *mocks)
+		wantIDs []int
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "should return every action in chronological order without filter",
+			filter: domain.Filter{},
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetAllActions(gomock.Any()).Return(stored, nil)
+			},
+			wantIDs: []int{2, 3, 1},
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "should keep the actions of the user",
+			filter: domain.Filter{UserID: &userID},
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetAllActions(gomock.Any()).Return(stored, nil)
+			},
+			wantIDs: []int{2, 1},
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "should keep the actions of the type regardless of case",
+			filter: domain.Filter{Type: "view_contacts"},
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetAllActions(gomock.Any()).Return(stored, nil)
+			},
+			wantIDs: []int{3, 1},
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "should return error when repo fails",
+			filter: domain.Filter{},
+			mock: func(m *mocks) {
+				m.repo.EXPECT().GetAllActions(gomock.Any()).Return(nil, assert.AnError)
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrDataUnavailable)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				m := &mocks{
+					logger: zap.NewNop().Sugar(),
+					repo:   storage.NewMockRepository(ctrl),
+				}
+
+				tt.mock(m)
+
+				s := &service{
+					logger: m.logger,
+					repo:   m.repo,
+				}
+
+				got, err := s.ListActions(t.Context(), tt.filter)
+
+				tt.wantErr(t, err)
+
+				var gotIDs []int
+				for _, a := range got {
+					gotIDs = append(gotIDs, a.ID)
+				}
+
+				assert.Equal(t, tt.wantIDs, gotIDs)
+			},
+		)
+	}
+}
+
+func Test_service_GetActionsByUserIDs(t *testing.T) {
+	stored := []*entity.Action{
+		{ID: 1, Type: "VIEW_CONTACTS", UserID: 1, CreatedAt: "2023-10-01T12:00:00Z"},
+		{ID: 2, Type: domain.ActionTypeReferUser, UserID: 1, TargetUser: 2, CreatedAt: "2023-10-01T10:00:00Z"},
+		{ID: 3, Type: "VIEW_CONTACTS", UserID: 2, CreatedAt: "2023-10-01T11:00:00Z"},
+		{ID: 4, Type: "VIEW_CONTACTS", UserID: 3, CreatedAt: "2023-10-01T09:00:00Z"},
+	}
+
+	tests := []struct {
+		name    string
+		userIDs []int64
+		repoErr error
+		wantIDs map[int64][]int
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "should group the actions of the requested users in chronological order",
+			userIDs: []int64{1, 2, 9},
+			wantIDs: map[int64][]int{1: {2, 1}, 2: {3}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "should return error when repo fails",
+			userIDs: []int64{1},
+			repoErr: assert.AnError,
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrDataUnavailable)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repo := storage.NewMockRepository(ctrl)
+				if tt.repoErr != nil {
+					repo.EXPECT().GetAllActions(gomock.Any()).Return(nil, tt.repoErr)
+				} else {
+					repo.EXPECT().GetAllActions(gomock.Any()).Return(stored, nil)
+				}
+
+				s := &service{
+					logger: zap.NewNop().Sugar(),
+					repo:   repo,
+				}
+
+				got, err := s.GetActionsByUserIDs(t.Context(), tt.userIDs)
+
+				tt.wantErr(t, err)
+
+				if tt.wantIDs == nil {
+					return
+				}
+
+				gotIDs := make(map[int64][]int, len(got))
+				for userID, actions := range got {
+					for _, a := range actions {
+						gotIDs[userID] = append(gotIDs[userID], a.ID)
+					}
+				}
+
+				assert.Equal(t, tt.wantIDs, gotIDs)
+			},
+		)
+	}
+}
+
+func Test_service_GetReferredUsers(t *testing.T) {
+	stored := []*entity.Action{
+		{ID: 1, Type: domain.ActionTypeReferUser, UserID: 1, TargetUser: 2, CreatedAt: "2023-10-01T10:00:00Z"},
+		{ID: 2, Type: domain.ActionTypeReferUser, UserID: 1, TargetUser: 3, CreatedAt: "2023-10-01T11:00:00Z"},
+		{ID: 3, Type: domain.ActionTypeReferUser, UserID: 2, TargetUser: 4, CreatedAt: "2023-10-01T12:00:00Z"},
+		{ID: 4, Type: "VIEW_CONTACTS", UserID: 3, CreatedAt: "2023-10-01T13:00:00Z"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := storage.NewMockRepository(ctrl)
+	repo.EXPECT().GetAllActions(gomock.Any()).Return(stored, nil)
+
+	s := &service{
+		logger: zap.NewNop().Sugar(),
+		repo:   repo,
+	}
+
+	got, err := s.GetReferredUsers(t.Context(), []int64{1, 2, 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]int64{1: {2, 3}, 2: {4}}, got)
+}
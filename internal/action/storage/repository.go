@@ -1,42 +1,108 @@
 package storage
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	_ "embed"
 
 	"surf_challenge/internal/action/storage/entity"
+	"surf_challenge/internal/jsonstream"
 )
 
-var ErrActionsNotFound = errors.New("actions not found")
-
 var (
-	actionsOnce  sync.Once
-	actionsCache []*entity.Action
-	errActions   error
+	// ErrActionsNotFound signals that the query is valid but there is no data to return.
+	ErrActionsNotFound = errors.New("actions not found")
+	// ErrLoadActions signals that the actions dataset could not be loaded.
+	ErrLoadActions = errors.New("failed to load actions")
 )
 
 //go:generate mockgen -source=repository.go -destination=repository_mock.go -package=storage
 type Repository interface {
 	GetActionsByUserID(ctx context.Context, userID int64) ([]*entity.Action, error)
 	GetAllActions(ctx context.Context) ([]*entity.Action, error)
+	Count(ctx context.Context) (int, error)
+	// Version identifies the content of the dataset and changes whenever it does.
+	Version(ctx context.Context) (string, error)
+}
+
+// DefaultReloadInterval is how often the dataset file is checked for changes.
+const DefaultReloadInterval = time.Second
+
+type actionRepository struct {
+	path           string
+	onLoad         func(records int, err error)
+	reloadInterval time.Duration
+
+	// current is the last dataset loaded successfully, read without locking.
+	current atomic.Pointer[dataset]
+	// nextCheck is when the file should next be checked for changes, in Unix nanoseconds.
+	nextCheck atomic.Int64
+
+	// mu serializes the loads and guards the fields below.
+	mu      sync.Mutex
+	loaded  bool
+	modTime time.Time
+	size    int64
+	err     error
 }
 
-type actionRepository struct{}
+type dataset struct {
+	actions []*entity.Action
+	version string
+}
+
+type Option func(*actionRepository)
+
+// WithLoadObserver registers a hook called after every dataset load with the number
+// of records read or the load error.
+func WithLoadObserver(onLoad func(records int, err error)) Option {
+	return func(ar *actionRepository) {
+		ar.onLoad = onLoad
+	}
+}
 
-func NewRepository() Repository {
-	return &actionRepository{}
+// WithReloadInterval sets how often the dataset file is checked for changes, instead of
+// DefaultReloadInterval. Zero checks it on every call.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(ar *actionRepository) {
+		ar.reloadInterval = interval
+	}
+}
+
+// NewRepository returns a repository reading actions from the JSON file at path,
+// or from the embedded dataset when path is empty.
+func NewRepository(path string, opts ...Option) Repository {
+	ar := &actionRepository{
+		path:           path,
+		onLoad:         func(int, error) {},
+		reloadInterval: DefaultReloadInterval,
+	}
+
+	for _, opt := range opts {
+		opt(ar)
+	}
+
+	return ar
 }
 
 func (ar *actionRepository) GetAllActions(_ context.Context) ([]*entity.Action, error) {
-	actions, err := loadFileWithActions()
+	ds, err := ar.loadFileWithActions()
 	if err != nil {
 		return nil, err
 	}
 
+	actions := ds.actions
+
 	if len(actions) == 0 {
 		return nil, ErrActionsNotFound
 	}
@@ -45,14 +111,14 @@ func (ar *actionRepository) GetAllActions(_ context.Context) ([]*entity.Action,
 }
 
 func (ar *actionRepository) GetActionsByUserID(_ context.Context, userID int64) ([]*entity.Action, error) {
-	actions, err := loadFileWithActions()
+	ds, err := ar.loadFileWithActions()
 	if err != nil {
 		return nil, err
 	}
 
 	var filteredActions []*entity.Action
 
-	for _, action := range actions {
+	for _, action := range ds.actions {
 		if int64(action.UserID) == userID {
 			filteredActions = append(filteredActions, action)
 		}
@@ -65,26 +131,187 @@ func (ar *actionRepository) GetActionsByUserID(_ context.Context, userID int64)
 	return filteredActions, nil
 }
 
+// Count loads the dataset if needed and returns the number of stored actions.
+func (ar *actionRepository) Count(_ context.Context) (int, error) {
+	ds, err := ar.loadFileWithActions()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ds.actions), nil
+}
+
+// Version returns a hash of the dataset file content.
+func (ar *actionRepository) Version(_ context.Context) (string, error) {
+	ds, err := ar.loadFileWithActions()
+	if err != nil {
+		return "", err
+	}
+
+	return ds.version, nil
+}
+
 //go:embed db/actions.json
 var actionsFile []byte
 
-func loadFileWithActions() ([]*entity.Action, error) {
-	actionsOnce.Do(
-		func() {
-			actionsCache, errActions = parseActionsFile()
-		},
-	)
+// Open returns the dataset at path, or the embedded one when path is empty, decompressed
+// when gzip-compressed.
+func Open(path string) (io.ReadCloser, error) {
+	if path == "" {
+		return io.NopCloser(bytes.NewReader(actionsFile)), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadActions, err)
+	}
+
+	r, err := jsonstream.Decompress(f)
+	if err != nil {
+		_ = f.Close()
+
+		return nil, fmt.Errorf("%w: %w", ErrLoadActions, err)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// loadFileWithActions returns the dataset, loading it on first use and reloading it
+// when the file modification time or size changed, checked at most once per reload
+// interval. A failed reload keeps serving the last dataset that loaded successfully.
+func (ar *actionRepository) loadFileWithActions() (*dataset, error) {
+	ds := ar.current.Load()
+	if ds != nil && !ar.due() {
+		return ds, nil
+	}
+
+	if ds == nil {
+		ar.mu.Lock()
+	} else if !ar.mu.TryLock() {
+		// Another call is checking the file, the current dataset is still valid meanwhile.
+		return ds, nil
+	}
+
+	defer ar.mu.Unlock()
+
+	if ds = ar.current.Load(); ds != nil && !ar.due() {
+		return ds, nil
+	}
+
+	return ar.reload()
+}
 
-	return actionsCache, errActions
+// due reports whether the dataset file should be checked for changes.
+func (ar *actionRepository) due() bool {
+	return ar.path != "" && time.Now().UnixNano() >= ar.nextCheck.Load()
 }
 
-func parseActionsFile() ([]*entity.Action, error) {
-	var actions []*entity.Action
+// reload loads the dataset if it never was or if its file changed. ar.mu must be held.
+func (ar *actionRepository) reload() (*dataset, error) {
+	if ar.path == "" {
+		if !ar.loaded {
+			ar.loaded = true
+			ar.store(parseActions(bytes.NewReader(actionsFile)))
+		}
+
+		return ar.current.Load(), ar.err
+	}
+
+	ar.nextCheck.Store(time.Now().Add(ar.reloadInterval).UnixNano())
 
-	err := json.Unmarshal(actionsFile, &actions)
+	current := ar.current.Load()
+
+	info, err := os.Stat(ar.path)
 	if err != nil {
-		return nil, err
+		if current != nil {
+			return current, nil
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrLoadActions, err)
 	}
 
-	return actions, nil
+	if ar.loaded && info.ModTime().Equal(ar.modTime) && info.Size() == ar.size {
+		if current != nil {
+			return current, nil
+		}
+
+		return nil, ar.err
+	}
+
+	ar.loaded, ar.modTime, ar.size = true, info.ModTime(), info.Size()
+
+	ds, err := parseActionsFile(ar.path)
+	if err != nil && current != nil {
+		ar.onLoad(0, err)
+
+		return current, nil
+	}
+
+	ar.store(ds, err)
+
+	return ds, err
+}
+
+func (ar *actionRepository) store(ds *dataset, err error) {
+	ar.err = err
+	if err != nil {
+		ar.onLoad(0, err)
+
+		return
+	}
+
+	ar.current.Store(ds)
+	ar.onLoad(len(ds.actions), nil)
+}
+
+func parseActionsFile(path string) (*dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadActions, err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return parseActions(f)
+}
+
+// parseActions decodes the actions one record at a time, so that the file content is
+// never buffered. Every decoded record is retained, memory growing with the dataset.
+// Gzip-compressed input is detected and decompressed, the version hashing the input as
+// stored.
+func parseActions(r io.Reader) (*dataset, error) {
+	hash := sha256.New()
+
+	input, err := jsonstream.Decompress(io.TeeReader(r, hash))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadActions, err)
+	}
+
+	dec := jsonstream.NewDecoder(input)
+	actions := []*entity.Action{}
+
+	for {
+		action := &entity.Action{}
+
+		_, err = dec.Next(action)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %w", ErrLoadActions, len(actions), err)
+		}
+
+		actions = append(actions, action)
+	}
+
+	return &dataset{
+		actions: actions,
+		version: hex.EncodeToString(hash.Sum(nil)[:8]),
+	}, nil
 }
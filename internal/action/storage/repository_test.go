@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_actionRepository_reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "actions.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":1,"type":"VIEW","userId":1,"createdAt":"2020-01-01T00:00:00Z"}]`), 0o600))
+
+	var loads []int
+
+	repo := NewRepository(
+		path, WithReloadInterval(0), WithLoadObserver(func(records int, _ error) { loads = append(loads, records) }),
+	)
+
+	count, err := repo.Count(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	v1, err := repo.Version(t.Context())
+	require.NoError(t, err)
+
+	_, err = repo.Count(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, loads, "an unchanged file should not be reloaded")
+
+	require.NoError(
+		t, os.WriteFile(
+			path, []byte(`[{"id":1,"type":"VIEW","userId":1,"createdAt":"2020-01-01T00:00:00Z"},`+
+				`{"id":2,"type":"VIEW","userId":2,"createdAt":"2020-01-02T00:00:00Z"}]`), 0o600,
+		),
+	)
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	count, err = repo.Count(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	v2, err := repo.Version(t.Context())
+	require.NoError(t, err)
+	assert.NotEqual(t, v1, v2)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+
+	count, err = repo.Count(t.Context())
+	require.NoError(t, err, "a failed reload should keep the previous dataset")
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []int{1, 2, 0}, loads)
+}
+
+func Test_actionRepository_reloadInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "actions.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":1,"type":"VIEW","userId":1,"createdAt":"2020-01-01T00:00:00Z"}]`), 0o600))
+
+	repo := NewRepository(path, WithReloadInterval(time.Hour))
+
+	count, err := repo.Count(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	count, err = repo.Count(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "the file should not be checked before the interval elapsed")
+}
+
+func Test_parseActionsFile(t *testing.T) {
+	const actions = "[\n" +
+		`  {"id":1,"type":"VIEW","userId":1,"createdAt":"2020-01-01T00:00:00Z"},` + "\n" +
+		`  {"id":2,"type":"VIEW","userId":2,"createdAt":"2020-01-02T00:00:00Z"}` + "\n]\n"
+
+	tests := []struct {
+		name      string
+		content   []byte
+		wantCount int
+		wantErr   string
+	}{
+		{
+			name:      "should load the actions",
+			content:   []byte(actions),
+			wantCount: 2,
+		},
+		{
+			name:      "should load gzip-compressed actions",
+			content:   gzipped(t, actions),
+			wantCount: 2,
+		},
+		{
+			name:    "should locate a malformed record",
+			content: []byte("[\n  {\"id\":1},\n  {\"id\":2,,}\n]"),
+			wantErr: "failed to load actions: record 1: line 3, offset 24: invalid character ',' looking for beginning of object key string",
+		},
+		{
+			name:    "should locate a record of the wrong type",
+			content: []byte("[\n  {\"id\":\"one\"}\n]"),
+			wantErr: "failed to load actions: record 0: line 2, offset 4: " +
+				"json: cannot unmarshal string into Go struct field Action.id of type int",
+		},
+		{
+			name:    "should reject data after the actions",
+			content: []byte("[]\n[]"),
+			wantErr: "failed to load actions: record 0: line 2, offset 3: unexpected data after the array",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "actions.json")
+				require.NoError(t, os.WriteFile(path, tt.content, 0o600))
+
+				got, err := parseActionsFile(path)
+
+				if tt.wantErr != "" {
+					require.ErrorIs(t, err, ErrLoadActions)
+					assert.EqualError(t, err, tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Len(t, got.actions, tt.wantCount)
+				assert.Len(t, got.version, 16)
+			},
+		)
+	}
+}
+
+func gzipped(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
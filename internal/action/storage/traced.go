@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"surf_challenge/internal/action/storage/entity"
+	"surf_challenge/internal/tracing"
+)
+
+// tracedRepository decorates a Repository with one span per call.
+type tracedRepository struct {
+	next   Repository
+	tracer trace.Tracer
+}
+
+func NewTracedRepository(next Repository, tracer trace.Tracer) Repository {
+	return &tracedRepository{
+		next:   next,
+		tracer: tracer,
+	}
+}
+
+func (r *tracedRepository) GetActionsByUserID(ctx context.Context, userID int64) (actions []*entity.Action, err error) {
+	ctx, span := r.tracer.Start(
+		ctx, "action.Repository/GetActionsByUserID",
+		trace.WithAttributes(attribute.Int64("user.id", userID)),
+	)
+	defer tracing.End(span, &err)
+
+	actions, err = r.next.GetActionsByUserID(ctx, userID)
+	span.SetAttributes(attribute.Int("result.size", len(actions)))
+
+	return actions, err
+}
+
+func (r *tracedRepository) GetAllActions(ctx context.Context) (actions []*entity.Action, err error) {
+	ctx, span := r.tracer.Start(ctx, "action.Repository/GetAllActions")
+	defer tracing.End(span, &err)
+
+	actions, err = r.next.GetAllActions(ctx)
+	span.SetAttributes(attribute.Int("result.size", len(actions)))
+
+	return actions, err
+}
+
+func (r *tracedRepository) Count(ctx context.Context) (count int, err error) {
+	ctx, span := r.tracer.Start(ctx, "action.Repository/Count")
+	defer tracing.End(span, &err)
+
+	count, err = r.next.Count(ctx)
+	span.SetAttributes(attribute.Int("result.size", count))
+
+	return count, err
+}
+
+func (r *tracedRepository) Version(ctx context.Context) (version string, err error) {
+	ctx, span := r.tracer.Start(ctx, "action.Repository/Version")
+	defer tracing.End(span, &err)
+
+	return r.next.Version(ctx)
+}
@@ -0,0 +1,107 @@
+package action
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"surf_challenge/internal/action/domain"
+	"surf_challenge/internal/tracing"
+)
+
+// tracedService decorates a Service with one span per call.
+type tracedService struct {
+	next   Service
+	tracer trace.Tracer
+}
+
+func NewTracedService(next Service, tracer trace.Tracer) Service {
+	return &tracedService{
+		next:   next,
+		tracer: tracer,
+	}
+}
+
+func (s *tracedService) GetActionByUserID(ctx context.Context, userID int64) (actions []*domain.Action, err error) {
+	ctx, span := s.tracer.Start(
+		ctx, "action.Service/GetActionByUserID",
+		trace.WithAttributes(attribute.Int64("user.id", userID)),
+	)
+	defer tracing.End(span, &err)
+
+	actions, err = s.next.GetActionByUserID(ctx, userID)
+	span.SetAttributes(attribute.Int("result.size", len(actions)))
+
+	return actions, err
+}
+
+func (s *tracedService) GetNextActionProbability(
+	ctx context.Context,
+	action string,
+) (probability map[string]string, err error) {
+	ctx, span := s.tracer.Start(
+		ctx, "action.Service/GetNextActionProbability",
+		trace.WithAttributes(attribute.String("action.type", action)),
+	)
+	defer tracing.End(span, &err)
+
+	probability, err = s.next.GetNextActionProbability(ctx, action)
+	span.SetAttributes(attribute.Int("result.size", len(probability)))
+
+	return probability, err
+}
+
+func (s *tracedService) GetUsersReferrals(ctx context.Context) (referrals map[int]int, err error) {
+	ctx, span := s.tracer.Start(ctx, "action.Service/GetUsersReferrals")
+	defer tracing.End(span, &err)
+
+	referrals, err = s.next.GetUsersReferrals(ctx)
+	span.SetAttributes(attribute.Int("result.size", len(referrals)))
+
+	return referrals, err
+}
+
+func (s *tracedService) ListActions(ctx context.Context, filter domain.Filter) (actions []*domain.Action, err error) {
+	attrs := []attribute.KeyValue{attribute.String("action.type", filter.Type)}
+	if filter.UserID != nil {
+		attrs = append(attrs, attribute.Int64("user.id", *filter.UserID))
+	}
+
+	ctx, span := s.tracer.Start(ctx, "action.Service/ListActions", trace.WithAttributes(attrs...))
+	defer tracing.End(span, &err)
+
+	actions, err = s.next.ListActions(ctx, filter)
+	span.SetAttributes(attribute.Int("result.size", len(actions)))
+
+	return actions, err
+}
+
+func (s *tracedService) GetActionsByUserIDs(
+	ctx context.Context,
+	userIDs []int64,
+) (actions map[int64][]*domain.Action, err error) {
+	ctx, span := s.tracer.Start(
+		ctx, "action.Service/GetActionsByUserIDs",
+		trace.WithAttributes(attribute.Int("batch.size", len(userIDs))),
+	)
+	defer tracing.End(span, &err)
+
+	actions, err = s.next.GetActionsByUserIDs(ctx, userIDs)
+	span.SetAttributes(attribute.Int("result.size", len(actions)))
+
+	return actions, err
+}
+
+func (s *tracedService) GetReferredUsers(ctx context.Context, userIDs []int64) (referred map[int64][]int64, err error) {
+	ctx, span := s.tracer.Start(
+		ctx, "action.Service/GetReferredUsers",
+		trace.WithAttributes(attribute.Int("batch.size", len(userIDs))),
+	)
+	defer tracing.End(span, &err)
+
+	referred, err = s.next.GetReferredUsers(ctx, userIDs)
+	span.SetAttributes(attribute.Int("result.size", len(referred)))
+
+	return referred, err
+}
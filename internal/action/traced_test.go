@@ -0,0 +1,127 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+
+	"surf_challenge/internal/action/domain"
+	"surf_challenge/internal/tracing"
+)
+
+func Test_tracedService(t *testing.T) {
+	tests := []struct {
+		name       string
+		mock       func(m *MockService)
+		call       func(s Service)
+		wantName   string
+		wantStatus codes.Code
+		wantAttrs  []attribute.KeyValue
+	}{
+		{
+			name: "should trace actions lookup by user",
+			mock: func(m *MockService) {
+				m.EXPECT().GetActionByUserID(gomock.Any(), int64(3)).Return([]*domain.Action{{ID: 1}, {ID: 2}}, nil)
+			},
+			call: func(s Service) {
+				_, _ = s.GetActionByUserID(t.Context(), 3)
+			},
+			wantName:   "action.Service/GetActionByUserID",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int64("user.id", 3),
+				attribute.Int("result.size", 2),
+			},
+		},
+		{
+			name: "should trace next action probability with the action type",
+			mock: func(m *MockService) {
+				m.EXPECT().GetNextActionProbability(gomock.Any(), "REFER_USER").
+					Return(map[string]string{"A": "1.00"}, nil)
+			},
+			call: func(s Service) {
+				_, _ = s.GetNextActionProbability(t.Context(), "REFER_USER")
+			},
+			wantName:   "action.Service/GetNextActionProbability",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("action.type", "REFER_USER"),
+				attribute.Int("result.size", 1),
+			},
+		},
+		{
+			name: "should trace actions listing with the filter",
+			mock: func(m *MockService) {
+				m.EXPECT().ListActions(gomock.Any(), gomock.Any()).Return([]*domain.Action{{ID: 1}}, nil)
+			},
+			call: func(s Service) {
+				userID := int64(3)
+				_, _ = s.ListActions(t.Context(), domain.Filter{UserID: &userID, Type: "REFER_USER"})
+			},
+			wantName:   "action.Service/ListActions",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("action.type", "REFER_USER"),
+				attribute.Int64("user.id", 3),
+				attribute.Int("result.size", 1),
+			},
+		},
+		{
+			name: "should trace batched lookups with the batch size",
+			mock: func(m *MockService) {
+				m.EXPECT().GetReferredUsers(gomock.Any(), []int64{1, 2}).Return(map[int64][]int64{1: {3}}, nil)
+			},
+			call: func(s Service) {
+				_, _ = s.GetReferredUsers(t.Context(), []int64{1, 2})
+			},
+			wantName:   "action.Service/GetReferredUsers",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int("batch.size", 2),
+				attribute.Int("result.size", 1),
+			},
+		},
+		{
+			name: "should record errors on the span",
+			mock: func(m *MockService) {
+				m.EXPECT().GetUsersReferrals(gomock.Any()).Return(nil, assert.AnError)
+			},
+			call: func(s Service) {
+				_, _ = s.GetUsersReferrals(t.Context())
+			},
+			wantName:   "action.Service/GetUsersReferrals",
+			wantStatus: codes.Error,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int("result.size", 0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				next := NewMockService(ctrl)
+				tt.mock(next)
+
+				recorder := tracetest.NewSpanRecorder()
+				provider := tracing.NewWithProcessor(recorder, sdktrace.AlwaysSample())
+
+				tt.call(NewTracedService(next, provider.Tracer()))
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				assert.Equal(t, tt.wantName, spans[0].Name())
+				assert.Equal(t, tt.wantStatus, spans[0].Status().Code)
+				assert.ElementsMatch(t, tt.wantAttrs, spans[0].Attributes())
+			},
+		)
+	}
+}
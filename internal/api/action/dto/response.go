@@ -2,7 +2,9 @@ package dto
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 type NextActionProbability struct {
@@ -29,4 +31,59 @@ func (n *NextActionProbability) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// ActionProbability is one row of the NDJSON and CSV representations of
+// NextActionProbability.
+type ActionProbability struct {
+	Action      string      `json:"action"`
+	Probability json.Number `json:"probability"`
+}
+
+// ProbabilityColumns are the CSV columns of an ActionProbability.
+var ProbabilityColumns = []string{"action", "probability"}
+
+// Rows returns one row per action, following Keys so that every representation lists
+// the actions in the same order, with the precision of the JSON document.
+func (n *NextActionProbability) Rows() []ActionProbability {
+	rows := make([]ActionProbability, 0, len(n.Keys))
+
+	for _, key := range n.Keys {
+		rows = append(
+			rows, ActionProbability{
+				Action:      key,
+				Probability: json.Number(fmt.Sprintf("%.2f", n.Data[key])),
+			},
+		)
+	}
+
+	return rows
+}
+
+// Record returns the CSV fields of p in ProbabilityColumns order.
+func (p ActionProbability) Record() []string {
+	return []string{p.Action, p.Probability.String()}
+}
+
 type ReferralResponse map[int]int
+
+// Referral is one line of the NDJSON and CSV referral index.
+type Referral struct {
+	UserID    int `json:"userId"`
+	Referrals int `json:"referrals"`
+}
+
+// ReferralColumns are the CSV columns of a Referral.
+var ReferralColumns = []string{"userId", "referrals"}
+
+// Record returns the CSV fields of r in ReferralColumns order.
+func (r Referral) Record() []string {
+	return []string{strconv.Itoa(r.UserID), strconv.Itoa(r.Referrals)}
+}
+
+// Action is the data of an event of the actions stream.
+type Action struct {
+	ID         int    `json:"id"`
+	Type       string `json:"type"`
+	UserID     int    `json:"userId"`
+	TargetUser int    `json:"targetUser,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
@@ -1,8 +1,11 @@
 package action
 
 import (
-	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"slices"
 
 	"go.uber.org/zap"
 
@@ -10,6 +13,7 @@ import (
 	"surf_challenge/internal/api/action/dto"
 	"surf_challenge/internal/api/action/mapper"
 	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
 )
 
 type Handler interface {
@@ -30,26 +34,12 @@ func NewHandler(sugar *zap.SugaredLogger, service action.Service) Handler {
 }
 
 func (a actionsHandler) GetNextActionProbability() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		resp, err := a.handleGetNextActionProbability(r)
-		if err != nil {
-			a.logger.Errorw("failed to get next action probability", "error", err)
-
-			apiError := mapper.MapErrors(err)
-			http.Error(w, apiError.Message, apiError.Code)
-
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
-		err = json.NewEncoder(w).Encode(resp)
-		if err != nil {
-			a.logger.Errorw("failed to encode response", "error", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		}
-	}
+	return response.Handle(
+		a.logger, "failed to get next action probability", a.handleGetNextActionProbability,
+		response.WithETag(),
+		response.WithEncoder(response.ContentTypeNDJSON, encodeProbabilityNDJSON),
+		response.WithEncoder(response.ContentTypeCSV, encodeProbabilityCSV),
+	)
 }
 
 func (a actionsHandler) handleGetNextActionProbability(r *http.Request) (*dto.NextActionProbability, error) {
@@ -73,27 +63,41 @@ func (a actionsHandler) handleGetNextActionProbability(r *http.Request) (*dto.Ne
 	return probabilityDTO, nil
 }
 
-func (a actionsHandler) GetReferralForUser() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		resp, err := a.handleGetUsersReferrals(r)
-		if err != nil {
-			a.logger.Errorw("failed to get referral for user", "error", err)
-
-			apiError := mapper.MapErrors(err)
-			http.Error(w, apiError.Message, apiError.Code)
+// encodeProbabilityNDJSON writes one action per line, in the order of the JSON document.
+func encodeProbabilityNDJSON(w io.Writer, v any) error {
+	enc := response.NewNDJSONEncoder(w)
 
-			return
+	for _, row := range v.(*dto.NextActionProbability).Rows() {
+		err := enc.Encode(row)
+		if err != nil {
+			return err
 		}
+	}
+
+	return enc.Flush()
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+// encodeProbabilityCSV writes one action per row, in the order of the JSON document.
+func encodeProbabilityCSV(w io.Writer, v any) error {
+	enc := response.NewCSVEncoder(w, dto.ProbabilityColumns)
 
-		err = json.NewEncoder(w).Encode(resp)
+	for _, row := range v.(*dto.NextActionProbability).Rows() {
+		err := enc.Encode(row.Record())
 		if err != nil {
-			a.logger.Errorw("failed to encode response", "error", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return err
 		}
 	}
+
+	return enc.Flush()
+}
+
+func (a actionsHandler) GetReferralForUser() http.HandlerFunc {
+	return response.Handle(
+		a.logger, "failed to get referral for user", a.handleGetUsersReferrals,
+		response.WithETag(),
+		response.WithStream(response.ContentTypeNDJSON, a.streamReferralsNDJSON),
+		response.WithStream(response.ContentTypeCSV, a.streamReferralsCSV),
+	)
 }
 
 func (a actionsHandler) handleGetUsersReferrals(r *http.Request) (dto.ReferralResponse, error) {
@@ -106,3 +110,57 @@ func (a actionsHandler) handleGetUsersReferrals(r *http.Request) (dto.ReferralRe
 
 	return referral, nil
 }
+
+// streamReferralsNDJSON writes the referral index as NDJSON, one user per line.
+func (a actionsHandler) streamReferralsNDJSON(r *http.Request, w io.Writer) error {
+	referrals, err := a.sortedReferrals(r)
+	if err != nil {
+		return err
+	}
+
+	enc := response.NewNDJSONEncoder(w)
+
+	for _, referral := range referrals {
+		err = enc.Encode(referral)
+		if err != nil {
+			return fmt.Errorf("streaming referrals: %w", err)
+		}
+	}
+
+	return enc.Flush()
+}
+
+// streamReferralsCSV writes the referral index as CSV, in dto.ReferralColumns order.
+func (a actionsHandler) streamReferralsCSV(r *http.Request, w io.Writer) error {
+	referrals, err := a.sortedReferrals(r)
+	if err != nil {
+		return err
+	}
+
+	enc := response.NewCSVEncoder(w, dto.ReferralColumns)
+
+	for _, referral := range referrals {
+		err = enc.Encode(referral.Record())
+		if err != nil {
+			return fmt.Errorf("streaming referrals: %w", err)
+		}
+	}
+
+	return enc.Flush()
+}
+
+// sortedReferrals returns the referral index in ascending user ID order.
+func (a actionsHandler) sortedReferrals(r *http.Request) ([]dto.Referral, error) {
+	referral, err := a.service.GetUsersReferrals(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	referrals := make([]dto.Referral, 0, len(referral))
+
+	for _, userID := range slices.Sorted(maps.Keys(referral)) {
+		referrals = append(referrals, dto.Referral{UserID: userID, Referrals: referral[userID]})
+	}
+
+	return referrals, nil
+}
@@ -59,8 +59,15 @@ func Test_actionsHandler_GetNextActionProbability(t *testing.T) {
 			assertBody: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				t.Helper()
 
-				wantBody := "next action parameter is required\n"
-				require.Equal(t, wantBody, recorder.Body.String())
+				wantBody := `{
+					"type": "about:blank",
+					"title": "Bad Request",
+					"status": 400,
+					"detail": "next action parameter is required",
+					"instance": "/actions/next-probability"
+				}`
+				require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+				require.JSONEq(t, wantBody, recorder.Body.String())
 			},
 		},
 		{
@@ -75,8 +82,38 @@ func Test_actionsHandler_GetNextActionProbability(t *testing.T) {
 			assertBody: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				t.Helper()
 
-				wantBody := "Internal server error\n"
-				require.Equal(t, wantBody, recorder.Body.String())
+				wantBody := `{
+					"type": "about:blank",
+					"title": "Internal Server Error",
+					"status": 500,
+					"detail": "Internal server error",
+					"instance": "/actions/next-probability"
+				}`
+				require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+				require.JSONEq(t, wantBody, recorder.Body.String())
+			},
+		},
+		{
+			name:      "Should return service unavailable when actions data cannot be loaded",
+			nextInput: "action1",
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetNextActionProbability(gomock.Any(), "action1").Return(
+					nil, action.ErrDataUnavailable,
+				)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			assertBody: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				t.Helper()
+
+				wantBody := `{
+					"type": "about:blank",
+					"title": "Service Unavailable",
+					"status": 503,
+					"detail": "Service unavailable",
+					"instance": "/actions/next-probability"
+				}`
+				require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+				require.JSONEq(t, wantBody, recorder.Body.String())
 			},
 		},
 	}
@@ -157,7 +194,49 @@ func Test_actionsHandler_GetReferralForUser(t *testing.T) {
 			assertBody: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				t.Helper()
 
-				wantBody := "Internal server error\n"
+				wantBody := `{
+					"type": "about:blank",
+					"title": "Internal Server Error",
+					"status": 500,
+					"detail": "Internal server error",
+					"instance": "/actions/referrals"
+				}`
+				require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+				require.JSONEq(t, wantBody, recorder.Body.String())
+			},
+		},
+		{
+			name: "Should return service unavailable when actions data cannot be loaded",
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUsersReferrals(gomock.Any()).
+					Return(nil, action.ErrDataUnavailable)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			assertBody: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				t.Helper()
+
+				wantBody := `{
+					"type": "about:blank",
+					"title": "Service Unavailable",
+					"status": 503,
+					"detail": "Service unavailable",
+					"instance": "/actions/referrals"
+				}`
+				require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+				require.JSONEq(t, wantBody, recorder.Body.String())
+			},
+		},
+		{
+			name: "Should return empty object when there are no referrals",
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUsersReferrals(gomock.Any()).
+					Return(map[int]int{}, nil)
+			},
+			wantStatus: http.StatusOK,
+			assertBody: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				t.Helper()
+
+				wantBody := "{}\n"
 				require.Equal(t, wantBody, recorder.Body.String())
 			},
 		},
@@ -195,3 +274,95 @@ func Test_actionsHandler_GetReferralForUser(t *testing.T) {
 		)
 	}
 }
+
+func Test_actionsHandler_GetReferralForUser_NDJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := action.NewMockService(ctrl)
+	service.EXPECT().GetUsersReferrals(gomock.Any()).Return(map[int]int{3: 2, 1: 5}, nil)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/actions/referrals", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	recorder := httptest.NewRecorder()
+	NewHandler(zap.NewNop().Sugar(), service).GetReferralForUser().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"userId\":1,\"referrals\":5}\n{\"userId\":3,\"referrals\":2}\n", recorder.Body.String())
+}
+
+func Test_actionsHandler_GetReferralForUser_CSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := action.NewMockService(ctrl)
+	service.EXPECT().GetUsersReferrals(gomock.Any()).Return(map[int]int{3: 2, 1: 5}, nil)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/actions/referrals?format=csv", nil)
+
+	recorder := httptest.NewRecorder()
+	NewHandler(zap.NewNop().Sugar(), service).GetReferralForUser().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "userId,referrals\n1,5\n3,2\n", recorder.Body.String())
+}
+
+func Test_actionsHandler_GetNextActionProbability_Formats(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		format          string
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "When NDJSON is accepted, should write one action per line in JSON key order",
+			accept:          "application/x-ndjson",
+			wantContentType: "application/x-ndjson",
+			wantBody: `{"action":"action2","probability":0.70}` + "\n" +
+				`{"action":"action3","probability":0.20}` + "\n" +
+				`{"action":"action4","probability":0.10}` + "\n",
+		},
+		{
+			name:            "When CSV is requested by format, should write one action per row in JSON key order",
+			accept:          "application/json",
+			format:          "csv",
+			wantContentType: "text/csv",
+			wantBody:        "action,probability\naction2,0.70\naction3,0.20\naction4,0.10\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				service := action.NewMockService(ctrl)
+				service.EXPECT().GetNextActionProbability(gomock.Any(), "action1").Return(
+					map[string]string{"action3": "0.20", "action2": "0.70", "action4": "0.10"}, nil,
+				)
+
+				query := url.Values{"next": {"action1"}}
+				if tt.format != "" {
+					query.Set("format", tt.format)
+				}
+
+				req := httptest.NewRequestWithContext(
+					t.Context(), http.MethodGet, "/actions/next-probability?"+query.Encode(), nil,
+				)
+				req.Header.Set("Accept", tt.accept)
+
+				recorder := httptest.NewRecorder()
+				NewHandler(zap.NewNop().Sugar(), service).GetNextActionProbability().ServeHTTP(recorder, req)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, tt.wantContentType, recorder.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantBody, recorder.Body.String())
+				assert.NotEmpty(t, recorder.Header().Get("ETag"))
+			},
+		)
+	}
+}
@@ -1,14 +1,13 @@
 package mapper
 
 import (
-	"errors"
 	"fmt"
-	"net/http"
 	"sort"
 	"strconv"
+	"time"
 
+	"surf_challenge/internal/action/domain"
 	"surf_challenge/internal/api/action/dto"
-	"surf_challenge/internal/api/apierror"
 )
 
 func MapProbabilityToDTO(probability map[string]string) (*dto.NextActionProbability, error) {
@@ -38,11 +37,12 @@ func MapProbabilityToDTO(probability map[string]string) (*dto.NextActionProbabil
 	}, nil
 }
 
-func MapErrors(err error) *apierror.APIError {
-	var apiErr *apierror.APIError
-	if errors.As(err, &apiErr) {
-		return apiErr
+func MapActionToDTO(a *domain.Action) dto.Action {
+	return dto.Action{
+		ID:         a.ID,
+		Type:       a.Type,
+		UserID:     a.UserID,
+		TargetUser: a.TargetUser,
+		CreatedAt:  a.CreatedAt.Format(time.RFC3339),
 	}
-
-	return apierror.NewAPIError("Internal server error", http.StatusInternalServerError)
 }
@@ -0,0 +1,170 @@
+package action
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action/domain"
+	"surf_challenge/internal/api/action/mapper"
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/feed"
+	"surf_challenge/internal/logger"
+)
+
+const (
+	ContentTypeEventStream = "text/event-stream"
+
+	// HeaderLastEventID is sent by EventSource clients when reconnecting.
+	HeaderLastEventID = "Last-Event-ID"
+
+	eventAction = "action"
+)
+
+type StreamHandler interface {
+	StreamActions() http.HandlerFunc
+}
+
+type streamHandler struct {
+	logger    *zap.SugaredLogger
+	feed      *feed.Feed
+	heartbeat time.Duration
+}
+
+// NewStreamHandler streams the actions published to f, sending a comment every
+// heartbeat on idle streams so that proxies keep the connection open.
+func NewStreamHandler(sugar *zap.SugaredLogger, f *feed.Feed, heartbeat time.Duration) StreamHandler {
+	return &streamHandler{
+		logger:    sugar,
+		feed:      f,
+		heartbeat: heartbeat,
+	}
+}
+
+// StreamActions serves the actions ingested from now on as Server-Sent Events, or since
+// the event named by Last-Event-ID when the client resumes. The stream lasts until the
+// client disconnects, or falls too far behind and has to resume.
+func (h *streamHandler) StreamActions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), h.logger)
+
+		filter, err := streamFilter(r)
+		if err != nil {
+			apierror.Write(w, r, err)
+
+			return
+		}
+
+		var lastEventID uint64
+
+		header := r.Header.Get(HeaderLastEventID)
+		if header != "" {
+			lastEventID, err = strconv.ParseUint(header, 10, 64)
+			if err != nil {
+				apierror.Write(w, r, apierror.NewAPIError("invalid Last-Event-ID header", http.StatusBadRequest))
+
+				return
+			}
+		}
+
+		// The server write timeout would cut the stream.
+		rc := http.NewResponseController(w)
+
+		err = rc.SetWriteDeadline(time.Time{})
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Warnw("failed to clear the write deadline", "error", err)
+		}
+
+		subscription := h.feed.Subscribe(filter, lastEventID, header != "")
+		defer subscription.Close()
+
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		w.Header().Set("Cache-Control", "no-cache")
+		// Disables response buffering in nginx.
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		for _, event := range subscription.Backlog {
+			err = writeEvent(w, event)
+			if err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(h.heartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			err = rc.Flush()
+			if err != nil {
+				return
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-subscription.Events():
+				if !ok {
+					log.Infow("Dropped a lagging actions stream", "lastEventId", lastEventID)
+
+					return
+				}
+
+				lastEventID = event.ID
+
+				err = writeEvent(w, event)
+			case <-heartbeat.C:
+				_, err = io.WriteString(w, ": keep-alive\n\n")
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// streamFilter returns the filter of the query. End users may only stream their own
+// actions, so they must pass their userId, as with the gRPC ListActions.
+func streamFilter(r *http.Request) (domain.Filter, error) {
+	filter := domain.Filter{Type: r.URL.Query().Get("type")}
+
+	userID := r.URL.Query().Get("userId")
+	if userID != "" {
+		id, err := strconv.ParseInt(userID, 10, 64)
+		if err != nil {
+			return domain.Filter{}, apierror.NewAPIError("invalid userId parameter", http.StatusBadRequest)
+		}
+
+		filter.UserID = &id
+	}
+
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return domain.Filter{}, auth.ErrUnauthenticated
+	}
+
+	if !principal.CanAccessUser(userID) {
+		return domain.Filter{}, auth.ErrForbidden
+	}
+
+	return filter, nil
+}
+
+func writeEvent(w io.Writer, event feed.Event) error {
+	data, err := json.Marshal(mapper.MapActionToDTO(event.Action))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, eventAction, data)
+
+	return err
+}
@@ -0,0 +1,171 @@
+package action
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action/domain"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/feed"
+)
+
+func Test_streamHandler_StreamActions(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	service := &auth.Principal{Name: "ops", Scopes: []auth.Scope{auth.ScopeAnalyticsRead}}
+	endUser := &auth.Principal{Name: "jwt", UserID: "1", Scopes: []auth.Scope{auth.ScopeAnalyticsRead}}
+
+	tests := []struct {
+		name        string
+		principal   *auth.Principal
+		query       string
+		lastEventID string
+		wantStatus  int
+		wantEvents  []string
+	}{
+		{
+			name:       "When no Last-Event-ID is sent, should stream the actions published from now on",
+			principal:  service,
+			wantStatus: http.StatusOK,
+			wantEvents: []string{
+				"id: 3\nevent: action\ndata: {\"id\":3,\"type\":\"REFER_USER\",\"userId\":2,\"targetUser\":5,\"createdAt\":\"2024-01-02T03:04:05Z\"}",
+				"id: 4\nevent: action\ndata: {\"id\":4,\"type\":\"VIEW_CONTACTS\",\"userId\":1,\"createdAt\":\"2024-01-02T03:04:05Z\"}",
+			},
+		},
+		{
+			name:        "When Last-Event-ID is sent, should replay the events published after it first",
+			principal:   service,
+			query:       "?userId=1",
+			lastEventID: "1",
+			wantStatus:  http.StatusOK,
+			wantEvents: []string{
+				"id: 2\nevent: action\ndata: {\"id\":2,\"type\":\"VIEW_CONTACTS\",\"userId\":1,\"createdAt\":\"2024-01-02T03:04:05Z\"}",
+				"id: 4\nevent: action\ndata: {\"id\":4,\"type\":\"VIEW_CONTACTS\",\"userId\":1,\"createdAt\":\"2024-01-02T03:04:05Z\"}",
+			},
+		},
+		{
+			name:       "When filtered by type, should only stream the actions of the type",
+			principal:  service,
+			query:      "?type=refer_user",
+			wantStatus: http.StatusOK,
+			wantEvents: []string{
+				"id: 3\nevent: action\ndata: {\"id\":3,\"type\":\"REFER_USER\",\"userId\":2,\"targetUser\":5,\"createdAt\":\"2024-01-02T03:04:05Z\"}",
+			},
+		},
+		{
+			name:        "When Last-Event-ID is not a number, should return 400",
+			principal:   service,
+			lastEventID: "abc",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:       "When userId is not a number, should return 400",
+			principal:  service,
+			query:      "?userId=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "When an end user streams their own actions, should only stream them",
+			principal:  endUser,
+			query:      "?userId=1",
+			wantStatus: http.StatusOK,
+			wantEvents: []string{
+				"id: 4\nevent: action\ndata: {\"id\":4,\"type\":\"VIEW_CONTACTS\",\"userId\":1,\"createdAt\":\"2024-01-02T03:04:05Z\"}",
+			},
+		},
+		{
+			name:       "When an end user streams the actions of another user, should return 403",
+			principal:  endUser,
+			query:      "?userId=2",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "When an end user streams without userId, should return 403",
+			principal:  endUser,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				f := feed.New(10)
+				f.Publish(
+					&domain.Action{ID: 1, Type: "VIEW_CONTACTS", UserID: 1, CreatedAt: createdAt},
+					&domain.Action{ID: 2, Type: "VIEW_CONTACTS", UserID: 1, CreatedAt: createdAt},
+				)
+
+				handler := NewStreamHandler(zap.NewNop().Sugar(), f, time.Minute).StreamActions()
+				server := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							handler(w, r.WithContext(auth.WithPrincipal(r.Context(), tt.principal)))
+						},
+					),
+				)
+				defer server.Close()
+
+				req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+tt.query, nil)
+				require.NoError(t, err)
+
+				if tt.lastEventID != "" {
+					req.Header.Set(HeaderLastEventID, tt.lastEventID)
+				}
+
+				resp, err := server.Client().Do(req)
+				require.NoError(t, err)
+
+				defer resp.Body.Close()
+
+				require.Equal(t, tt.wantStatus, resp.StatusCode)
+
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				assert.Equal(t, ContentTypeEventStream, resp.Header.Get("Content-Type"))
+
+				// The headers are sent once subscribed, so these are live events.
+				f.Publish(
+					&domain.Action{ID: 3, Type: "REFER_USER", UserID: 2, TargetUser: 5, CreatedAt: createdAt},
+					&domain.Action{ID: 4, Type: "VIEW_CONTACTS", UserID: 1, CreatedAt: createdAt},
+				)
+
+				events := readEvents(t, bufio.NewScanner(resp.Body), len(tt.wantEvents))
+				assert.Equal(t, tt.wantEvents, events)
+			},
+		)
+	}
+}
+
+// readEvents reads n events, each returned without its terminating blank line.
+func readEvents(t *testing.T, scanner *bufio.Scanner, n int) []string {
+	t.Helper()
+
+	var (
+		events []string
+		lines  []string
+	)
+
+	for len(events) < n && scanner.Scan() {
+		if scanner.Text() != "" {
+			lines = append(lines, scanner.Text())
+
+			continue
+		}
+
+		events = append(events, strings.Join(lines, "\n"))
+		lines = nil
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return events
+}
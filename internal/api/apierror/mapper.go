@@ -0,0 +1,38 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"surf_challenge/internal/action"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/webhook"
+)
+
+// MapErrors translates service errors into the API error exposed to clients.
+// Errors that are already an APIError are returned untouched.
+func MapErrors(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var invalidWebhook *webhook.InvalidError
+	if errors.As(err, &invalidWebhook) {
+		return NewAPIError(invalidWebhook.Error(), http.StatusBadRequest)
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return NewAPIError("Missing or invalid credentials", http.StatusUnauthorized)
+	case errors.Is(err, auth.ErrForbidden):
+		return NewAPIError("Not allowed to access this resource", http.StatusForbidden)
+	case errors.Is(err, user.ErrNotFound), errors.Is(err, webhook.ErrNotFound):
+		return NewAPIError("Resource not found", http.StatusNotFound)
+	case errors.Is(err, action.ErrDataUnavailable):
+		return NewAPIError("Service unavailable", http.StatusServiceUnavailable)
+	default:
+		return NewAPIError("Internal server error", http.StatusInternalServerError)
+	}
+}
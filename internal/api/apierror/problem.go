@@ -0,0 +1,54 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	ContentTypeProblem = "application/problem+json"
+	problemTypeDefault = "about:blank"
+)
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+func NewProblem(r *http.Request, apiErr *APIError) *Problem {
+	return &Problem{
+		Type:      problemTypeDefault,
+		Title:     http.StatusText(apiErr.Code),
+		Status:    apiErr.Code,
+		Detail:    apiErr.Message,
+		Instance:  r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+// Write maps err to an APIError and renders it as application/problem+json.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	WriteProblem(w, NewProblem(r, MapErrors(err)))
+}
+
+func WriteProblem(w http.ResponseWriter, problem *Problem) {
+	body, err := json.Marshal(problem)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+
+	_, _ = w.Write(append(body, '\n'))
+}
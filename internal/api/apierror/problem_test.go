@@ -0,0 +1,112 @@
+package apierror
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"surf_challenge/internal/action"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/webhook"
+)
+
+func Test_Write(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "When error is an APIError, should render it as is",
+			err:        NewAPIError("invalid userId parameter", http.StatusBadRequest),
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "invalid userId parameter",
+				"instance": "/api/v1/users/abc",
+				"requestId": "req-1"
+			}`,
+		},
+		{
+			name:       "When error wraps user not found, should render not found",
+			err:        fmt.Errorf("getting user: %w", user.ErrNotFound),
+			wantStatus: http.StatusNotFound,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Not Found",
+				"status": 404,
+				"detail": "Resource not found",
+				"instance": "/api/v1/users/abc",
+				"requestId": "req-1"
+			}`,
+		},
+		{
+			name:       "When actions data is unavailable, should render service unavailable",
+			err:        action.ErrDataUnavailable,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Service Unavailable",
+				"status": 503,
+				"detail": "Service unavailable",
+				"instance": "/api/v1/users/abc",
+				"requestId": "req-1"
+			}`,
+		},
+		{
+			name:       "When a webhook registration is invalid, should render the reason",
+			err:        fmt.Errorf("registering webhook: %w", &webhook.InvalidError{Reason: "events must not be empty"}),
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "invalid webhook: events must not be empty",
+				"instance": "/api/v1/users/abc",
+				"requestId": "req-1"
+			}`,
+		},
+		{
+			name:       "When error is unknown, should render internal server error",
+			err:        assert.AnError,
+			wantStatus: http.StatusInternalServerError,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Internal Server Error",
+				"status": 500,
+				"detail": "Internal server error",
+				"instance": "/api/v1/users/abc",
+				"requestId": "req-1"
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/users/abc", nil)
+				req.Header.Set(middleware.RequestIDHeader, "req-1")
+
+				recorder := httptest.NewRecorder()
+				middleware.RequestID(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							Write(w, r, tt.err)
+						},
+					),
+				).ServeHTTP(recorder, req)
+
+				require.Equal(t, tt.wantStatus, recorder.Code)
+				require.Equal(t, ContentTypeProblem, recorder.Header().Get("Content-Type"))
+				require.JSONEq(t, tt.wantBody, recorder.Body.String())
+			},
+		)
+	}
+}
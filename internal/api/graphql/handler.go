@@ -0,0 +1,124 @@
+// Package graphql serves a GraphQL view of users, their actions and the referral
+// graph, resolved through the same services as the REST API.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action"
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
+	"surf_challenge/internal/user"
+)
+
+const (
+	// maxDepth bounds the nesting of queries, referral subtrees being recursive.
+	maxDepth = 12
+	// maxBodyBytes bounds the size of a POST body.
+	maxBodyBytes = 1 << 20
+)
+
+//go:embed schema.graphql
+var schema string
+
+type Handler interface {
+	Query() http.HandlerFunc
+}
+
+type graphqlHandler struct {
+	logger *zap.SugaredLogger
+	schema *graphqlgo.Schema
+	root   *resolver
+}
+
+// NewHandler parses the schema against the resolvers, panicking when they drift apart
+// since both are compiled in. The referral fields fail unless analytics is set.
+func NewHandler(
+	sugar *zap.SugaredLogger,
+	userService user.Service,
+	actionService action.Service,
+	analytics bool,
+) Handler {
+	root := &resolver{
+		logger:    sugar,
+		users:     userService,
+		actions:   actionService,
+		analytics: analytics,
+	}
+
+	return &graphqlHandler{
+		logger: sugar,
+		schema: graphqlgo.MustParseSchema(schema, root, graphqlgo.MaxDepth(maxDepth)),
+		root:   root,
+	}
+}
+
+// request is a GraphQL request, sent as a JSON body or as query parameters.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Query executes a query and responds with 200 and the data and errors, as GraphQL
+// clients expect, unless the request itself cannot be read.
+func (h *graphqlHandler) Query() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := readRequest(r)
+		if err != nil {
+			apierror.Write(w, r, err)
+
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), loadersKey{}, h.root.newLoaders())
+
+		resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+		response.WriteJSON(h.logger, w, r, http.StatusOK, resp)
+	}
+}
+
+func readRequest(r *http.Request) (*request, error) {
+	var req request
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+
+		if variables := query.Get("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &req.Variables)
+			if err != nil {
+				return nil, apierror.NewAPIError("invalid variables parameter", http.StatusBadRequest)
+			}
+		}
+	case http.MethodPost:
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != response.ContentTypeJSON {
+			return nil, apierror.NewAPIError("content type must be application/json", http.StatusUnsupportedMediaType)
+		}
+
+		err := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes)).Decode(&req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, apierror.NewAPIError("invalid request body", http.StatusBadRequest)
+		}
+	}
+
+	if req.Query == "" {
+		return nil, apierror.NewAPIError("query is required", http.StatusBadRequest)
+	}
+
+	return &req, nil
+}
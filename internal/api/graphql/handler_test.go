@@ -0,0 +1,216 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action"
+	actiondomain "surf_challenge/internal/action/domain"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/user/domain"
+)
+
+func Test_graphqlHandler_Query(t *testing.T) {
+	type mocks struct {
+		users   *user.MockService
+		actions *action.MockService
+	}
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := func(ids ...int64) []*domain.User {
+		found := make([]*domain.User, len(ids))
+		for i, id := range ids {
+			found[i] = &domain.User{ID: id, Name: "user" + string(rune('0'+id)), CreatedAt: createdAt}
+		}
+
+		return found
+	}
+
+	admin := &auth.Principal{Name: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}}
+	reader := &auth.Principal{Name: "reader", Scopes: []auth.Scope{auth.ScopeUsersRead}}
+	endUser := &auth.Principal{Name: "jwt", UserID: "1", Scopes: []auth.Scope{auth.ScopeUsersRead}}
+	analyst := &auth.Principal{Name: "jwt", UserID: "1", Scopes: []auth.Scope{auth.ScopeUsersRead, auth.ScopeAnalyticsRead}}
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		body       string
+		mock       func(m *mocks)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:      "When a user is queried with its actions, should batch the lookups of the target users",
+			principal: admin,
+			body:      `{"query": "{ user(id: \"1\") { name actionCount actions(last: 2) { id type targetUser { name } } } }"}`,
+			mock: func(m *mocks) {
+				m.users.EXPECT().GetUsersByIDs(gomock.Any(), []int64{1}).Return(users(1), nil)
+				m.actions.EXPECT().GetActionsByUserIDs(gomock.Any(), []int64{1}).Return(
+					map[int64][]*actiondomain.Action{
+						1: {
+							{ID: 10, Type: "VIEW_CONTACTS", UserID: 1, CreatedAt: createdAt},
+							{ID: 11, Type: "REFER_USER", UserID: 1, TargetUser: 2, CreatedAt: createdAt.Add(time.Hour)},
+							{ID: 12, Type: "REFER_USER", UserID: 1, TargetUser: 3, CreatedAt: createdAt.Add(2 * time.Hour)},
+						},
+					}, nil,
+				)
+				m.users.EXPECT().GetUsersByIDs(gomock.Any(), gomock.InAnyOrder([]int64{2, 3})).Return(users(2, 3), nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"data": {"user": {"name": "user1", "actionCount": 3, "actions": [
+				{"id": "12", "type": "REFER_USER", "targetUser": {"name": "user3"}},
+				{"id": "11", "type": "REFER_USER", "targetUser": {"name": "user2"}}
+			]}}}`,
+		},
+		{
+			name:      "When a referral tree is queried, should fetch each level at once",
+			principal: admin,
+			body: `{"query": "query($id: ID!) { referralTree(userId: $id) { user { name } referralCount referrals { ` +
+				`user { name } referrals { user { name } referrals { referralCount } } } } }", "variables": {"id": "1"}}`,
+			mock: func(m *mocks) {
+				// Fields resolve concurrently so a level may be fetched along with the next
+				// one, but never one node at a time: 3 levels take at most 3 fetches.
+				m.users.EXPECT().GetUsersByIDs(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, ids []int64) ([]*domain.User, error) {
+						// User 4 was never imported.
+						return users(slices.DeleteFunc(slices.Clone(ids), func(id int64) bool { return id == 4 })...), nil
+					},
+				).MinTimes(1).MaxTimes(3)
+				m.actions.EXPECT().GetReferredUsers(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, ids []int64) (map[int64][]int64, error) {
+						tree := map[int64][]int64{1: {2, 3}, 2: {4}}
+
+						referred := make(map[int64][]int64)
+						for _, id := range ids {
+							if children, ok := tree[id]; ok {
+								referred[id] = children
+							}
+						}
+
+						return referred, nil
+					},
+				).MinTimes(1).MaxTimes(3)
+				m.actions.EXPECT().GetUsersReferrals(gomock.Any()).Return(map[int]int{1: 3, 2: 1}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"data": {"referralTree": {"user": {"name": "user1"}, "referralCount": 3, "referrals": [
+				{"user": {"name": "user2"}, "referrals": [{"user": null, "referrals": []}]},
+				{"user": {"name": "user3"}, "referrals": []}
+			]}}}`,
+		},
+		{
+			name:      "When the user does not exist, should return null",
+			principal: admin,
+			body:      `{"query": "{ user(id: \"9\") { name } }"}`,
+			mock: func(m *mocks) {
+				m.users.EXPECT().GetUsersByIDs(gomock.Any(), []int64{9}).Return(nil, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data": {"user": null}}`,
+		},
+		{
+			name:       "When an end user queries another user, should return a forbidden error",
+			principal:  endUser,
+			body:       `{"query": "{ user(id: \"2\") { name } }"}`,
+			mock:       func(m *mocks) {},
+			wantStatus: http.StatusOK,
+			wantBody: `{"errors": [{"message": "Not allowed to access this resource", "path": ["user"],
+				"extensions": {"status": 403}}], "data": {"user": null}}`,
+		},
+		{
+			name:      "When an end user follows an action to another user, should return a forbidden error for it",
+			principal: endUser,
+			body:      `{"query": "{ user(id: \"1\") { name actions { id user { name } targetUser { name actions { id } } } } }"}`,
+			mock: func(m *mocks) {
+				m.users.EXPECT().GetUsersByIDs(gomock.Any(), []int64{1}).Return(users(1), nil)
+				m.actions.EXPECT().GetActionsByUserIDs(gomock.Any(), []int64{1}).Return(
+					map[int64][]*actiondomain.Action{
+						1: {{ID: 11, Type: "REFER_USER", UserID: 1, TargetUser: 2, CreatedAt: createdAt}},
+					}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"errors": [{"message": "Not allowed to access this resource", "path": ["user", "actions", 0, "targetUser"],
+				"extensions": {"status": 403}}], "data": {"user": {"name": "user1", "actions": [
+				{"id": "11", "user": {"name": "user1"}, "targetUser": null}
+			]}}}`,
+		},
+		{
+			name:      "When an end user queries a referral tree, should only return their own user",
+			principal: analyst,
+			body:      `{"query": "{ referralTree(userId: \"2\") { user { name } referralCount referrals { user { name } } } }"}`,
+			mock: func(m *mocks) {
+				m.users.EXPECT().GetUsersByIDs(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, ids []int64) ([]*domain.User, error) {
+						return users(ids...), nil
+					},
+				).MinTimes(1).MaxTimes(2)
+				m.actions.EXPECT().GetReferredUsers(gomock.Any(), []int64{2}).Return(map[int64][]int64{2: {1}}, nil)
+				m.actions.EXPECT().GetUsersReferrals(gomock.Any()).Return(map[int]int{2: 1}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"errors": [{"message": "Not allowed to access this resource", "path": ["referralTree", "user"],
+				"extensions": {"status": 403}}], "data": {"referralTree": {"user": null, "referralCount": 1, "referrals": [
+				{"user": {"name": "user1"}}
+			]}}}`,
+		},
+		{
+			name:      "When the caller lacks the analytics scope, should return an error for the referral fields",
+			principal: reader,
+			body:      `{"query": "{ user(id: \"1\") { name referrals { referralCount } } }"}`,
+			mock: func(m *mocks) {
+				m.users.EXPECT().GetUsersByIDs(gomock.Any(), []int64{1}).Return(users(1), nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"errors": [{"message": "missing scope analytics:read", "path": ["user", "referrals"],
+				"extensions": {"status": 403}}], "data": {"user": null}}`,
+		},
+		{
+			name:       "When the query is missing, should return 400",
+			principal:  admin,
+			body:       `{}`,
+			mock:       func(m *mocks) {},
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "query is required",
+				"instance": "/graphql"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+
+				m := &mocks{
+					users:   user.NewMockService(ctrl),
+					actions: action.NewMockService(ctrl),
+				}
+
+				tt.mock(m)
+
+				handler := NewHandler(zap.NewNop().Sugar(), m.users, m.actions, true)
+
+				req := httptest.NewRequestWithContext(
+					auth.WithPrincipal(t.Context(), tt.principal), http.MethodPost, "/graphql", strings.NewReader(tt.body),
+				)
+				req.Header.Set("Content-Type", "application/json")
+
+				recorder := httptest.NewRecorder()
+				handler.Query().ServeHTTP(recorder, req)
+
+				require.Equal(t, tt.wantStatus, recorder.Code)
+				assert.JSONEq(t, tt.wantBody, recorder.Body.String())
+			},
+		)
+	}
+}
@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// loader batches the lookups of a request, in the fashion of dataloader: resolvers
+// Prime the keys they are about to Load, typically every element of a list, and the
+// first Load fetches all pending keys at once. Each level of a query thus costs one
+// fetch whatever the number of elements, instead of one per element.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending map[K]struct{}
+	fetched map[K]struct{}
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]struct{}),
+		fetched: make(map[K]struct{}),
+		values:  make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// Prime queues keys for the next fetch, without fetching them.
+func (l *loader[K, V]) Prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := l.fetched[key]; !ok {
+			l.pending[key] = struct{}{}
+		}
+	}
+}
+
+// Load returns the value of key, fetching it along with every pending key when not
+// fetched yet. ok is false when the fetch returned no value for key.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (value V, ok bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, fetched := l.fetched[key]; !fetched {
+		l.pending[key] = struct{}{}
+		l.flush(ctx)
+	}
+
+	if err = l.errs[key]; err != nil {
+		return value, false, err
+	}
+
+	value, ok = l.values[key]
+
+	return value, ok, nil
+}
+
+// flush fetches every pending key in a single call. A failed fetch fails all of them.
+func (l *loader[K, V]) flush(ctx context.Context) {
+	keys := make([]K, 0, len(l.pending))
+	for key := range l.pending {
+		keys = append(keys, key)
+		l.fetched[key] = struct{}{}
+	}
+
+	clear(l.pending)
+
+	values, err := l.fetch(ctx, keys)
+
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+
+			continue
+		}
+
+		if value, ok := values[key]; ok {
+			l.values[key] = value
+		}
+	}
+}
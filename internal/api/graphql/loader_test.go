@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loader(t *testing.T) {
+	var batches [][]int
+
+	l := newLoader(
+		func(_ context.Context, keys []int) (map[int]string, error) {
+			batches = append(batches, keys)
+
+			values := make(map[int]string)
+			for _, key := range keys {
+				if key != 3 {
+					values[key] = "v" + string(rune('0'+key))
+				}
+			}
+
+			return values, nil
+		},
+	)
+
+	l.Prime(1, 2, 3)
+
+	value, ok, err := l.Load(t.Context(), 2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "v2", value)
+
+	_, ok, err = l.Load(t.Context(), 3)
+	require.NoError(t, err)
+	assert.False(t, ok, "should report keys without value")
+
+	value, _, _ = l.Load(t.Context(), 1)
+	assert.Equal(t, "v1", value)
+
+	require.Len(t, batches, 1, "should fetch the primed keys at once")
+	assert.ElementsMatch(t, []int{1, 2, 3}, batches[0])
+
+	_, _, _ = l.Load(t.Context(), 4)
+
+	require.Len(t, batches, 2)
+	assert.Equal(t, []int{4}, batches[1], "should only fetch keys not fetched yet")
+}
+
+func Test_loader_error(t *testing.T) {
+	l := newLoader(
+		func(context.Context, []int) (map[int]string, error) {
+			return nil, assert.AnError
+		},
+	)
+
+	l.Prime(1, 2)
+
+	_, _, err := l.Load(t.Context(), 1)
+	require.ErrorIs(t, err, assert.AnError)
+
+	_, ok, err := l.Load(t.Context(), 2)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, assert.AnError, "should fail every key of the batch")
+}
@@ -0,0 +1,404 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action"
+	actiondomain "surf_challenge/internal/action/domain"
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/logger"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/user/domain"
+)
+
+var errAnalyticsDisabled = apierror.NewAPIError("analytics are disabled", http.StatusNotFound)
+
+// resolver is the root resolver. Request-scoped state, the loaders, lives in the
+// context so that a single resolver serves every request.
+type resolver struct {
+	logger    *zap.SugaredLogger
+	users     user.Service
+	actions   action.Service
+	analytics bool
+}
+
+// loaders batches the lookups of one request, see loader.
+type loaders struct {
+	users    *loader[int64, *domain.User]
+	actions  *loader[int64, []*actiondomain.Action]
+	referred *loader[int64, []int64]
+
+	referralCountsOnce sync.Once
+	referralCounts     map[int]int
+	referralCountsErr  error
+}
+
+type loadersKey struct{}
+
+func (r *resolver) newLoaders() *loaders {
+	return &loaders{
+		users: newLoader(
+			func(ctx context.Context, ids []int64) (map[int64]*domain.User, error) {
+				users, err := r.users.GetUsersByIDs(ctx, ids)
+				if err != nil {
+					return nil, err
+				}
+
+				byID := make(map[int64]*domain.User, len(users))
+				for _, u := range users {
+					byID[u.ID] = u
+				}
+
+				return byID, nil
+			},
+		),
+		actions:  newLoader(r.actions.GetActionsByUserIDs),
+		referred: newLoader(r.actions.GetReferredUsers),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// prime queues the users for every per-user loader, so that the fields of a list of
+// users are each resolved with a single fetch.
+func (l *loaders) prime(ids ...int64) {
+	l.users.Prime(ids...)
+	l.actions.Prime(ids...)
+	l.referred.Prime(ids...)
+}
+
+// referralCount reads the referral index once per request.
+func (l *loaders) referralCount(ctx context.Context, r *resolver, userID int64) (int32, error) {
+	l.referralCountsOnce.Do(
+		func() {
+			l.referralCounts, l.referralCountsErr = r.actions.GetUsersReferrals(ctx)
+		},
+	)
+
+	if l.referralCountsErr != nil {
+		return 0, l.referralCountsErr
+	}
+
+	return int32(l.referralCounts[int(userID)]), nil
+}
+
+// fieldError exposes the message of a service error, mapped like the REST API does,
+// and its HTTP status as an extension.
+type fieldError struct {
+	apiErr *apierror.APIError
+}
+
+func (e *fieldError) Error() string {
+	return e.apiErr.Message
+}
+
+func (e *fieldError) Extensions() map[string]any {
+	return map[string]any{"status": e.apiErr.Code}
+}
+
+// publicError hides the details of internal errors from clients, logging them instead.
+func (r *resolver) publicError(ctx context.Context, err error) error {
+	apiErr := apierror.MapErrors(err)
+	if apiErr.Code >= http.StatusInternalServerError {
+		logger.FromContext(ctx, r.logger).Errorw("failed to resolve GraphQL field", "error", err)
+	}
+
+	return &fieldError{apiErr: apiErr}
+}
+
+// authorizeUser fails unless the caller may read the user with id, or every user when
+// id is empty, the same rule as the REST routes.
+func (r *resolver) authorizeUser(ctx context.Context, id string) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return r.publicError(ctx, auth.ErrUnauthenticated)
+	}
+
+	if !principal.CanAccessUser(id) {
+		return r.publicError(ctx, auth.ErrForbidden)
+	}
+
+	return nil
+}
+
+// authorizeAnalytics guards the referral fields like the /actions routes.
+func (r *resolver) authorizeAnalytics(ctx context.Context) error {
+	if !r.analytics {
+		return r.publicError(ctx, errAnalyticsDisabled)
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return r.publicError(ctx, auth.ErrUnauthenticated)
+	}
+
+	if !principal.HasScope(auth.ScopeAnalyticsRead) {
+		return r.publicError(ctx, apierror.NewAPIError("missing scope "+string(auth.ScopeAnalyticsRead), http.StatusForbidden))
+	}
+
+	return nil
+}
+
+func parseID(id graphqlgo.ID) (int64, error) {
+	parsed, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, &fieldError{apiErr: apierror.NewAPIError("invalid id "+strconv.Quote(string(id)), http.StatusBadRequest)}
+	}
+
+	return parsed, nil
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphqlgo.ID }) (*userResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.loadUser(ctx, id)
+}
+
+func (r *resolver) Users(ctx context.Context, args struct{ Page, PageSize int32 }) ([]*userResolver, error) {
+	err := r.authorizeUser(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case args.Page < 1:
+		return nil, &fieldError{apiErr: apierror.NewAPIError("invalid page parameter", http.StatusBadRequest)}
+	case args.PageSize < 1:
+		return nil, &fieldError{apiErr: apierror.NewAPIError("invalid pageSize parameter", http.StatusBadRequest)}
+	}
+
+	users, _, err := r.users.QueryUsers(ctx, domain.Query{Page: int(args.Page), PageSize: int(args.PageSize)})
+	if err != nil {
+		return nil, r.publicError(ctx, err)
+	}
+
+	ids := make([]int64, len(users))
+	resolvers := make([]*userResolver, len(users))
+
+	for i, u := range users {
+		ids[i] = u.ID
+		resolvers[i] = &userResolver{root: r, user: u}
+	}
+
+	loadersFrom(ctx).prime(ids...)
+
+	return resolvers, nil
+}
+
+func (r *resolver) ReferralTree(ctx context.Context, args struct{ UserID graphqlgo.ID }) (*referralNodeResolver, error) {
+	id, err := parseID(args.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.authorizeAnalytics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tree itself is analytics; the users in it are checked by their own field.
+	_, ok, err := loadersFrom(ctx).users.Load(ctx, id)
+	if err != nil {
+		return nil, r.publicError(ctx, err)
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	return &referralNodeResolver{root: r, userID: id}, nil
+}
+
+// loadUser returns nil when the user does not exist. Every field returning a user goes
+// through it, so that end users cannot reach other users through nested fields.
+func (r *resolver) loadUser(ctx context.Context, id int64) (*userResolver, error) {
+	err := r.authorizeUser(ctx, strconv.FormatInt(id, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	u, ok, err := loadersFrom(ctx).users.Load(ctx, id)
+	if err != nil {
+		return nil, r.publicError(ctx, err)
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	return &userResolver{root: r, user: u}, nil
+}
+
+type userResolver struct {
+	root *resolver
+	user *domain.User
+}
+
+func (u *userResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(strconv.FormatInt(u.user.ID, 10))
+}
+
+func (u *userResolver) Name() string {
+	return u.user.Name
+}
+
+func (u *userResolver) CreatedAt() string {
+	return u.user.CreatedAt.Format(time.RFC3339)
+}
+
+func (u *userResolver) Version() int32 {
+	return int32(u.user.Version)
+}
+
+func (u *userResolver) Actions(
+	ctx context.Context,
+	args struct {
+		Type *string
+		Last *int32
+	},
+) ([]*actionResolver, error) {
+	actions, err := u.loadActions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter actiondomain.Filter
+	if args.Type != nil {
+		filter.Type = *args.Type
+	}
+
+	// The loader returns the actions in chronological order.
+	var resolvers []*actionResolver
+
+	var related []int64
+
+	for _, a := range slices.Backward(actions) {
+		if args.Last != nil && len(resolvers) >= int(*args.Last) {
+			break
+		}
+
+		if !filter.Matches(a) {
+			continue
+		}
+
+		resolvers = append(resolvers, &actionResolver{root: u.root, action: a})
+		related = append(related, int64(a.UserID))
+
+		if a.TargetUser != 0 {
+			related = append(related, int64(a.TargetUser))
+		}
+	}
+
+	loadersFrom(ctx).users.Prime(related...)
+
+	return resolvers, nil
+}
+
+func (u *userResolver) ActionCount(ctx context.Context) (int32, error) {
+	actions, err := u.loadActions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(len(actions)), nil
+}
+
+func (u *userResolver) loadActions(ctx context.Context) ([]*actiondomain.Action, error) {
+	actions, _, err := loadersFrom(ctx).actions.Load(ctx, u.user.ID)
+	if err != nil {
+		return nil, u.root.publicError(ctx, err)
+	}
+
+	return actions, nil
+}
+
+func (u *userResolver) Referrals(ctx context.Context) (*referralNodeResolver, error) {
+	err := u.root.authorizeAnalytics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &referralNodeResolver{root: u.root, userID: u.user.ID}, nil
+}
+
+type actionResolver struct {
+	root   *resolver
+	action *actiondomain.Action
+}
+
+func (a *actionResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(strconv.Itoa(a.action.ID))
+}
+
+func (a *actionResolver) Type() string {
+	return a.action.Type
+}
+
+func (a *actionResolver) CreatedAt() string {
+	return a.action.CreatedAt.Format(time.RFC3339)
+}
+
+func (a *actionResolver) User(ctx context.Context) (*userResolver, error) {
+	return a.root.loadUser(ctx, int64(a.action.UserID))
+}
+
+func (a *actionResolver) TargetUser(ctx context.Context) (*userResolver, error) {
+	if !strings.EqualFold(a.action.Type, actiondomain.ActionTypeReferUser) || a.action.TargetUser == 0 {
+		return nil, nil
+	}
+
+	return a.root.loadUser(ctx, int64(a.action.TargetUser))
+}
+
+// referralNodeResolver is a node of the referral graph. Its user may be missing from
+// the users dataset, actions referencing users that were never imported.
+type referralNodeResolver struct {
+	root   *resolver
+	userID int64
+}
+
+func (n *referralNodeResolver) User(ctx context.Context) (*userResolver, error) {
+	return n.root.loadUser(ctx, n.userID)
+}
+
+func (n *referralNodeResolver) ReferralCount(ctx context.Context) (int32, error) {
+	count, err := loadersFrom(ctx).referralCount(ctx, n.root, n.userID)
+	if err != nil {
+		return 0, n.root.publicError(ctx, err)
+	}
+
+	return count, nil
+}
+
+func (n *referralNodeResolver) Referrals(ctx context.Context) ([]*referralNodeResolver, error) {
+	l := loadersFrom(ctx)
+
+	referred, _, err := l.referred.Load(ctx, n.userID)
+	if err != nil {
+		return nil, n.root.publicError(ctx, err)
+	}
+
+	l.prime(referred...)
+
+	resolvers := make([]*referralNodeResolver, len(referred))
+	for i, id := range referred {
+		resolvers[i] = &referralNodeResolver{root: n.root, userID: id}
+	}
+
+	return resolvers, nil
+}
@@ -0,0 +1,28 @@
+package dto
+
+const (
+	StatusOK       = "ok"
+	StatusReady    = "ready"
+	StatusNotReady = "not ready"
+)
+
+type Health struct {
+	Status string `json:"status"`
+}
+
+type Dataset struct {
+	Records int    `json:"records"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Readiness struct {
+	Status   string             `json:"status"`
+	Datasets map[string]Dataset `json:"datasets"`
+}
+
+type Version struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
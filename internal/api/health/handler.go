@@ -0,0 +1,84 @@
+package health
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/api/health/dto"
+	"surf_challenge/internal/api/response"
+	"surf_challenge/internal/buildinfo"
+	"surf_challenge/internal/health"
+	"surf_challenge/internal/logger"
+)
+
+type Handler interface {
+	Liveness() http.HandlerFunc
+	Readiness() http.HandlerFunc
+	Version() http.HandlerFunc
+}
+
+type healthHandler struct {
+	logger  *zap.SugaredLogger
+	checker health.Checker
+}
+
+func NewHandler(sugar *zap.SugaredLogger, checker health.Checker) Handler {
+	return &healthHandler{
+		logger:  sugar,
+		checker: checker,
+	}
+}
+
+func (h *healthHandler) Liveness() http.HandlerFunc {
+	return response.Handle(
+		h.logger, "failed to report liveness", func(*http.Request) (dto.Health, error) {
+			return dto.Health{Status: dto.StatusOK}, nil
+		},
+	)
+}
+
+func (h *healthHandler) Readiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := h.checker.Readiness(r.Context())
+
+		resp := dto.Readiness{
+			Status:   dto.StatusReady,
+			Datasets: make(map[string]dto.Dataset, len(report.Datasets)),
+		}
+
+		for _, dataset := range report.Datasets {
+			status := dto.Dataset{Records: dataset.Records}
+			if dataset.Err != nil {
+				status.Error = dataset.Err.Error()
+			}
+
+			resp.Datasets[dataset.Name] = status
+		}
+
+		code := http.StatusOK
+		if !report.Ready {
+			logger.FromContext(r.Context(), h.logger).Warnw("readiness check failed", "datasets", resp.Datasets)
+
+			resp.Status = dto.StatusNotReady
+			code = http.StatusServiceUnavailable
+		}
+
+		response.WriteJSON(h.logger, w, r, code, resp)
+	}
+}
+
+func (h *healthHandler) Version() http.HandlerFunc {
+	return response.Handle(
+		h.logger, "failed to report version", func(*http.Request) (dto.Version, error) {
+			info := buildinfo.Get()
+
+			return dto.Version{
+				Version:   info.Version,
+				Commit:    info.Commit,
+				BuildDate: info.BuildDate,
+				GoVersion: info.GoVersion,
+			}, nil
+		},
+	)
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/buildinfo"
+	"surf_challenge/internal/health"
+)
+
+func Test_healthHandler_Liveness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/healthz", nil)
+	recorder := httptest.NewRecorder()
+
+	NewHandler(zap.NewNop().Sugar(), health.NewMockChecker(ctrl)).Liveness().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
+
+func Test_healthHandler_Readiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		report     *health.Report
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "When every dataset is loaded, should be ready",
+			report: &health.Report{
+				Ready: true,
+				Datasets: []health.DatasetStatus{
+					{Name: "users", Records: 2},
+					{Name: "actions", Records: 5},
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ready","datasets":{"users":{"records":2},"actions":{"records":5}}}`,
+		},
+		{
+			name: "When a dataset failed to load, should be unavailable and report the error",
+			report: &health.Report{
+				Ready: false,
+				Datasets: []health.DatasetStatus{
+					{Name: "users", Records: 2},
+					{Name: "actions", Err: assert.AnError},
+				},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: `{"status":"not ready","datasets":{"users":{"records":2},"actions":{"records":0,"error":"` +
+				assert.AnError.Error() + `"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+
+				checker := health.NewMockChecker(ctrl)
+				checker.EXPECT().Readiness(gomock.Any()).Return(tt.report)
+
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/readyz", nil)
+				recorder := httptest.NewRecorder()
+
+				NewHandler(zap.NewNop().Sugar(), checker).Readiness().ServeHTTP(recorder, req)
+
+				require.Equal(t, tt.wantStatus, recorder.Code)
+				assert.JSONEq(t, tt.wantBody, recorder.Body.String())
+			},
+		)
+	}
+}
+
+func Test_healthHandler_Version(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/version", nil)
+	recorder := httptest.NewRecorder()
+
+	NewHandler(zap.NewNop().Sugar(), health.NewMockChecker(ctrl)).Version().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"version":"`+buildinfo.Version+`"`)
+	assert.Contains(t, recorder.Body.String(), `"goVersion":"go`)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/logger"
+)
+
+// Authenticate resolves the caller with authenticator and stores it in the request
+// context. Requests without valid credentials are rejected with 401.
+func Authenticate(authenticator auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				principal, err := authenticator.Authenticate(r)
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", authenticator.Challenge())
+					apierror.Write(w, r, err)
+
+					return
+				}
+
+				ctx := auth.WithPrincipal(r.Context(), principal)
+				if l := logger.FromContext(ctx, nil); l != nil {
+					ctx = logger.WithContext(ctx, l.With("principal", principal.Name))
+				}
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// RequireScope rejects callers that were not granted scope with 403. It must run after
+// Authenticate.
+func RequireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				principal, ok := auth.PrincipalFromContext(r.Context())
+				if !ok {
+					apierror.Write(w, r, auth.ErrUnauthenticated)
+
+					return
+				}
+
+				if !principal.HasScope(scope) {
+					apierror.Write(w, r, auth.ErrForbidden)
+
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// RequireUserAccess restricts end users to the user identified by the param URL
+// parameter, so they can only read their own records. Routes without the parameter,
+// such as listings, are reserved to services and admins. It must run after routing,
+// through chi's With, for the URL parameter to be resolved.
+func RequireUserAccess(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				principal, ok := auth.PrincipalFromContext(r.Context())
+				if !ok {
+					apierror.Write(w, r, auth.ErrUnauthenticated)
+
+					return
+				}
+
+				if !principal.CanAccessUser(chi.URLParam(r, param)) {
+					apierror.Write(w, r, auth.ErrForbidden)
+
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
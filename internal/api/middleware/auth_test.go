@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/logger"
+)
+
+func Test_Authenticate_RequireScope(t *testing.T) {
+	authenticator, err := auth.NewAPIKeyAuthenticator(
+		[]auth.APIKey{
+			{Name: "reader", SHA256: auth.HashAPIKey("reader-key"), Scopes: []string{"users:read"}},
+			{Name: "ops", SHA256: auth.HashAPIKey("ops-key"), Scopes: []string{"admin"}},
+		},
+	)
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(Authenticate(authenticator))
+	router.With(RequireScope(auth.ScopeUsersRead)).Get("/users", okHandler)
+	router.With(RequireScope(auth.ScopeAnalyticsRead)).Get("/actions/referrals", okHandler)
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+		wantDetail string
+	}{
+		{
+			name:       "When the key has the route scope, should serve the request",
+			path:       "/users",
+			key:        "reader-key",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When the key is an admin key, should serve any route",
+			path:       "/actions/referrals",
+			key:        "ops-key",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When the key lacks the route scope, should return 403",
+			path:       "/actions/referrals",
+			key:        "reader-key",
+			wantStatus: http.StatusForbidden,
+			wantDetail: "Not allowed to access this resource",
+		},
+		{
+			name:       "When the key is unknown, should return 401",
+			path:       "/users",
+			key:        "guess",
+			wantStatus: http.StatusUnauthorized,
+			wantDetail: "Missing or invalid credentials",
+		},
+		{
+			name:       "When no key is sent, should return 401",
+			path:       "/users",
+			wantStatus: http.StatusUnauthorized,
+			wantDetail: "Missing or invalid credentials",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.path, nil)
+				if tt.key != "" {
+					req.Header.Set(auth.HeaderAPIKey, tt.key)
+				}
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.wantStatus, rec.Code)
+
+				if tt.wantDetail == "" {
+					return
+				}
+
+				var problem apierror.Problem
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+				assert.Equal(t, tt.wantDetail, problem.Detail)
+				assert.Equal(t, apierror.ContentTypeProblem, rec.Header().Get("Content-Type"))
+
+				if tt.wantStatus == http.StatusUnauthorized {
+					assert.Equal(t, `APIKey header="X-API-Key"`, rec.Header().Get("WWW-Authenticate"))
+				}
+			},
+		)
+	}
+}
+
+func Test_Authenticate_logger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	fallback := zap.New(core).Sugar()
+
+	tests := []struct {
+		name       string
+		logger     *zap.SugaredLogger
+		wantFields map[string]any
+	}{
+		{
+			name:       "When the request has a logger, should annotate it with the principal",
+			logger:     fallback.With("requestId", "r1"),
+			wantFields: map[string]any{"requestId": "r1", "principal": "anonymous"},
+		},
+		{
+			name:       "When the request has no logger, should leave the fallback of the handlers",
+			wantFields: map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				logs.TakeAll()
+
+				handler := Authenticate(auth.NewAnonymousAuthenticator())(
+					http.HandlerFunc(
+						func(_ http.ResponseWriter, r *http.Request) {
+							logger.FromContext(r.Context(), fallback).Info("handled")
+						},
+					),
+				)
+
+				ctx := t.Context()
+				if tt.logger != nil {
+					ctx = logger.WithContext(ctx, tt.logger)
+				}
+
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+				entries := logs.TakeAll()
+				require.Len(t, entries, 1)
+				assert.Equal(t, tt.wantFields, entries[0].ContextMap())
+			},
+		)
+	}
+}
+
+func okHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func Test_RequireUserAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "When an end user reads themselves, should serve the request",
+			principal:  &auth.Principal{UserID: "7", Scopes: []auth.Scope{auth.ScopeUsersRead}},
+			path:       "/users/7",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When an end user reads someone else, should return 403",
+			principal:  &auth.Principal{UserID: "7", Scopes: []auth.Scope{auth.ScopeUsersRead}},
+			path:       "/users/8",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "When an end user lists users, should return 403",
+			principal:  &auth.Principal{UserID: "7", Scopes: []auth.Scope{auth.ScopeUsersRead}},
+			path:       "/users",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "When an admin reads someone else, should serve the request",
+			principal:  &auth.Principal{UserID: "1", Scopes: []auth.Scope{auth.ScopeAdmin}},
+			path:       "/users/8",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When a service reads a user, should serve the request",
+			principal:  &auth.Principal{Name: "service", Scopes: []auth.Scope{auth.ScopeUsersRead}},
+			path:       "/users/8",
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				router := chi.NewRouter()
+				router.Use(
+					func(next http.Handler) http.Handler {
+						return http.HandlerFunc(
+							func(w http.ResponseWriter, r *http.Request) {
+								next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), tt.principal)))
+							},
+						)
+					},
+				)
+
+				users := router.With(RequireUserAccess("userId"))
+				users.Get("/users", okHandler)
+				users.Get("/users/{userId}", okHandler)
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.path, nil))
+
+				assert.Equal(t, tt.wantStatus, rec.Code)
+			},
+		)
+	}
+}
@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
+)
+
+// compressibleTypes are the content types worth compressing; /metrics is left out as
+// the Prometheus handler compresses its own output.
+var compressibleTypes = []string{
+	response.ContentTypeJSON,
+	response.ContentTypeNDJSON,
+	response.ContentTypeCSV,
+	apierror.ContentTypeProblem,
+}
+
+// encodingPrecedence lists the encodings of the compressor, preferred first on ties.
+var encodingPrecedence = []string{"br", "gzip", "deflate"}
+
+// Compress encodes responses with brotli or gzip, whichever the client Accept-Encoding
+// gives the highest q-value, brotli winning ties; q=0 refuses an encoding. level applies
+// to both, from 1 (fastest) to 9.
+//
+// A strong ETag identifies the exact bytes sent, so encoded responses get the encoding
+// appended to the tag of the handler ("abc" becomes "abc-gzip"), and the conditional
+// headers of requests are translated back before reaching the handlers.
+func Compress(level int) func(http.Handler) http.Handler {
+	compressor := chimiddleware.NewCompressor(level, compressibleTypes...)
+	compressor.SetEncoder(
+		"br", func(w io.Writer, level int) io.Writer {
+			return brotli.NewWriterLevel(w, level)
+		},
+	)
+
+	return func(next http.Handler) http.Handler {
+		compress := compressor.Handler(next)
+
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				// The compressor matches names without their q-values, so it only gets the
+				// encoding chosen here.
+				var encoding string
+
+				if accept := r.Header.Get("Accept-Encoding"); accept != "" {
+					r.Header.Del("Accept-Encoding")
+
+					encoding = preferredEncoding(accept)
+					if encoding != "" {
+						r.Header.Set("Accept-Encoding", encoding)
+					}
+				}
+
+				untagConditions(r.Header, encoding)
+
+				if encoding != "" {
+					w = &etagWriter{ResponseWriter: w, encoding: encoding}
+				}
+
+				compress.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// preferredEncoding returns the encoding of encodingPrecedence with the highest q-value
+// in an Accept-Encoding header, "*" standing for those not listed, or "" when all are refused.
+func preferredEncoding(accept string) string {
+	qualities := make(map[string]float64)
+
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+
+			q = parsed
+		}
+
+		if name != "" {
+			qualities[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+
+	for _, encoding := range encodingPrecedence {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = qualities["*"]
+		}
+
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// etagWriter appends the encoding to the strong ETag of encoded responses, and of 304
+// responses, which stand for the encoded representation the client holds.
+type etagWriter struct {
+	http.ResponseWriter
+	encoding    string
+	wroteHeader bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		header := w.Header()
+		etag := header.Get("ETag")
+
+		encoded := code == http.StatusNotModified || header.Get("Content-Encoding") == w.encoding
+		if encoded && strings.HasPrefix(etag, `"`) {
+			header.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+w.encoding+`"`)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *etagWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *etagWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// untagConditions strips the encoding suffix etagWriter appends from the entity tags of
+// the conditional request headers. If-None-Match only matches the variant of the
+// negotiated encoding, while If-Match compares the resource state whatever the encoding
+// the client saw.
+func untagConditions(header http.Header, encoding string) {
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" && encoding != "" {
+		header.Set("If-None-Match", untag(ifNoneMatch, []string{encoding}))
+	}
+
+	if ifMatch := header.Get("If-Match"); ifMatch != "" {
+		header.Set("If-Match", untag(ifMatch, encodingPrecedence))
+	}
+}
+
+func untag(tags string, encodings []string) string {
+	candidates := strings.Split(tags, ",")
+
+	for i, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+
+		if strings.HasPrefix(candidate, `"`) {
+			for _, encoding := range encodings {
+				if trimmed, ok := strings.CutSuffix(candidate, "-"+encoding+`"`); ok {
+					candidate = trimmed + `"`
+
+					break
+				}
+			}
+		}
+
+		candidates[i] = candidate
+	}
+
+	return strings.Join(candidates, ", ")
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Compress(t *testing.T) {
+	body := strings.Repeat(`{"id":"1","name":"John Doe"}`+"\n", 100)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		wantEncoding   string
+		decode         func(io.Reader) (io.Reader, error)
+	}{
+		{
+			name:           "When the client accepts brotli and gzip, should prefer brotli",
+			acceptEncoding: "gzip, br",
+			contentType:    "application/json",
+			wantEncoding:   "br",
+			decode: func(r io.Reader) (io.Reader, error) {
+				return brotli.NewReader(r), nil
+			},
+		},
+		{
+			name:           "When the client only accepts gzip, should use gzip",
+			acceptEncoding: "gzip",
+			contentType:    "application/x-ndjson",
+			wantEncoding:   "gzip",
+			decode: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		{
+			name:           "When the client refuses brotli, should use gzip",
+			acceptEncoding: "gzip, br;q=0",
+			contentType:    "application/json",
+			wantEncoding:   "gzip",
+			decode: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		{
+			name:           "When the client gives gzip a higher q-value, should use gzip",
+			acceptEncoding: "gzip;q=1, br;q=0.5",
+			contentType:    "application/json",
+			wantEncoding:   "gzip",
+			decode: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		{
+			name:           "When the client accepts any encoding, should prefer brotli",
+			acceptEncoding: "*",
+			contentType:    "application/json",
+			wantEncoding:   "br",
+			decode: func(r io.Reader) (io.Reader, error) {
+				return brotli.NewReader(r), nil
+			},
+		},
+		{
+			name:           "When the client refuses every encoding, should send identity",
+			acceptEncoding: "br;q=0, gzip;q=0, *;q=0",
+			contentType:    "application/json",
+		},
+		{
+			name:           "When the client accepts no encoding, should send identity",
+			acceptEncoding: "",
+			contentType:    "application/json",
+		},
+		{
+			name:           "When the content type is not compressible, should send identity",
+			acceptEncoding: "gzip, br",
+			contentType:    "text/plain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				handler := Compress(5)(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, _ *http.Request) {
+							w.Header().Set("Content-Type", tt.contentType)
+							_, _ = io.WriteString(w, body)
+						},
+					),
+				)
+
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				if tt.acceptEncoding != "" {
+					req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+				}
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.wantEncoding, rec.Header().Get("Content-Encoding"))
+
+				var reader io.Reader = rec.Body
+				if tt.decode != nil {
+					assert.Less(t, rec.Body.Len(), len(body))
+
+					var err error
+
+					reader, err = tt.decode(rec.Body)
+					require.NoError(t, err)
+				}
+
+				got, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Equal(t, body, string(got))
+			},
+		)
+	}
+}
+
+func Test_Compress_etag(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		etag           string
+		headers        map[string]string
+		wantStatus     int
+		wantETag       string
+		wantIfMatch    string
+	}{
+		{
+			name:           "When the response is encoded, should append the encoding to the ETag",
+			acceptEncoding: "gzip",
+			etag:           `"abc"`,
+			wantStatus:     http.StatusOK,
+			wantETag:       `"abc-gzip"`,
+		},
+		{
+			name:       "When the response is not encoded, should keep the ETag",
+			etag:       `"abc"`,
+			wantStatus: http.StatusOK,
+			wantETag:   `"abc"`,
+		},
+		{
+			name:           "When the ETag is weak, should keep it",
+			acceptEncoding: "br",
+			etag:           `W/"abc"`,
+			wantStatus:     http.StatusOK,
+			wantETag:       `W/"abc"`,
+		},
+		{
+			name:           "When If-None-Match names the negotiated variant, should return not modified",
+			acceptEncoding: "gzip",
+			etag:           `"abc"`,
+			headers:        map[string]string{"If-None-Match": `"abc-gzip"`},
+			wantStatus:     http.StatusNotModified,
+			wantETag:       `"abc-gzip"`,
+		},
+		{
+			name:           "When If-None-Match names another variant, should return the response",
+			acceptEncoding: "br",
+			etag:           `"abc"`,
+			headers:        map[string]string{"If-None-Match": `"abc-gzip"`},
+			wantStatus:     http.StatusOK,
+			wantETag:       `"abc-br"`,
+		},
+		{
+			name:        "When If-Match names an encoded variant, should pass the handler tag",
+			etag:        `"abc"`,
+			headers:     map[string]string{"If-Match": `"abc-br", "def"`},
+			wantStatus:  http.StatusOK,
+			wantETag:    `"abc"`,
+			wantIfMatch: `"abc", "def"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var ifMatch string
+
+				handler := Compress(5)(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							ifMatch = r.Header.Get("If-Match")
+
+							w.Header().Set("Content-Type", "application/json")
+							w.Header().Set("ETag", tt.etag)
+
+							if r.Header.Get("If-None-Match") == tt.etag {
+								w.WriteHeader(http.StatusNotModified)
+
+								return
+							}
+
+							_, _ = io.WriteString(w, `{"id":"1"}`)
+						},
+					),
+				)
+
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				if tt.acceptEncoding != "" {
+					req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+				}
+
+				for key, value := range tt.headers {
+					req.Header.Set(key, value)
+				}
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, tt.wantETag, rec.Header().Get("ETag"))
+				assert.Equal(t, tt.wantIfMatch, ifMatch)
+			},
+		)
+	}
+}
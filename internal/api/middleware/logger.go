@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/logger"
+)
+
+// Logger stores a logger annotated with the request ID in the request context, for
+// handlers and services to log through, and emits one access log line per request.
+// It must run after RequestID.
+func Logger(base *zap.SugaredLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				start := time.Now()
+
+				reqLogger := base.With("requestId", chimiddleware.GetReqID(r.Context()))
+				ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+				next.ServeHTTP(ww, r.WithContext(logger.WithContext(r.Context(), reqLogger)))
+
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				route := ""
+				if rctx := chi.RouteContext(r.Context()); rctx != nil {
+					route = rctx.RoutePattern()
+				}
+
+				reqLogger.Infow(
+					"access",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"route", route,
+					"status", status,
+					"bytes", ww.BytesWritten(),
+					"duration", time.Since(start),
+					"remoteAddr", r.RemoteAddr,
+					"userAgent", r.UserAgent(),
+				)
+			},
+		)
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"surf_challenge/internal/logger"
+)
+
+func Test_Logger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	base := zap.New(core).Sugar()
+
+	router := chi.NewRouter()
+	router.Use(RequestID, Logger(base))
+	router.Get(
+		"/users/{userId}", func(w http.ResponseWriter, r *http.Request) {
+			logger.FromContext(r.Context(), zap.NewNop().Sugar()).Infow("handling")
+
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte("hello"))
+		},
+	)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/users/7", nil)
+	req.Header.Set(HeaderRequestID, "req-7")
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	handling := entries[0]
+	assert.Equal(t, "handling", handling.Message)
+	assert.Equal(t, "req-7", handling.ContextMap()["requestId"])
+
+	access := entries[1].ContextMap()
+	assert.Equal(t, "access", entries[1].Message)
+	assert.Equal(t, "req-7", access["requestId"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/users/7", access["path"])
+	assert.Equal(t, "/users/{userId}", access["route"])
+	assert.Equal(t, int64(http.StatusTeapot), access["status"])
+	assert.Equal(t, int64(5), access["bytes"])
+	assert.Contains(t, access, "duration")
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/ratelimit"
+)
+
+// RateLimit rejects clients that exceed their token bucket with 429 and a Retry-After
+// header. Authenticated callers are limited per principal, anonymous ones per remote
+// IP, so it must run after Authenticate. onReject is called with ratelimit.ReasonRate.
+func RateLimit(limiter *ratelimit.Limiter, onReject func(reason string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ok, retryAfter := limiter.Allow(ClientKey(r))
+				if !ok {
+					onReject(ratelimit.ReasonRate)
+					tooManyRequests(w, r, retryAfter, "Rate limit exceeded")
+
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// ThrottleFailedAuth counts the 401 answers per remote IP against limiter and rejects
+// the IPs that exhausted their bucket with 429, so that credentials cannot be guessed
+// at the pace of the principal limits. It must run before Authenticate. onReject is
+// called with ratelimit.ReasonAuth.
+func ThrottleFailedAuth(limiter *ratelimit.Limiter, onReject func(reason string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				key := FailedAuthKey(r)
+
+				exhausted, retryAfter := limiter.Exhausted(key)
+				if exhausted {
+					onReject(ratelimit.ReasonAuth)
+					tooManyRequests(w, r, retryAfter, "Too many failed authentications")
+
+					return
+				}
+
+				ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+				next.ServeHTTP(ww, r)
+
+				if ww.Status() == http.StatusUnauthorized {
+					limiter.Allow(key)
+				}
+			},
+		)
+	}
+}
+
+// ConcurrencyLimit runs at most the gate capacity of requests at once and rejects the
+// ones that could not get a slot in time with 429. onReject is called with
+// ratelimit.ReasonConcurrency.
+func ConcurrencyLimit(gate *ratelimit.Gate, onReject func(reason string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				release, ok := gate.Acquire(r.Context())
+				if !ok {
+					onReject(ratelimit.ReasonConcurrency)
+					tooManyRequests(w, r, time.Second, "Too many concurrent analytics requests")
+
+					return
+				}
+
+				defer release()
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// ClientKey identifies the caller of r in a Limiter: the authenticated principal, or the
+// remote IP for anonymous callers.
+func ClientKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.Name != auth.AnonymousName {
+		return "principal:" + principal.Name
+	}
+
+	return "ip:" + remoteIP(r)
+}
+
+// FailedAuthKey identifies the remote IP of r in a Limiter counting failed
+// authentications.
+func FailedAuthKey(r *http.Request) string {
+	return "auth-failure:" + remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func tooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration, message string) {
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	apierror.Write(w, r, apierror.NewAPIError(message, http.StatusTooManyRequests))
+}
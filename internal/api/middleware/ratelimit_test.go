@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/ratelimit"
+)
+
+func Test_RateLimit(t *testing.T) {
+	var rejected []string
+
+	router := chi.NewRouter()
+	router.Use(
+		Authenticate(auth.NewAnonymousAuthenticator()),
+		RateLimit(ratelimit.NewLimiter(0.001, 1, 10), func(reason string) { rejected = append(rejected, reason) }),
+	)
+	router.Get("/users", okHandler)
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:       "When the client has tokens left, should serve the request",
+			remoteAddr: "10.0.0.1:1234",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:           "When the client exhausted its bucket, should return 429 with Retry-After",
+			remoteAddr:     "10.0.0.1:5678",
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "1000",
+		},
+		{
+			name:       "When another client calls, should use its own bucket",
+			remoteAddr: "10.0.0.2:1234",
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/users", nil)
+				req.RemoteAddr = tt.remoteAddr
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, tt.wantRetryAfter, rec.Header().Get("Retry-After"))
+			},
+		)
+	}
+
+	assert.Equal(t, []string{ratelimit.ReasonRate}, rejected)
+}
+
+func Test_ThrottleFailedAuth(t *testing.T) {
+	var rejected []string
+
+	router := chi.NewRouter()
+	router.Use(ThrottleFailedAuth(ratelimit.NewLimiter(0.001, 1, 10), func(reason string) { rejected = append(rejected, reason) }))
+	router.Get(
+		"/users", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer valid" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		},
+	)
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		authorization  string
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:          "When the credentials are valid, should not count the request",
+			remoteAddr:    "10.0.0.1:1234",
+			authorization: "Bearer valid",
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:          "When the IP has failures left, should let the authentication fail",
+			remoteAddr:    "10.0.0.1:1234",
+			authorization: "Bearer guess",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:           "When the IP exhausted its failures, should return 429 with Retry-After",
+			remoteAddr:     "10.0.0.1:5678",
+			authorization:  "Bearer valid",
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "1000",
+		},
+		{
+			name:          "When another IP calls, should use its own bucket",
+			remoteAddr:    "10.0.0.2:1234",
+			authorization: "Bearer guess",
+			wantStatus:    http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/users", nil)
+				req.RemoteAddr = tt.remoteAddr
+				req.Header.Set("Authorization", tt.authorization)
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, tt.wantRetryAfter, rec.Header().Get("Retry-After"))
+			},
+		)
+	}
+
+	assert.Equal(t, []string{ratelimit.ReasonAuth}, rejected)
+}
+
+func Test_ConcurrencyLimit(t *testing.T) {
+	var rejected []string
+
+	gate := ratelimit.NewGate(1, time.Millisecond)
+	release, _ := gate.Acquire(t.Context())
+
+	router := chi.NewRouter()
+	router.Use(ConcurrencyLimit(gate, func(reason string) { rejected = append(rejected, reason) }))
+	router.Get("/actions/referrals", okHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/actions/referrals", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, apierror.ContentTypeProblem, rec.Header().Get("Content-Type"))
+	assert.Equal(t, []string{ratelimit.ReasonConcurrency}, rejected)
+
+	release()
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/actions/referrals", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLength = 128
+	requestIDBytes     = 16
+)
+
+// RequestID propagates the caller's X-Request-ID, or generates one, stores it in the
+// request context and echoes it in the response. It uses chi's context key so
+// chimiddleware.GetReqID keeps working for the rest of the stack.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requestID := RequestIDOrNew(r.Header.Get(HeaderRequestID))
+
+			w.Header().Set(HeaderRequestID, requestID)
+
+			ctx := context.WithValue(r.Context(), chimiddleware.RequestIDKey, requestID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		},
+	)
+}
+
+// RequestIDOrNew returns id when it is a valid request ID, and a new one otherwise.
+func RequestIDOrNew(id string) string {
+	if !validRequestID(id) {
+		return newRequestID()
+	}
+
+	return id
+}
+
+// validRequestID rejects empty, oversized or non printable ASCII IDs so a client
+// cannot inject arbitrary content into logs and response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, requestIDBytes)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantSame  bool
+		wantRegex string
+	}{
+		{
+			name:     "When the request carries an ID, should propagate it",
+			header:   "abc-123",
+			wantSame: true,
+		},
+		{
+			name:      "When the request has no ID, should generate one",
+			header:    "",
+			wantRegex: `^[0-9a-f]{32}$`,
+		},
+		{
+			name:      "When the ID contains control characters, should replace it",
+			header:    "abc\x01def",
+			wantRegex: `^[0-9a-f]{32}$`,
+		},
+		{
+			name:      "When the ID is too long, should replace it",
+			header:    strings.Repeat("a", maxRequestIDLength+1),
+			wantRegex: `^[0-9a-f]{32}$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var ctxID string
+
+				handler := RequestID(
+					http.HandlerFunc(
+						func(_ http.ResponseWriter, r *http.Request) {
+							ctxID = chimiddleware.GetReqID(r.Context())
+						},
+					),
+				)
+
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				if tt.header != "" {
+					req.Header.Set(HeaderRequestID, tt.header)
+				}
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				respID := recorder.Header().Get(HeaderRequestID)
+				assert.Equal(t, ctxID, respID)
+
+				if tt.wantSame {
+					assert.Equal(t, tt.header, respID)
+
+					return
+				}
+
+				assert.Regexp(t, tt.wantRegex, respID)
+			},
+		)
+	}
+}
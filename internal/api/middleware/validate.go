@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
+)
+
+// ValidateRequest rejects requests whose parameters do not match the OpenAPI operation
+// of their route with 400. Requests to routes the document does not describe are left to
+// the router. Authentication is not checked here, Authenticate does it.
+func ValidateRequest(router routers.Router) func(http.Handler) http.Handler {
+	options := &openapi3filter.Options{
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				route, pathParams, err := router.FindRoute(r)
+				if err != nil {
+					next.ServeHTTP(w, r)
+
+					return
+				}
+
+				err = openapi3filter.ValidateRequest(
+					r.Context(), &openapi3filter.RequestValidationInput{
+						Request:    normalizeFormat(r),
+						PathParams: pathParams,
+						Route:      route,
+						Options:    options,
+					},
+				)
+				if err != nil {
+					apierror.Write(w, r, apierror.NewAPIError(validationMessage(err), http.StatusBadRequest))
+
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// normalizeFormat returns r with the format parameter lowercased, as it is matched
+// case-insensitively while the enum of its schema is not.
+func normalizeFormat(r *http.Request) *http.Request {
+	query := r.URL.Query()
+
+	format := query.Get(response.FormatParam)
+	if format == strings.ToLower(format) {
+		return r
+	}
+
+	query.Set(response.FormatParam, strings.ToLower(format))
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+
+	normalized := r.WithContext(r.Context())
+	normalized.URL = &u
+
+	return normalized
+}
+
+// validationMessage names the invalid parameter and why, without the schema dump the
+// validator errors carry.
+func validationMessage(err error) string {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) || reqErr.Parameter == nil {
+		return "invalid request"
+	}
+
+	reason := reqErr.Reason
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(reqErr.Err, &schemaErr) {
+		reason = schemaErr.Reason
+	} else if reqErr.Err != nil {
+		reason = reqErr.Err.Error()
+	}
+
+	return fmt.Sprintf("invalid %s parameter: %s", reqErr.Parameter.Name, reason)
+}
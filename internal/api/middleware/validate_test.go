@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/openapi"
+)
+
+func Test_ValidateRequest(t *testing.T) {
+	doc, err := openapi.Load(t.Context())
+	require.NoError(t, err)
+
+	openAPIRouter, err := legacy.NewRouter(doc)
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(ValidateRequest(openAPIRouter))
+	router.Get("/api/v1/users", okHandler)
+	router.Get("/api/v1/users/{userId}", okHandler)
+	router.Get("/undocumented", okHandler)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantDetail string
+	}{
+		{
+			name:       "When the parameters match the operation, should serve the request",
+			target:     "/api/v1/users?page=2&pageSize=5&format=csv",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When the format is not lowercase, should match it case-insensitively",
+			target:     "/api/v1/users?format=CSV",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "When a query parameter breaks its schema, should return 400 naming it",
+			target:     "/api/v1/users?page=0",
+			wantStatus: http.StatusBadRequest,
+			wantDetail: "invalid page parameter: number must be at least 1",
+		},
+		{
+			name:       "When a query parameter is not in its enum, should return 400",
+			target:     "/api/v1/users?format=xml",
+			wantStatus: http.StatusBadRequest,
+			wantDetail: `invalid format parameter: value is not one of the allowed values ["json","ndjson","csv"]`,
+		},
+		{
+			name:       "When a path parameter has the wrong type, should return 400",
+			target:     "/api/v1/users/abc",
+			wantStatus: http.StatusBadRequest,
+			wantDetail: "invalid userId parameter: value abc: an invalid integer: invalid syntax",
+		},
+		{
+			name:       "When the route is not documented, should leave it to the router",
+			target:     "/undocumented?page=0",
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.target, nil))
+
+				require.Equal(t, tt.wantStatus, rec.Code)
+
+				if tt.wantDetail != "" {
+					var problem apierror.Problem
+					require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+					assert.Equal(t, tt.wantDetail, problem.Detail)
+				}
+			},
+		)
+	}
+}
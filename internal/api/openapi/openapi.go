@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	swaggerfiles "github.com/swaggo/files/v2"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
+	"surf_challenge/internal/logger"
+)
+
+// spec describes every route of router.New; the router tests fail when they drift apart.
+//
+//go:embed openapi.yaml
+var spec []byte
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+// assets are the files of the Swagger UI distribution swagger.html needs.
+var assets = map[string]bool{
+	"swagger-ui.css":       true,
+	"swagger-ui-bundle.js": true,
+	"favicon-32x32.png":    true,
+	"favicon-16x16.png":    true,
+}
+
+// Load parses and validates the embedded OpenAPI document.
+func Load(ctx context.Context) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("loading OpenAPI document: %w", err)
+	}
+
+	err = doc.Validate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("validating OpenAPI document: %w", err)
+	}
+
+	return doc, nil
+}
+
+type Handler interface {
+	Spec() http.HandlerFunc
+	UI() http.HandlerFunc
+	Asset() http.HandlerFunc
+}
+
+type openAPIHandler struct {
+	logger *zap.SugaredLogger
+	doc    *openapi3.T
+}
+
+// NewHandler serves doc and the bundled Swagger UI rendering it.
+func NewHandler(sugar *zap.SugaredLogger, doc *openapi3.T) Handler {
+	return &openAPIHandler{
+		logger: sugar,
+		doc:    doc,
+	}
+}
+
+func (h *openAPIHandler) Spec() http.HandlerFunc {
+	return response.Handle(
+		h.logger, "failed to render OpenAPI document", func(*http.Request) (*openapi3.T, error) {
+			return h.doc, nil
+		},
+		response.WithETag(),
+	)
+}
+
+func (h *openAPIHandler) UI() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		_, err := w.Write(swaggerUI)
+		if err != nil {
+			logger.FromContext(r.Context(), h.logger).Warnw("failed to write response", "error", err)
+		}
+	}
+}
+
+func (h *openAPIHandler) Asset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "asset")
+		if !assets[name] {
+			apierror.Write(w, r, apierror.NewAPIError("asset not found", http.StatusNotFound))
+
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		http.ServeFileFS(w, r, swaggerfiles.FS, name)
+	}
+}
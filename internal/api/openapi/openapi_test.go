@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_openAPIHandler(t *testing.T) {
+	doc, err := Load(t.Context())
+	require.NoError(t, err)
+
+	h := NewHandler(zap.NewNop().Sugar(), doc)
+
+	router := chi.NewRouter()
+	router.Get("/openapi.json", h.Spec())
+	router.Get("/docs", h.UI())
+	router.Get("/docs/{asset}", h.Asset())
+
+	tests := []struct {
+		name            string
+		target          string
+		wantStatus      int
+		wantContentType string
+	}{
+		{
+			name:            "When the document is requested, should serve it as JSON",
+			target:          "/openapi.json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+		},
+		{
+			name:            "When the docs are requested, should serve the Swagger UI page",
+			target:          "/docs",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+		},
+		{
+			name:            "When a bundled asset is requested, should serve it",
+			target:          "/docs/swagger-ui.css",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/css; charset=utf-8",
+		},
+		{
+			name:            "When another file of the distribution is requested, should return not found",
+			target:          "/docs/index.html",
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/problem+json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.target, nil))
+
+				assert.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			},
+		)
+	}
+}
@@ -0,0 +1,82 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"surf_challenge/internal/api/apierror"
+)
+
+// Validators identify the version of a resource representation for conditional
+// requests. ETag must be a quoted entity tag; a zero LastModified is not sent.
+type Validators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// Conditional wraps a handler result with its validators. Handle writes them as ETag
+// and Last-Modified headers and answers 304 Not Modified, without encoding Value, when
+// the request If-None-Match or If-Modified-Since header shows the client is up to date.
+type Conditional[T any] struct {
+	Value      T
+	Validators Validators
+}
+
+func (c Conditional[T]) conditional() (any, Validators) {
+	return c.Value, c.Validators
+}
+
+type conditionalResult interface {
+	conditional() (any, Validators)
+}
+
+func (v Validators) write(w http.ResponseWriter) {
+	if v.ETag != "" {
+		w.Header().Set("ETag", v.ETag)
+	}
+
+	if !v.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when the former is absent,
+// as RFC 9110 13.2.2 orders them.
+func (v Validators) notModified(r *http.Request) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return v.ETag != "" && etagMatches(ifNoneMatch, v.ETag)
+	}
+
+	if v.LastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !v.LastModified.Truncate(time.Second).After(since)
+}
+
+// CheckIfMatch implements optimistic concurrency for update endpoints: given the ETag of
+// the current version of the resource, it returns a 412 Precondition Failed error when
+// the request If-Match header names another version. Requests without If-Match pass.
+func CheckIfMatch(r *http.Request, etag string) error {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		// If-Match uses the strong comparison: weak tags never match.
+		if candidate == "*" || (candidate == etag && !strings.HasPrefix(etag, "W/")) {
+			return nil
+		}
+	}
+
+	return apierror.NewAPIError("resource was modified, fetch it again before updating", http.StatusPreconditionFailed)
+}
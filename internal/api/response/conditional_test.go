@@ -0,0 +1,66 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"surf_challenge/internal/api/apierror"
+)
+
+func Test_CheckIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  string
+		etag     string
+		wantCode int
+	}{
+		{
+			name: "When If-Match is absent, should pass",
+			etag: `"1-2"`,
+		},
+		{
+			name:    "When If-Match names the current version, should pass",
+			ifMatch: `"1-1", "1-2"`,
+			etag:    `"1-2"`,
+		},
+		{
+			name:    "When If-Match is a wildcard, should pass",
+			ifMatch: "*",
+			etag:    `"1-2"`,
+		},
+		{
+			name:     "When If-Match names a previous version, should fail the precondition",
+			ifMatch:  `"1-1"`,
+			etag:     `"1-2"`,
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "When If-Match is a weak tag, should fail the strong comparison",
+			ifMatch:  `W/"1-2"`,
+			etag:     `"1-2"`,
+			wantCode: http.StatusPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPut, "/", nil)
+				if tt.ifMatch != "" {
+					req.Header.Set("If-Match", tt.ifMatch)
+				}
+
+				err := CheckIfMatch(req, tt.etag)
+				if tt.wantCode == 0 {
+					assert.NoError(t, err)
+
+					return
+				}
+
+				assert.Equal(t, tt.wantCode, apierror.MapErrors(err).Code)
+			},
+		)
+	}
+}
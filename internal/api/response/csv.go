@@ -0,0 +1,109 @@
+package response
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const ContentTypeCSV = "text/csv"
+
+// CSVEncoder writes records to a stream as CSV, starting with a header row
+// and flushing regularly.
+type CSVEncoder struct {
+	w       io.Writer
+	csv     *csv.Writer
+	header  []string
+	pending int
+}
+
+// NewCSVEncoder returns an encoder writing header before the first record. Nothing is
+// written until Encode or Flush is called, so a stream can still fail cleanly.
+func NewCSVEncoder(w io.Writer, header []string) *CSVEncoder {
+	return &CSVEncoder{
+		w:      w,
+		csv:    csv.NewWriter(w),
+		header: header,
+	}
+}
+
+// Encode writes a record, escaping the cells a spreadsheet would run as formulas.
+func (e *CSVEncoder) Encode(record []string) error {
+	err := e.writeHeader()
+	if err != nil {
+		return err
+	}
+
+	err = e.csv.Write(EscapeFormulas(record))
+	if err != nil {
+		return err
+	}
+
+	e.pending++
+	if e.pending < streamFlushEvery {
+		return nil
+	}
+
+	return e.Flush()
+}
+
+// Flush sends the buffered records, and the header of an empty stream, when the
+// underlying writer supports it.
+func (e *CSVEncoder) Flush() error {
+	err := e.writeHeader()
+	if err != nil {
+		return err
+	}
+
+	e.pending = 0
+
+	e.csv.Flush()
+
+	err = e.csv.Error()
+	if err != nil {
+		return err
+	}
+
+	if f, ok := e.w.(flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+func (e *CSVEncoder) writeHeader() error {
+	if e.header == nil {
+		return nil
+	}
+
+	header := e.header
+	e.header = nil
+
+	return e.csv.Write(header)
+}
+
+// EscapeFormulas returns a copy of record where the cells starting like a spreadsheet
+// formula are prefixed with a quote, as OWASP recommends against CSV injection. Every
+// CSV writer of the module runs its rows through it.
+func EscapeFormulas(record []string) []string {
+	escaped := make([]string, len(record))
+	for i, cell := range record {
+		escaped[i] = escapeFormula(cell)
+	}
+
+	return escaped
+}
+
+// escapeFormula escapes a single cell. Numbers, negative ones included, are kept.
+func escapeFormula(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return cell
+	}
+
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+
+	return "'" + cell
+}
@@ -0,0 +1,54 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CSVEncoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		records  [][]string
+		wantBody string
+	}{
+		{
+			name:     "should write the header before the records and quote fields when needed",
+			records:  [][]string{{"1", "Ada"}, {"2", "Lovelace, Ada"}},
+			wantBody: "id,name\n1,Ada\n2,\"Lovelace, Ada\"\n",
+		},
+		{
+			name: "should escape the cells starting like a formula",
+			records: [][]string{
+				{"1", "=HYPERLINK(\"http://evil\")"}, {"2", "+1+1"}, {"3", "-2+3"}, {"4", "@SUM(A1)"}, {"-5", "-0.5"},
+			},
+			wantBody: "id,name\n1,\"'=HYPERLINK(\"\"http://evil\"\")\"\n2,'+1+1\n3,'-2+3\n4,'@SUM(A1)\n-5,-0.5\n",
+		},
+		{
+			name:     "should write the header of an empty stream",
+			wantBody: "id,name\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				recorder := httptest.NewRecorder()
+				sw := &streamWriter{w: recorder, contentType: ContentTypeCSV, status: http.StatusOK}
+				enc := NewCSVEncoder(sw, []string{"id", "name"})
+
+				for _, record := range tt.records {
+					require.NoError(t, enc.Encode(record))
+				}
+
+				require.NoError(t, enc.Flush())
+
+				assert.Equal(t, tt.wantBody, recorder.Body.String())
+				assert.Equal(t, ContentTypeCSV, recorder.Header().Get("Content-Type"))
+				assert.True(t, recorder.Flushed)
+			},
+		)
+	}
+}
@@ -0,0 +1,38 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// WithETag tags successful responses with a strong ETag computed from the encoded body
+// and answers 304 Not Modified when it matches the If-None-Match request header. The
+// Compress middleware makes the tag of compressed responses distinct.
+func WithETag() Option {
+	return func(o *options) {
+		o.etag = true
+	}
+}
+
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches implements the weak comparison If-None-Match requires (RFC 9110 13.1.2).
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,211 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/logger"
+)
+
+// Encoder serialises a handler result for a given content type.
+type Encoder func(w io.Writer, v any) error
+
+type options struct {
+	status   int
+	encoders map[string]Encoder
+	streams  map[string]StreamFunc
+	offers   []string
+	etag     bool
+}
+
+type Option func(*options)
+
+// WithStatus overrides the status code written on success, 200 by default.
+func WithStatus(status int) Option {
+	return func(o *options) {
+		o.status = status
+	}
+}
+
+// WithEncoder registers an additional representation for the handler result.
+// JSON is always offered first.
+func WithEncoder(contentType string, encoder Encoder) Option {
+	return func(o *options) {
+		if _, ok := o.encoders[contentType]; !ok {
+			o.offers = append(o.offers, contentType)
+		}
+
+		o.encoders[contentType] = encoder
+		delete(o.streams, contentType)
+	}
+}
+
+// Handle adapts fn into an http.HandlerFunc. Errors returned by fn are logged with
+// errMsg and rendered as problem details. Results are negotiated against the Accept
+// header, or the format query parameter when set, and encoded into a buffer before
+// anything is written, so an encoding failure still produces a clean error response.
+func Handle[T any](
+	baseLogger *zap.SugaredLogger,
+	errMsg string,
+	fn func(*http.Request) (T, error),
+	opts ...Option,
+) http.HandlerFunc {
+	o := &options{
+		status:   http.StatusOK,
+		encoders: map[string]Encoder{ContentTypeJSON: encodeJSON},
+		streams:  map[string]StreamFunc{},
+		offers:   []string{ContentTypeJSON},
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	notAcceptable := apierror.NewAPIError(
+		"supported media types: "+strings.Join(o.offers, ", "),
+		http.StatusNotAcceptable,
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), baseLogger)
+
+		accept, err := requestedAccept(r)
+		if err != nil {
+			apierror.Write(w, r, err)
+
+			return
+		}
+
+		contentType, ok := negotiate(accept, o.offers)
+		if !ok {
+			apierror.Write(w, r, notAcceptable)
+
+			return
+		}
+
+		w.Header().Add("Vary", "Accept")
+
+		if stream, ok := o.streams[contentType]; ok {
+			serveStream(log, w, r, errMsg, o.status, contentType, stream)
+
+			return
+		}
+
+		resp, err := fn(r)
+		if err != nil {
+			log.Errorw(errMsg, "error", err)
+			apierror.Write(w, r, err)
+
+			return
+		}
+
+		var (
+			body       any = resp
+			validators Validators
+		)
+
+		if c, ok := body.(conditionalResult); ok {
+			body, validators = c.conditional()
+
+			if validators.notModified(r) {
+				validators.write(w)
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+		}
+
+		var buf bytes.Buffer
+
+		err = o.encoders[contentType](&buf, body)
+		if err != nil {
+			log.Errorw("failed to encode response", "error", err)
+			apierror.Write(w, r, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		validators.write(w)
+
+		if o.etag {
+			etag := bodyETag(buf.Bytes())
+			w.Header().Set("ETag", etag)
+
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+		}
+
+		w.WriteHeader(o.status)
+
+		_, err = buf.WriteTo(w)
+		if err != nil {
+			log.Warnw("failed to write response", "error", err)
+		}
+	}
+}
+
+func serveStream(
+	log *zap.SugaredLogger,
+	w http.ResponseWriter,
+	r *http.Request,
+	errMsg string,
+	status int,
+	contentType string,
+	stream StreamFunc,
+) {
+	sw := &streamWriter{w: w, contentType: contentType, status: status}
+
+	err := stream(r, sw)
+	if err != nil {
+		log.Errorw(errMsg, "error", err, "streamStarted", sw.started)
+
+		if !sw.started {
+			apierror.Write(w, r, err)
+		}
+
+		return
+	}
+
+	if !sw.started {
+		// Empty streams still need their headers.
+		_, _ = sw.Write(nil)
+	}
+}
+
+// WriteJSON encodes v into a buffer and writes it with status. It is meant for handlers
+// that need to send a body with a non-2xx status, which Handle reserves for problems.
+func WriteJSON(baseLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, status int, v any) {
+	log := logger.FromContext(r.Context(), baseLogger)
+
+	var buf bytes.Buffer
+
+	err := encodeJSON(&buf, v)
+	if err != nil {
+		log.Errorw("failed to encode response", "error", err)
+		apierror.Write(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(status)
+
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		log.Warnw("failed to write response", "error", err)
+	}
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
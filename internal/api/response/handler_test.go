@@ -0,0 +1,221 @@
+package response
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/api/apierror"
+)
+
+type payload struct {
+	Value float64 `json:"value"`
+}
+
+func Test_Handle(t *testing.T) {
+	tests := []struct {
+		name            string
+		fn              func(*http.Request) (*payload, error)
+		opts            []Option
+		accept          string
+		format          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "When handler succeeds, should encode result as JSON",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeJSON,
+			wantBody:        `{"value":1}` + "\n",
+		},
+		{
+			name: "When status option is set, should write it on success",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			opts:            []Option{WithStatus(http.StatusCreated)},
+			wantStatus:      http.StatusCreated,
+			wantContentType: ContentTypeJSON,
+			wantBody:        `{"value":1}` + "\n",
+		},
+		{
+			name: "When handler returns an error, should render problem details",
+			fn: func(*http.Request) (*payload, error) {
+				return nil, apierror.NewAPIError("invalid value", http.StatusBadRequest)
+			},
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: apierror.ContentTypeProblem,
+			wantBody:        "",
+		},
+		{
+			name: "When encoding fails, should render a single internal server error",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: math.Inf(1)}, nil
+			},
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: apierror.ContentTypeProblem,
+			wantBody:        "",
+		},
+		{
+			name: "When Accept does not match any representation, should return not acceptable",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			accept:          "text/html",
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: apierror.ContentTypeProblem,
+			wantBody:        "",
+		},
+		{
+			name: "When Accept prefers a registered encoder, should use it",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			opts: []Option{
+				WithEncoder(
+					"text/plain", func(w io.Writer, _ any) error {
+						_, err := io.WriteString(w, "one")
+
+						return err
+					},
+				),
+			},
+			accept:          "application/json;q=0.5, text/*",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain",
+			wantBody:        "one",
+		},
+		{
+			name: "When Accept is a wildcard, should default to JSON",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			accept:          "*/*",
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeJSON,
+			wantBody:        `{"value":1}` + "\n",
+		},
+		{
+			name: "When format is set, should override the Accept header",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			opts: []Option{
+				WithEncoder(
+					ContentTypeCSV, func(w io.Writer, _ any) error {
+						_, err := io.WriteString(w, "value\n1\n")
+
+						return err
+					},
+				),
+			},
+			accept:          ContentTypeJSON,
+			format:          "CSV",
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeCSV,
+			wantBody:        "value\n1\n",
+		},
+		{
+			name: "When format names a representation not offered, should return not acceptable",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			format:          "ndjson",
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: apierror.ContentTypeProblem,
+		},
+		{
+			name: "When format is unknown, should return bad request",
+			fn: func(*http.Request) (*payload, error) {
+				return &payload{Value: 1}, nil
+			},
+			format:          "xml",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: apierror.ContentTypeProblem,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				target := "/"
+				if tt.format != "" {
+					target += "?" + FormatParam + "=" + tt.format
+				}
+
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, target, nil)
+				if tt.accept != "" {
+					req.Header.Set("Accept", tt.accept)
+				}
+
+				recorder := httptest.NewRecorder()
+				Handle(zap.NewNop().Sugar(), "failed", tt.fn, tt.opts...).ServeHTTP(recorder, req)
+
+				require.Equal(t, tt.wantStatus, recorder.Code)
+				assert.Equal(t, tt.wantContentType, recorder.Header().Get("Content-Type"))
+
+				if tt.wantBody != "" {
+					assert.Equal(t, tt.wantBody, recorder.Body.String())
+				}
+			},
+		)
+	}
+}
+
+func Test_Handle_ETag(t *testing.T) {
+	etag := bodyETag([]byte(`{"value":1}` + "\n"))
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "When no validator is sent, should return the body with its ETag",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"value":1}` + "\n",
+		},
+		{
+			name:        "When If-None-Match matches, should return not modified without body",
+			ifNoneMatch: `"other", W/` + etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "When If-None-Match is stale, should return the body",
+			ifNoneMatch: `"other"`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"value":1}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				if tt.ifNoneMatch != "" {
+					req.Header.Set("If-None-Match", tt.ifNoneMatch)
+				}
+
+				fn := func(*http.Request) (*payload, error) {
+					return &payload{Value: 1}, nil
+				}
+
+				recorder := httptest.NewRecorder()
+				Handle(zap.NewNop().Sugar(), "failed", fn, WithETag()).ServeHTTP(recorder, req)
+
+				assert.Equal(t, tt.wantStatus, recorder.Code)
+				assert.Equal(t, etag, recorder.Header().Get("ETag"))
+				assert.Equal(t, tt.wantBody, recorder.Body.String())
+			},
+		)
+	}
+}
@@ -0,0 +1,139 @@
+package response
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"surf_challenge/internal/api/apierror"
+)
+
+const (
+	ContentTypeJSON = "application/json"
+	anyMediaType    = "*/*"
+)
+
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiate returns the offered content type that best matches the Accept header,
+// following RFC 9110 quality values. An empty Accept header accepts anything.
+// It returns false when none of the offers is acceptable.
+func negotiate(accept string, offers []string) (string, bool) {
+	if len(offers) == 0 {
+		return "", false
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	ranges := parseAccept(accept)
+
+	best, bestQuality, bestSpecificity := "", 0.0, -1
+
+	for _, offer := range offers {
+		for _, mr := range ranges {
+			specificity, ok := matchMediaRange(mr.mediaType, offer)
+			if !ok || mr.quality <= 0 {
+				continue
+			}
+
+			if mr.quality > bestQuality || (mr.quality == bestQuality && specificity > bestSpecificity) {
+				best, bestQuality, bestSpecificity = offer, mr.quality, specificity
+			}
+		}
+	}
+
+	return best, best != ""
+}
+
+func parseAccept(accept string) []mediaRange {
+	parts := strings.Split(accept, ",")
+	ranges := make([]mediaRange, 0, len(parts))
+
+	for _, part := range parts {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	// Stable so that, on equal quality, the client's order is kept.
+	sort.SliceStable(
+		ranges, func(i, j int) bool {
+			return ranges[i].quality > ranges[j].quality
+		},
+	)
+
+	return ranges
+}
+
+// matchMediaRange reports whether offer satisfies the media range and how specific
+// the match is: 2 for an exact type, 1 for type/*, 0 for */*.
+func matchMediaRange(mediaRange, offer string) (int, bool) {
+	if mediaRange == anyMediaType {
+		return 0, true
+	}
+
+	if mediaRange == offer {
+		return 2, true
+	}
+
+	rangeType, rangeSubtype, ok := strings.Cut(mediaRange, "/")
+	if !ok || rangeSubtype != "*" {
+		return 0, false
+	}
+
+	offerType, _, _ := strings.Cut(offer, "/")
+
+	return 1, rangeType == offerType
+}
+
+// FormatParam is the query parameter overriding the Accept header, for clients such as
+// browsers and spreadsheets that cannot set it.
+const FormatParam = "format"
+
+// formats maps the values of FormatParam to the content type they request.
+var formats = map[string]string{
+	"json":   ContentTypeJSON,
+	"ndjson": ContentTypeNDJSON,
+	"csv":    ContentTypeCSV,
+}
+
+// requestedAccept returns the media ranges the client accepts: the content type named by
+// FormatParam when present, the Accept header otherwise.
+func requestedAccept(r *http.Request) (string, error) {
+	format := r.URL.Query().Get(FormatParam)
+	if format == "" {
+		return r.Header.Get("Accept"), nil
+	}
+
+	contentType, ok := formats[strings.ToLower(format)]
+	if !ok {
+		return "", apierror.NewAPIError(
+			fmt.Sprintf("invalid %s parameter %q, supported: csv, json, ndjson", FormatParam, format),
+			http.StatusBadRequest,
+		)
+	}
+
+	return contentType, nil
+}
@@ -0,0 +1,110 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+const (
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// streamFlushEvery bounds how many records a client waits for: flushing each line
+	// would defeat compression and syscall batching.
+	streamFlushEvery = 64
+)
+
+// StreamFunc writes the representation of the request to w as it is produced.
+type StreamFunc func(r *http.Request, w io.Writer) error
+
+// WithStream registers a representation written incrementally by fn rather than
+// encoded from the handler result, so clients start consuming it before it is complete.
+// Errors returned before fn writes anything are rendered as problem details; once the
+// body started they can only be logged and the response ends early.
+func WithStream(contentType string, fn StreamFunc) Option {
+	return func(o *options) {
+		if _, ok := o.encoders[contentType]; !ok {
+			o.offers = append(o.offers, contentType)
+		}
+
+		o.encoders[contentType] = nil
+		o.streams[contentType] = fn
+	}
+}
+
+// streamWriter sends the headers on the first write, leaving the handler a chance to
+// fail with a proper status until then.
+type streamWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	status      int
+	started     bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.w.Header().Set("Content-Type", s.contentType)
+		s.w.WriteHeader(s.status)
+	}
+
+	return s.w.Write(p)
+}
+
+// Flush pushes what was written so far to the client, through any compression.
+func (s *streamWriter) Flush() error {
+	if !s.started {
+		return nil
+	}
+
+	err := http.NewResponseController(s.w).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+
+	return err
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// NDJSONEncoder writes one JSON document per line to a stream, flushing regularly.
+type NDJSONEncoder struct {
+	w       io.Writer
+	enc     *json.Encoder
+	pending int
+}
+
+func NewNDJSONEncoder(w io.Writer) *NDJSONEncoder {
+	return &NDJSONEncoder{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (e *NDJSONEncoder) Encode(v any) error {
+	err := e.enc.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	e.pending++
+	if e.pending < streamFlushEvery {
+		return nil
+	}
+
+	return e.Flush()
+}
+
+// Flush sends the buffered records when the underlying writer supports it.
+func (e *NDJSONEncoder) Flush() error {
+	e.pending = 0
+
+	if f, ok := e.w.(flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
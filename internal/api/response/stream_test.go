@@ -0,0 +1,119 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/api/apierror"
+)
+
+func Test_Handle_WithStream(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		stream          StreamFunc
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:   "When the stream is negotiated, should write every record on its own line",
+			accept: ContentTypeNDJSON,
+			stream: func(_ *http.Request, w io.Writer) error {
+				enc := NewNDJSONEncoder(w)
+				for i := range streamFlushEvery + 1 {
+					if err := enc.Encode(&payload{Value: float64(i)}); err != nil {
+						return err
+					}
+				}
+
+				return enc.Flush()
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeNDJSON,
+		},
+		{
+			name:   "When JSON is negotiated, should not call the stream",
+			accept: ContentTypeJSON,
+			stream: func(*http.Request, io.Writer) error {
+				return errors.New("unexpected call")
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeJSON,
+			wantBody:        `{"value":1}` + "\n",
+		},
+		{
+			name:   "When the stream fails before writing, should render problem details",
+			accept: ContentTypeNDJSON,
+			stream: func(*http.Request, io.Writer) error {
+				return apierror.NewAPIError("invalid value", http.StatusBadRequest)
+			},
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: apierror.ContentTypeProblem,
+		},
+		{
+			name:   "When the stream fails after writing, should end the body early",
+			accept: ContentTypeNDJSON,
+			stream: func(_ *http.Request, w io.Writer) error {
+				_ = NewNDJSONEncoder(w).Encode(&payload{Value: 1})
+
+				return assert.AnError
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeNDJSON,
+			wantBody:        `{"value":1}` + "\n",
+		},
+		{
+			name:   "When the stream is empty, should still send its headers",
+			accept: ContentTypeNDJSON,
+			stream: func(*http.Request, io.Writer) error {
+				return nil
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeNDJSON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				req.Header.Set("Accept", tt.accept)
+
+				fn := func(*http.Request) (*payload, error) {
+					return &payload{Value: 1}, nil
+				}
+
+				recorder := httptest.NewRecorder()
+				Handle(zap.NewNop().Sugar(), "failed", fn, WithStream(ContentTypeNDJSON, tt.stream)).ServeHTTP(recorder, req)
+
+				assert.Equal(t, tt.wantStatus, recorder.Code)
+				assert.Equal(t, tt.wantContentType, recorder.Header().Get("Content-Type"))
+
+				if tt.wantBody != "" {
+					assert.Equal(t, tt.wantBody, recorder.Body.String())
+				}
+			},
+		)
+	}
+}
+
+func Test_NDJSONEncoder_flushes(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	sw := &streamWriter{w: recorder, contentType: ContentTypeNDJSON, status: http.StatusOK}
+	enc := NewNDJSONEncoder(sw)
+
+	for range streamFlushEvery - 1 {
+		assert.NoError(t, enc.Encode(&payload{}))
+	}
+
+	assert.False(t, recorder.Flushed)
+
+	assert.NoError(t, enc.Encode(&payload{}))
+	assert.True(t, recorder.Flushed, "should flush every streamFlushEvery records")
+}
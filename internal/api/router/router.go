@@ -7,32 +7,147 @@ import (
 	"go.uber.org/zap"
 
 	"surf_challenge/internal/api/action"
+	"surf_challenge/internal/api/graphql"
+	"surf_challenge/internal/api/health"
+	"surf_challenge/internal/api/middleware"
+	"surf_challenge/internal/api/openapi"
 	"surf_challenge/internal/api/user"
+	"surf_challenge/internal/api/webhook"
+	"surf_challenge/internal/auth"
 	"surf_challenge/internal/container"
 )
 
 func New(sugar *zap.SugaredLogger, dependencies *container.AppContainer) http.Handler {
 	router := chi.NewRouter()
+	router.Use(middleware.RequestID, middleware.Logger(sugar), dependencies.Tracing.Middleware)
+
+	if dependencies.Config.Compression.Enabled {
+		router.Use(middleware.Compress(dependencies.Config.Compression.Level))
+	}
+
+	if dependencies.Config.Features.Metrics {
+		router.Use(dependencies.Metrics.Middleware)
+		router.Method(http.MethodGet, "/metrics", dependencies.Metrics.Handler())
+	}
 
 	usersHandler := user.NewHandler(sugar, dependencies.UserService)
 	actionsHandler := action.NewHandler(sugar, dependencies.ActionService)
+	healthHandler := health.NewHandler(sugar, dependencies.Health)
+
+	router.Get("/healthz", healthHandler.Liveness())
+	router.Get("/readyz", healthHandler.Readiness())
+	router.Get("/version", healthHandler.Version())
+
+	if dependencies.Config.Features.Docs {
+		openAPIHandler := openapi.NewHandler(sugar, dependencies.OpenAPI)
+
+		router.Get("/openapi.json", openAPIHandler.Spec())
+		router.Get("/docs", openAPIHandler.UI())
+		router.Get("/docs/{asset}", openAPIHandler.Asset())
+	}
+
+	if dependencies.Config.Features.GraphQL {
+		graphqlHandler := graphql.NewHandler(
+			sugar, dependencies.UserService, dependencies.ActionService, dependencies.Config.Features.Analytics,
+		)
+
+		// Guarded like /api/v1/users, the resolvers checking user access and the analytics
+		// scope per field.
+		router.Group(
+			func(r chi.Router) {
+				if dependencies.Config.RateLimit.Enabled {
+					r.Use(middleware.ThrottleFailedAuth(dependencies.RateLimiter, dependencies.Metrics.ObserveRateLimited))
+				}
+
+				r.Use(middleware.Authenticate(dependencies.Authenticator))
+
+				if dependencies.Config.RateLimit.Enabled {
+					r.Use(middleware.RateLimit(dependencies.RateLimiter, dependencies.Metrics.ObserveRateLimited))
+				}
+
+				r.Use(middleware.RequireScope(auth.ScopeUsersRead))
+
+				// Queries may select the referral fields, computed like /actions/referrals.
+				if dependencies.Config.RateLimit.Enabled && dependencies.Config.Features.Analytics {
+					r.Use(middleware.ConcurrencyLimit(dependencies.AnalyticsGate, dependencies.Metrics.ObserveRateLimited))
+				}
+
+				r.Get("/graphql", graphqlHandler.Query())
+				r.Post("/graphql", graphqlHandler.Query())
+			},
+		)
+	}
 
 	router.Route(
 		"/api/v1", func(r chi.Router) {
+			if dependencies.Config.RateLimit.Enabled {
+				r.Use(middleware.ThrottleFailedAuth(dependencies.RateLimiter, dependencies.Metrics.ObserveRateLimited))
+			}
+
+			r.Use(middleware.Authenticate(dependencies.Authenticator))
+
+			if dependencies.Config.RateLimit.Enabled {
+				r.Use(middleware.RateLimit(dependencies.RateLimiter, dependencies.Metrics.ObserveRateLimited))
+			}
+
+			if dependencies.Config.Features.RequestValidation {
+				r.Use(middleware.ValidateRequest(dependencies.OpenAPIRouter))
+			}
+
 			r.Route(
 				"/users", func(r chi.Router) {
-					r.Get("/", usersHandler.GetUsers())
-					r.Get("/{userId}", usersHandler.GetUserByID())
-					r.Get("/{userId}/actions/count", usersHandler.GetUserActionCount())
-				},
-			)
+					r.Use(middleware.RequireScope(auth.ScopeUsersRead))
 
-			r.Route(
-				"/actions", func(r chi.Router) {
-					r.Get("/next-probability", actionsHandler.GetNextActionProbability())
-					r.Get("/referrals", actionsHandler.GetReferralForUser())
+					users := r.With(middleware.RequireUserAccess("userId"))
+					users.Get("/", usersHandler.GetUsers())
+					users.Get("/{userId}", usersHandler.GetUserByID())
+					users.Get("/{userId}/actions/count", usersHandler.GetUserActionCount())
 				},
 			)
+
+			if dependencies.Config.Features.Analytics {
+				r.Route(
+					"/actions", func(r chi.Router) {
+						r.Use(middleware.RequireScope(auth.ScopeAnalyticsRead))
+
+						// Streams are long-lived, they would hold an analytics slot for good.
+						if dependencies.Config.Stream.Enabled {
+							streamHandler := action.NewStreamHandler(
+								sugar, dependencies.Feed, dependencies.Config.Stream.Heartbeat,
+							)
+							r.Get("/stream", streamHandler.StreamActions())
+						}
+
+						gated := r
+						if dependencies.Config.RateLimit.Enabled {
+							gated = r.With(
+								middleware.ConcurrencyLimit(dependencies.AnalyticsGate, dependencies.Metrics.ObserveRateLimited),
+							)
+						}
+
+						gated.Get("/next-probability", actionsHandler.GetNextActionProbability())
+						gated.Get("/referrals", actionsHandler.GetReferralForUser())
+					},
+				)
+			}
+
+			if dependencies.Config.Webhooks.Enabled {
+				webhooksHandler := webhook.NewHandler(sugar, dependencies.WebhookService)
+
+				r.Route(
+					"/admin/webhooks", func(r chi.Router) {
+						r.Use(middleware.RequireScope(auth.ScopeAdmin))
+
+						r.Post("/", webhooksHandler.CreateWebhook())
+						r.Get("/", webhooksHandler.GetWebhooks())
+						r.Get("/dead-letters", webhooksHandler.GetDeadLetters())
+						r.Post("/dead-letters/{deliveryId}/retry", webhooksHandler.RetryDeadLetter())
+						r.Get("/{webhookId}", webhooksHandler.GetWebhook())
+						r.Delete("/{webhookId}", webhooksHandler.DeleteWebhook())
+						r.Get("/{webhookId}/deliveries", webhooksHandler.GetDeliveries())
+					},
+				)
+			}
 		},
 	)
 
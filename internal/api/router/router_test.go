@@ -0,0 +1,300 @@
+package router
+
+import (
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action"
+	actiondto "surf_challenge/internal/api/action/dto"
+	"surf_challenge/internal/api/apierror"
+	healthdto "surf_challenge/internal/api/health/dto"
+	"surf_challenge/internal/api/openapi"
+	userdto "surf_challenge/internal/api/user/dto"
+	webhookdto "surf_challenge/internal/api/webhook/dto"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/config"
+	"surf_challenge/internal/container"
+	"surf_challenge/internal/feed"
+	"surf_challenge/internal/health"
+	"surf_challenge/internal/metrics"
+	"surf_challenge/internal/ratelimit"
+	"surf_challenge/internal/tracing"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/user/domain"
+	"surf_challenge/internal/webhook"
+	webhookdomain "surf_challenge/internal/webhook/domain"
+)
+
+// newTestDependencies enables every feature, so that every route is registered, over
+// services returning fixed data.
+func newTestDependencies(t *testing.T) *container.AppContainer {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+
+	doc, err := openapi.Load(t.Context())
+	require.NoError(t, err)
+
+	openAPIRouter, err := legacy.NewRouter(doc)
+	require.NoError(t, err)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	userService := user.NewMockService(ctrl)
+	userService.EXPECT().QueryUsers(gomock.Any(), gomock.Any()).
+		Return([]*domain.User{{ID: 7, Name: "Jane", CreatedAt: createdAt, Version: 1}}, &domain.Results{TotalItems: 1}, nil).
+		AnyTimes()
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(7)).
+		Return(&domain.User{ID: 7, Name: "Jane", CreatedAt: createdAt, Version: 1}, nil).
+		AnyTimes()
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(8)).Return(nil, user.ErrNotFound).AnyTimes()
+	userService.EXPECT().GetUserActionCount(gomock.Any(), int64(7)).Return(3, nil).AnyTimes()
+	userService.EXPECT().GetUsersByIDs(gomock.Any(), []int64{7}).
+		Return([]*domain.User{{ID: 7, Name: "Jane", CreatedAt: createdAt, Version: 1}}, nil).
+		AnyTimes()
+
+	actionService := action.NewMockService(ctrl)
+	actionService.EXPECT().GetNextActionProbability(gomock.Any(), gomock.Any()).
+		Return(map[string]string{"ADD_CONTACT": "0.70", "EDIT_CONTACT": "0.30"}, nil).
+		AnyTimes()
+	actionService.EXPECT().GetUsersReferrals(gomock.Any()).Return(map[int]int{1: 2, 3: 0}, nil).AnyTimes()
+
+	webhookService := webhook.NewMockService(ctrl)
+	webhookService.EXPECT().ListEndpoints(gomock.Any()).
+		Return(
+			[]*webhookdomain.Endpoint{
+				{
+					ID: "wh_1", URL: "https://example.com/hooks", Events: []string{webhookdomain.EventActionCreated},
+					ActionTypes: []string{"REFER_USER"}, Secret: "whsec_1", CreatedAt: createdAt,
+				},
+			}, nil,
+		).
+		AnyTimes()
+	webhookService.EXPECT().GetEndpoint(gomock.Any(), "wh_2").Return(nil, webhook.ErrNotFound).AnyTimes()
+	webhookService.EXPECT().ListDeadLetters(gomock.Any()).
+		Return(
+			[]*webhookdomain.Delivery{
+				{
+					ID: "dlv_1", EndpointID: "wh_1", Status: webhookdomain.DeliveryFailed, CreatedAt: createdAt,
+					Event: &webhookdomain.Event{ID: "evt_1", Type: webhookdomain.EventActionCreated},
+					Attempts: []webhookdomain.Attempt{
+						{Number: 1, At: createdAt, Duration: time.Second, StatusCode: http.StatusBadGateway, Error: "unexpected response status 502"},
+					},
+				},
+			}, nil,
+		).
+		AnyTimes()
+
+	count := func(context.Context) (int, error) {
+		return 1, nil
+	}
+
+	cfg := config.Default()
+	cfg.Webhooks.Enabled = true
+
+	checker := health.NewChecker(health.Dataset{Name: "users", Count: count})
+	checker.Load(t.Context())
+
+	return &container.AppContainer{
+		Config:         cfg,
+		UserService:    userService,
+		ActionService:  actionService,
+		Health:         checker,
+		Metrics:        metrics.New(),
+		Tracing:        tracing.NewNoop(),
+		Authenticator:  auth.NewAnonymousAuthenticator(),
+		RateLimiter:    ratelimit.NewLimiter(1000, 1000, 10),
+		AnalyticsGate:  ratelimit.NewGate(4, time.Second),
+		OpenAPI:        doc,
+		OpenAPIRouter:  openAPIRouter,
+		Feed:           feed.New(10),
+		WebhookService: webhookService,
+	}
+}
+
+func Test_New_routesMatchOpenAPI(t *testing.T) {
+	deps := newTestDependencies(t)
+
+	routes, ok := New(zap.NewNop().Sugar(), deps).(chi.Routes)
+	require.True(t, ok)
+
+	var served []string
+
+	err := chi.Walk(
+		routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			served = append(served, method+" "+strings.TrimSuffix(route, "/"))
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	var documented []string
+
+	for path, item := range deps.OpenAPI.Paths.Map() {
+		for method := range item.Operations() {
+			documented = append(documented, method+" "+path)
+		}
+	}
+
+	assert.ElementsMatch(t, documented, served, "every route of New should be documented in openapi.yaml")
+}
+
+func Test_OpenAPI_schemasMatchDTOs(t *testing.T) {
+	doc, err := openapi.Load(t.Context())
+	require.NoError(t, err)
+
+	tests := []struct {
+		schema string
+		dto    any
+	}{
+		{schema: "User", dto: userdto.User{}},
+		{schema: "Pagination", dto: userdto.Pagination{}},
+		{schema: "UsersResponse", dto: userdto.UsersResponse{}},
+		{schema: "ActionsCount", dto: userdto.ActionsCount{}},
+		{schema: "ActionProbability", dto: actiondto.ActionProbability{}},
+		{schema: "Referral", dto: actiondto.Referral{}},
+		{schema: "Action", dto: actiondto.Action{}},
+		{schema: "Health", dto: healthdto.Health{}},
+		{schema: "Dataset", dto: healthdto.Dataset{}},
+		{schema: "Readiness", dto: healthdto.Readiness{}},
+		{schema: "Version", dto: healthdto.Version{}},
+		{schema: "CreateWebhookRequest", dto: webhookdto.CreateWebhookRequest{}},
+		{schema: "Webhook", dto: webhookdto.Webhook{}},
+		{schema: "WebhooksResponse", dto: webhookdto.WebhooksResponse{}},
+		{schema: "Delivery", dto: webhookdto.Delivery{}},
+		{schema: "DeliveryAttempt", dto: webhookdto.Attempt{}},
+		{schema: "DeliveriesResponse", dto: webhookdto.DeliveriesResponse{}},
+		{schema: "Problem", dto: apierror.Problem{}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.schema, func(t *testing.T) {
+				ref, ok := doc.Components.Schemas[tt.schema]
+				require.True(t, ok, "schema should be documented")
+
+				properties, required := jsonFields(reflect.TypeOf(tt.dto))
+
+				assert.ElementsMatch(t, properties, slices.Collect(maps.Keys(ref.Value.Properties)), "properties")
+				assert.ElementsMatch(t, required, ref.Value.Required, "required properties")
+			},
+		)
+	}
+}
+
+func Test_New_responsesMatchOpenAPI(t *testing.T) {
+	deps := newTestDependencies(t)
+	handler := New(zap.NewNop().Sugar(), deps)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{name: "liveness", target: "/healthz", wantStatus: http.StatusOK},
+		{name: "readiness", target: "/readyz", wantStatus: http.StatusOK},
+		{name: "version", target: "/version", wantStatus: http.StatusOK},
+		{name: "users", target: "/api/v1/users?page=1&pageSize=2", wantStatus: http.StatusOK},
+		{name: "user", target: "/api/v1/users/7", wantStatus: http.StatusOK},
+		{name: "unknown user", target: "/api/v1/users/8", wantStatus: http.StatusNotFound},
+		{name: "invalid user ID", target: "/api/v1/users/x", wantStatus: http.StatusBadRequest},
+		{name: "user action count", target: "/api/v1/users/7/actions/count", wantStatus: http.StatusOK},
+		{name: "next action probability", target: "/api/v1/actions/next-probability?next=ADD_CONTACT", wantStatus: http.StatusOK},
+		{name: "missing next action", target: "/api/v1/actions/next-probability", wantStatus: http.StatusBadRequest},
+		{name: "referrals", target: "/api/v1/actions/referrals", wantStatus: http.StatusOK},
+		{name: "graphql", target: "/graphql?query=" + url.QueryEscape(`{ user(id: "7") { name } }`), wantStatus: http.StatusOK},
+		{name: "missing graphql query", target: "/graphql", wantStatus: http.StatusBadRequest},
+		{name: "webhooks", target: "/api/v1/admin/webhooks", wantStatus: http.StatusOK},
+		{name: "unknown webhook", target: "/api/v1/admin/webhooks/wh_2", wantStatus: http.StatusNotFound},
+		{name: "dead letters", target: "/api/v1/admin/webhooks/dead-letters", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.target, nil)
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				require.Equal(t, tt.wantStatus, recorder.Code, recorder.Body.String())
+
+				route, pathParams, err := deps.OpenAPIRouter.FindRoute(req)
+				require.NoError(t, err)
+
+				err = openapi3filter.ValidateResponse(
+					t.Context(), &openapi3filter.ResponseValidationInput{
+						RequestValidationInput: &openapi3filter.RequestValidationInput{
+							Request:    req,
+							PathParams: pathParams,
+							Route:      route,
+						},
+						Status: recorder.Code,
+						Header: recorder.Header(),
+						Body:   io.NopCloser(recorder.Body),
+					},
+				)
+				assert.NoError(t, err, "response should match openapi.yaml")
+			},
+		)
+	}
+}
+
+func Test_New_graphqlAnalyticsGate(t *testing.T) {
+	deps := newTestDependencies(t)
+	deps.AnalyticsGate = ratelimit.NewGate(1, time.Millisecond)
+
+	release, _ := deps.AnalyticsGate.Acquire(t.Context())
+	defer release()
+
+	req := httptest.NewRequestWithContext(
+		t.Context(), http.MethodGet, "/graphql?query="+url.QueryEscape(`{ user(id: "7") { referralCount } }`), nil,
+	)
+
+	recorder := httptest.NewRecorder()
+	New(zap.NewNop().Sugar(), deps).ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "graphql should share the analytics gate")
+}
+
+// jsonFields returns the JSON names of the fields of t, and those always present.
+func jsonFields(t reflect.Type) ([]string, []string) {
+	var names, required []string
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		names = append(names, name)
+
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return names, required
+}
@@ -13,6 +13,14 @@ type User struct {
 	CreatedAt string `json:"createdAt"` // in ISO 8601 format (e.g., "2022-04-14T11:12:22.758Z") RFC3339
 }
 
+// UserColumns are the CSV columns of a User, in the order of its JSON fields.
+var UserColumns = []string{"id", "name", "createdAt"}
+
+// Record returns the CSV fields of u in UserColumns order.
+func (u User) Record() []string {
+	return []string{u.ID, u.Name, u.CreatedAt}
+}
+
 type UsersResponse struct {
 	Users      []User     `json:"users"`
 	Pagination Pagination `json:"pagination"`
@@ -1,8 +1,8 @@
 package user
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -10,12 +10,16 @@ import (
 	"go.uber.org/zap"
 
 	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
 	"surf_challenge/internal/api/user/dto"
 	"surf_challenge/internal/api/user/mapper"
 	"surf_challenge/internal/user"
 	"surf_challenge/internal/user/domain"
 )
 
+// streamBatchSize is the number of users read from the service at once when streaming.
+const streamBatchSize = 500
+
 type Handler interface {
 	GetUsers() http.HandlerFunc
 	GetUserActionCount() http.HandlerFunc
@@ -35,34 +39,19 @@ func NewHandler(sugar *zap.SugaredLogger, service user.Service) Handler {
 }
 
 func (h *usersHandler) GetUsers() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		resp, err := h.handleGetUsers(r)
-		if err != nil {
-			h.logger.Errorw("failed to get users", "error", err)
-
-			apiError := mapper.MapErrors(err)
-			http.Error(w, apiError.Message, apiError.Code)
-
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
-		err = json.NewEncoder(w).Encode(resp)
-		if err != nil {
-			h.logger.Errorw("failed to encode response", "error", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		}
-	}
+	return response.Handle(
+		h.logger, "failed to get users", h.handleGetUsers,
+		response.WithStream(response.ContentTypeNDJSON, h.streamUsersNDJSON),
+		response.WithStream(response.ContentTypeCSV, h.streamUsersCSV),
+	)
 }
 
-func (h *usersHandler) handleGetUsers(r *http.Request) (*dto.UsersResponse, error) {
+func (h *usersHandler) handleGetUsers(r *http.Request) (response.Conditional[*dto.UsersResponse], error) {
 	ctx := r.Context()
 
 	userID, page, size, err := extractQueryParams(r)
 	if err != nil {
-		return nil, err
+		return response.Conditional[*dto.UsersResponse]{}, err
 	}
 
 	users, pagination, err := h.service.QueryUsers(
@@ -74,7 +63,7 @@ func (h *usersHandler) handleGetUsers(r *http.Request) (*dto.UsersResponse, erro
 		},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("querying users: %w", err)
+		return response.Conditional[*dto.UsersResponse]{}, fmt.Errorf("querying users: %w", err)
 	}
 
 	usersResponse := mapper.MapUsersToDTO(users)
@@ -85,7 +74,98 @@ func (h *usersHandler) handleGetUsers(r *http.Request) (*dto.UsersResponse, erro
 		Pagination: paginationDTO,
 	}
 
-	return resp, nil
+	return response.Conditional[*dto.UsersResponse]{
+		Value:      resp,
+		Validators: usersValidators(users, pagination, page, size),
+	}, nil
+}
+
+// streamUsersNDJSON writes every user matching the query as NDJSON, one user per line.
+func (h *usersHandler) streamUsersNDJSON(r *http.Request, w io.Writer) error {
+	enc := response.NewNDJSONEncoder(w)
+
+	err := h.eachUser(
+		r, func(u dto.User) error {
+			return enc.Encode(u)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return enc.Flush()
+}
+
+// streamUsersCSV writes every user matching the query as CSV, in dto.UserColumns order.
+func (h *usersHandler) streamUsersCSV(r *http.Request, w io.Writer) error {
+	enc := response.NewCSVEncoder(w, dto.UserColumns)
+
+	err := h.eachUser(
+		r, func(u dto.User) error {
+			return enc.Encode(u.Record())
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return enc.Flush()
+}
+
+// eachUser calls fn for every user matching the query. With page or pageSize only the
+// requested page is visited; without, the whole collection is, reading it from the
+// service one batch at a time.
+func (h *usersHandler) eachUser(r *http.Request, fn func(dto.User) error) error {
+	ctx := r.Context()
+
+	userID, page, size, err := extractQueryParams(r)
+	if err != nil {
+		return err
+	}
+
+	if query := r.URL.Query(); query.Has("page") || query.Has("pageSize") {
+		users, _, err := h.service.QueryUsers(ctx, domain.Query{ID: userID, Page: page, PageSize: size})
+		if err != nil {
+			return fmt.Errorf("querying users: %w", err)
+		}
+
+		return emitUsers(users, fn)
+	}
+
+	for page, streamed := 1, 0; ; page++ {
+		users, results, err := h.service.QueryUsers(
+			ctx,
+			domain.Query{
+				ID:       userID,
+				Page:     page,
+				PageSize: streamBatchSize,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("querying users: %w", err)
+		}
+
+		err = emitUsers(users, fn)
+		if err != nil {
+			return err
+		}
+
+		streamed += len(users)
+		if len(users) < streamBatchSize || streamed >= results.TotalItems {
+			return nil
+		}
+	}
+}
+
+func emitUsers(users []*domain.User, fn func(dto.User) error) error {
+	for _, u := range users {
+		err := fn(mapper.MapUserToDTO(u))
+		if err != nil {
+			return fmt.Errorf("streaming users: %w", err)
+		}
+	}
+
+	return nil
 }
 
 func extractQueryParams(r *http.Request) (*int64, int, int, error) {
@@ -125,26 +205,7 @@ func extractQueryParams(r *http.Request) (*int64, int, int, error) {
 }
 
 func (h *usersHandler) GetUserActionCount() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		resp, err := h.handleGetUserActionCount(r)
-		if err != nil {
-			h.logger.Errorw("failed to get user action count", "error", err)
-
-			apiError := mapper.MapErrors(err)
-			http.Error(w, apiError.Message, apiError.Code)
-
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
-		err = json.NewEncoder(w).Encode(resp)
-		if err != nil {
-			h.logger.Errorw("failed to encode response", "error", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		}
-	}
+	return response.Handle(h.logger, "failed to get user action count", h.handleGetUserActionCount)
 }
 
 func (h *usersHandler) handleGetUserActionCount(r *http.Request) (*dto.ActionsCount, error) {
@@ -173,47 +234,29 @@ func (h *usersHandler) handleGetUserActionCount(r *http.Request) (*dto.ActionsCo
 }
 
 func (h *usersHandler) GetUserByID() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		resp, err := h.handleGetUserByID(r)
-		if err != nil {
-			h.logger.Errorw("failed to get user by ID", "error", err)
-
-			apiError := mapper.MapErrors(err)
-			http.Error(w, apiError.Message, apiError.Code)
-
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
-		err = json.NewEncoder(w).Encode(resp)
-		if err != nil {
-			h.logger.Errorw("failed to encode response", "error", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		}
-	}
+	return response.Handle(h.logger, "failed to get user by ID", h.handleGetUserByID)
 }
 
-func (h *usersHandler) handleGetUserByID(r *http.Request) (dto.User, error) {
+func (h *usersHandler) handleGetUserByID(r *http.Request) (response.Conditional[dto.User], error) {
 	ctx := r.Context()
 
 	userIDStr := chi.URLParam(r, "userId")
 	if userIDStr == "" {
-		return dto.User{}, apierror.NewAPIError("userId parameter is required", http.StatusBadRequest)
+		return response.Conditional[dto.User]{}, apierror.NewAPIError("userId parameter is required", http.StatusBadRequest)
 	}
 
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		return dto.User{}, apierror.NewAPIError("invalid userId parameter", http.StatusBadRequest)
+		return response.Conditional[dto.User]{}, apierror.NewAPIError("invalid userId parameter", http.StatusBadRequest)
 	}
 
 	userEntity, err := h.service.GetUserByID(ctx, userID)
 	if err != nil {
-		return dto.User{}, fmt.Errorf("getting user by ID: %w", err)
+		return response.Conditional[dto.User]{}, fmt.Errorf("getting user by ID: %w", err)
 	}
 
-	resp := mapper.MapUserToDTO(userEntity)
-
-	return resp, nil
+	return response.Conditional[dto.User]{
+		Value:      mapper.MapUserToDTO(userEntity),
+		Validators: userValidators(userEntity),
+	}, nil
 }
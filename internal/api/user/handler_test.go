@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -15,6 +16,7 @@ import (
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 
+	"surf_challenge/internal/action"
 	"surf_challenge/internal/api/user/dto"
 	"surf_challenge/internal/converter"
 	"surf_challenge/internal/user"
@@ -294,6 +296,7 @@ func Test_usersHandler_GetUsers(t *testing.T) {
 				h := NewHandler(m.logger, m.service)
 				h.GetUsers().ServeHTTP(recorder, req)
 
+				require.Equal(t, tt.wantStatus, recorder.Code)
 				tt.assertBody(t, recorder)
 			},
 		)
@@ -335,6 +338,54 @@ func Test_usersHandler_GetUserActionCount(t *testing.T) {
 				assert.JSONEq(t, string(expected), r.Body.String())
 			},
 		},
+		{
+			name:   "When user has no actions, should return zero count",
+			userID: "1",
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUserActionCount(
+					gomock.Any(),
+					int64(1),
+				).Return(0, nil)
+			},
+			wantStatus: http.StatusOK,
+			assertBody: func(t *testing.T, r *httptest.ResponseRecorder) {
+				t.Helper()
+
+				assert.JSONEq(t, `{"count":0}`, r.Body.String())
+			},
+		},
+		{
+			name:   "When user is not found, should return not found",
+			userID: "1",
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUserActionCount(
+					gomock.Any(),
+					int64(1),
+				).Return(0, user.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			assertBody: func(t *testing.T, r *httptest.ResponseRecorder) {
+				t.Helper()
+
+				assert.Contains(t, r.Body.String(), "Resource not found")
+			},
+		},
+		{
+			name:   "When actions data is unavailable, should return service unavailable",
+			userID: "1",
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUserActionCount(
+					gomock.Any(),
+					int64(1),
+				).Return(0, fmt.Errorf("getting actions: %w", action.ErrDataUnavailable))
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			assertBody: func(t *testing.T, r *httptest.ResponseRecorder) {
+				t.Helper()
+
+				assert.Contains(t, r.Body.String(), "Service unavailable")
+			},
+		},
 		{
 			name:       "When user ID is not an integer, should return bad request",
 			userID:     "abc",
@@ -393,6 +444,7 @@ func Test_usersHandler_GetUserActionCount(t *testing.T) {
 				h := NewHandler(m.logger, m.service)
 				h.GetUserActionCount().ServeHTTP(recorder, req)
 
+				require.Equal(t, tt.wantStatus, recorder.Code)
 				tt.assertBody(t, recorder)
 			},
 		)
@@ -400,6 +452,8 @@ func Test_usersHandler_GetUserActionCount(t *testing.T) {
 }
 
 func Test_usersHandler_GetUserByID(t *testing.T) {
+	updated := &domain.User{ID: 1, Name: "John Doe", Version: 4}
+
 	type mocks struct {
 		logger  *zap.SugaredLogger
 		service *user.MockService
@@ -408,6 +462,7 @@ func Test_usersHandler_GetUserByID(t *testing.T) {
 	tests := []struct {
 		name       string
 		userID     string
+		headers    map[string]string
 		mock       func(m *mocks)
 		wantstatus int
 		assertBody func(*testing.T, *httptest.ResponseRecorder)
@@ -424,6 +479,8 @@ func Test_usersHandler_GetUserByID(t *testing.T) {
 						ID:        1,
 						Name:      "John Doe",
 						CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+						Version:   domain.InitialVersion,
+						UpdatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 					},
 					nil,
 				)
@@ -441,6 +498,62 @@ func Test_usersHandler_GetUserByID(t *testing.T) {
 				expected, err := json.Marshal(want)
 				require.NoError(t, err)
 				assert.JSONEq(t, string(expected), r.Body.String())
+				assert.Equal(
+					t, userETag(
+						&domain.User{
+							ID: 1, Name: "John Doe", CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+							Version: domain.InitialVersion, UpdatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+						},
+					), r.Header().Get("ETag"),
+				)
+				assert.Equal(t, "Sun, 01 Jan 2023 00:00:00 GMT", r.Header().Get("Last-Modified"))
+			},
+		},
+		{
+			name:    "When If-None-Match matches the user version, should return not modified",
+			userID:  "1",
+			headers: map[string]string{"If-None-Match": userETag(updated)},
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(updated, nil)
+			},
+			wantstatus: http.StatusNotModified,
+			assertBody: func(t *testing.T, r *httptest.ResponseRecorder) {
+				t.Helper()
+
+				assert.Empty(t, r.Body.String())
+				assert.Equal(t, userETag(updated), r.Header().Get("ETag"))
+			},
+		},
+		{
+			name:    "When If-None-Match names an older version, should return the user",
+			userID:  "1",
+			headers: map[string]string{"If-None-Match": `"1-3"`, "If-Modified-Since": "Mon, 01 Jan 2024 00:00:00 GMT"},
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(
+					&domain.User{ID: 1, Name: "John Doe", Version: 4, UpdatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}, nil,
+				)
+			},
+			wantstatus: http.StatusOK,
+			assertBody: func(t *testing.T, r *httptest.ResponseRecorder) {
+				t.Helper()
+
+				assert.Contains(t, r.Body.String(), "John Doe")
+			},
+		},
+		{
+			name:    "When the user was not updated since If-Modified-Since, should return not modified",
+			userID:  "1",
+			headers: map[string]string{"If-Modified-Since": "Mon, 01 Jan 2024 00:00:00 GMT"},
+			mock: func(m *mocks) {
+				m.service.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(
+					&domain.User{ID: 1, Name: "John Doe", Version: 4, UpdatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}, nil,
+				)
+			},
+			wantstatus: http.StatusNotModified,
+			assertBody: func(t *testing.T, r *httptest.ResponseRecorder) {
+				t.Helper()
+
+				assert.Empty(t, r.Body.String())
 			},
 		},
 		{
@@ -513,12 +626,100 @@ func Test_usersHandler_GetUserByID(t *testing.T) {
 				)
 				require.NoError(t, err)
 
+				for k, v := range tt.headers {
+					req.Header.Set(k, v)
+				}
+
 				recorder := httptest.NewRecorder()
 				h := NewHandler(m.logger, m.service)
 				h.GetUserByID().ServeHTTP(recorder, req)
 
+				require.Equal(t, tt.wantstatus, recorder.Code)
 				tt.assertBody(t, recorder)
 			},
 		)
 	}
 }
+
+func Test_usersHandler_GetUsers_NDJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := user.NewMockService(ctrl)
+
+	batch := make([]*domain.User, streamBatchSize)
+	for i := range batch {
+		batch[i] = &domain.User{ID: int64(i), Name: "user"}
+	}
+
+	gomock.InOrder(
+		service.EXPECT().QueryUsers(gomock.Any(), domain.Query{Page: 1, PageSize: streamBatchSize}).
+			Return(batch, &domain.Results{TotalItems: streamBatchSize + 1}, nil),
+		service.EXPECT().QueryUsers(gomock.Any(), domain.Query{Page: 2, PageSize: streamBatchSize}).
+			Return([]*domain.User{{ID: streamBatchSize, Name: "last"}}, &domain.Results{TotalItems: streamBatchSize + 1}, nil),
+	)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/users", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	recorder := httptest.NewRecorder()
+	NewHandler(zap.NewNop().Sugar(), service).GetUsers().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+
+	decoder := json.NewDecoder(recorder.Body)
+
+	var got []dto.User
+
+	for decoder.More() {
+		var u dto.User
+		require.NoError(t, decoder.Decode(&u))
+
+		got = append(got, u)
+	}
+
+	require.Len(t, got, streamBatchSize+1, "should stream the whole collection without pagination")
+	assert.Equal(t, dto.User{ID: "500", Name: "last", CreatedAt: "0001-01-01T00:00:00Z"}, got[streamBatchSize])
+}
+
+func Test_usersHandler_GetUsers_NDJSON_page(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := user.NewMockService(ctrl)
+	service.EXPECT().QueryUsers(gomock.Any(), domain.Query{Page: 2, PageSize: 1}).
+		Return([]*domain.User{{ID: 2, Name: "second"}}, &domain.Results{TotalItems: 3}, nil)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/users?page=2&pageSize=1&format=ndjson", nil)
+
+	recorder := httptest.NewRecorder()
+	NewHandler(zap.NewNop().Sugar(), service).GetUsers().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"id":"2","name":"second","createdAt":"0001-01-01T00:00:00Z"}`, recorder.Body.String())
+}
+
+func Test_usersHandler_GetUsers_CSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := user.NewMockService(ctrl)
+
+	userID := int64(7)
+	service.EXPECT().QueryUsers(gomock.Any(), domain.Query{ID: &userID, Page: 1, PageSize: streamBatchSize}).
+		Return(
+			[]*domain.User{{ID: 7, Name: "Doe, Jane", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}},
+			&domain.Results{TotalItems: 1},
+			nil,
+		)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/users?userId=7&format=csv", nil)
+
+	recorder := httptest.NewRecorder()
+	NewHandler(zap.NewNop().Sugar(), service).GetUsers().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name,createdAt\n7,\"Doe, Jane\",2024-01-02T03:04:05Z\n", recorder.Body.String())
+}
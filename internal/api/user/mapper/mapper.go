@@ -1,31 +1,13 @@
 package mapper
 
 import (
-	"errors"
-	"net/http"
 	"strconv"
 	"time"
 
-	"surf_challenge/internal/api/apierror"
 	"surf_challenge/internal/api/user/dto"
-	"surf_challenge/internal/user"
 	"surf_challenge/internal/user/domain"
 )
 
-func MapErrors(err error) *apierror.APIError {
-	var apiErr *apierror.APIError
-	if errors.As(err, &apiErr) {
-		return apiErr
-	}
-
-	switch {
-	case errors.Is(err, user.ErrNotFound):
-		return apierror.NewAPIError("Resource not found", http.StatusNotFound)
-	default:
-		return apierror.NewAPIError("Internal server error", http.StatusInternalServerError)
-	}
-}
-
 func MapUsersToDTO(users []*domain.User) []dto.User {
 	userDTOs := make([]dto.User, len(users))
 	for i, u := range users {
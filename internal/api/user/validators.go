@@ -0,0 +1,54 @@
+package user
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"surf_challenge/internal/api/response"
+	"surf_challenge/internal/user/domain"
+)
+
+// userETag identifies a user record state by hashing its fields, so that it changes
+// when the dataset is edited without bumping the version. Update endpoints pass it to
+// response.CheckIfMatch to reject writes based on a stale read.
+func userETag(u *domain.User) string {
+	hash := sha256.New()
+	writeUser(hash, u)
+
+	return fmt.Sprintf(`"%d-%s"`, u.ID, hex.EncodeToString(hash.Sum(nil)[:8]))
+}
+
+// writeUser writes every field of u to w, unambiguously.
+func writeUser(w io.Writer, u *domain.User) {
+	_, _ = fmt.Fprintf(w, "|%d %q %d %d %d", u.ID, u.Name, u.CreatedAt.UnixNano(), u.Version, u.UpdatedAt.UnixNano())
+}
+
+func userValidators(u *domain.User) response.Validators {
+	return response.Validators{
+		ETag:         userETag(u),
+		LastModified: u.UpdatedAt,
+	}
+}
+
+// usersValidators derives the validators of a page from the fields of every user in it
+// and the pagination, so the tag changes when any listed record or the total does.
+func usersValidators(users []*domain.User, results *domain.Results, page, size int) response.Validators {
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%d/%d/%d", page, size, results.TotalItems)
+
+	var validators response.Validators
+
+	for _, u := range users {
+		writeUser(hash, u)
+
+		if u.UpdatedAt.After(validators.LastModified) {
+			validators.LastModified = u.UpdatedAt
+		}
+	}
+
+	validators.ETag = `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+
+	return validators
+}
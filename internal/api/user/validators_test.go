@@ -0,0 +1,47 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"surf_challenge/internal/user/domain"
+)
+
+func Test_userETag(t *testing.T) {
+	u := &domain.User{ID: 1, Name: "John Doe", Version: 1, CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	got := userETag(u)
+
+	assert.Regexp(t, `^"1-[0-9a-f]{16}"$`, got)
+	assert.Equal(t, got, userETag(&domain.User{ID: 1, Name: "John Doe", Version: 1, CreatedAt: u.CreatedAt}), "should be deterministic")
+
+	renamed := *u
+	renamed.Name = "Jane Doe"
+	assert.NotEqual(t, got, userETag(&renamed), "should change with a field edited without a version bump")
+
+	bumped := *u
+	bumped.Version = 2
+	assert.NotEqual(t, got, userETag(&bumped), "should change with the version")
+}
+
+func Test_usersValidators(t *testing.T) {
+	users := []*domain.User{
+		{ID: 1, Version: 1, UpdatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Version: 5, UpdatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	results := &domain.Results{TotalItems: 20}
+
+	got := usersValidators(users, results, 1, 2)
+
+	assert.Equal(t, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), got.LastModified)
+	assert.Equal(t, got, usersValidators(users, results, 1, 2), "should be deterministic")
+
+	updated := []*domain.User{users[0], {ID: 2, Version: 6, UpdatedAt: users[1].UpdatedAt}}
+	assert.NotEqual(t, got.ETag, usersValidators(updated, results, 1, 2).ETag, "should change with a record version")
+
+	renamed := []*domain.User{users[0], {ID: 2, Name: "Jane", Version: 5, UpdatedAt: users[1].UpdatedAt}}
+	assert.NotEqual(t, got.ETag, usersValidators(renamed, results, 1, 2).ETag, "should change with a record field")
+	assert.NotEqual(t, got.ETag, usersValidators(users, &domain.Results{TotalItems: 21}, 1, 2).ETag, "should change with the total")
+}
@@ -0,0 +1,46 @@
+package dto
+
+// CreateWebhookRequest registers an endpoint. ActionTypes restricts action.created
+// events to the given types.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	ActionTypes []string `json:"actionTypes,omitempty"`
+}
+
+type Webhook struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	ActionTypes []string `json:"actionTypes,omitempty"`
+	// Secret is only returned when the webhook is registered.
+	Secret    string `json:"secret,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type Delivery struct {
+	ID            string    `json:"id"`
+	WebhookID     string    `json:"webhookId"`
+	EventID       string    `json:"eventId"`
+	Event         string    `json:"event"`
+	Status        string    `json:"status"`
+	Attempts      []Attempt `json:"attempts"`
+	NextAttemptAt string    `json:"nextAttemptAt,omitempty"`
+	CreatedAt     string    `json:"createdAt"`
+}
+
+type Attempt struct {
+	Attempt    int    `json:"attempt"`
+	At         string `json:"at"`
+	DurationMs int64  `json:"durationMs"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []Delivery `json:"deliveries"`
+}
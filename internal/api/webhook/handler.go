@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
+	"surf_challenge/internal/api/webhook/dto"
+	"surf_challenge/internal/api/webhook/mapper"
+	"surf_challenge/internal/logger"
+	"surf_challenge/internal/webhook"
+)
+
+// maxBodyBytes bounds the size of a registration body.
+const maxBodyBytes = 64 << 10
+
+type Handler interface {
+	CreateWebhook() http.HandlerFunc
+	GetWebhooks() http.HandlerFunc
+	GetWebhook() http.HandlerFunc
+	DeleteWebhook() http.HandlerFunc
+	GetDeliveries() http.HandlerFunc
+	GetDeadLetters() http.HandlerFunc
+	RetryDeadLetter() http.HandlerFunc
+}
+
+type webhooksHandler struct {
+	logger  *zap.SugaredLogger
+	service webhook.Service
+}
+
+func NewHandler(sugar *zap.SugaredLogger, service webhook.Service) Handler {
+	return &webhooksHandler{
+		logger:  sugar,
+		service: service,
+	}
+}
+
+func (h *webhooksHandler) CreateWebhook() http.HandlerFunc {
+	return response.Handle(
+		h.logger, "failed to create webhook", h.handleCreateWebhook, response.WithStatus(http.StatusCreated),
+	)
+}
+
+func (h *webhooksHandler) handleCreateWebhook(r *http.Request) (*dto.Webhook, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != response.ContentTypeJSON {
+		return nil, apierror.NewAPIError("content type must be application/json", http.StatusUnsupportedMediaType)
+	}
+
+	var req dto.CreateWebhookRequest
+
+	err := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes)).Decode(&req)
+	if err != nil {
+		return nil, apierror.NewAPIError("invalid request body", http.StatusBadRequest)
+	}
+
+	endpoint, err := h.service.Register(r.Context(), mapper.MapRegistrationToDomain(&req))
+	if err != nil {
+		return nil, fmt.Errorf("registering webhook: %w", err)
+	}
+
+	resp := mapper.MapWebhookToDTO(endpoint)
+	resp.Secret = endpoint.Secret
+
+	return &resp, nil
+}
+
+func (h *webhooksHandler) GetWebhooks() http.HandlerFunc {
+	return response.Handle(h.logger, "failed to get webhooks", h.handleGetWebhooks)
+}
+
+func (h *webhooksHandler) handleGetWebhooks(r *http.Request) (*dto.WebhooksResponse, error) {
+	endpoints, err := h.service.ListEndpoints(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("listing webhooks: %w", err)
+	}
+
+	return mapper.MapWebhooksToDTO(endpoints), nil
+}
+
+func (h *webhooksHandler) GetWebhook() http.HandlerFunc {
+	return response.Handle(h.logger, "failed to get webhook", h.handleGetWebhook)
+}
+
+func (h *webhooksHandler) handleGetWebhook(r *http.Request) (*dto.Webhook, error) {
+	endpoint, err := h.service.GetEndpoint(r.Context(), chi.URLParam(r, "webhookId"))
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook: %w", err)
+	}
+
+	resp := mapper.MapWebhookToDTO(endpoint)
+
+	return &resp, nil
+}
+
+// DeleteWebhook answers 204 without a body, which response.Handle does not produce.
+func (h *webhooksHandler) DeleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h.service.DeleteEndpoint(r.Context(), chi.URLParam(r, "webhookId"))
+		if err != nil {
+			logger.FromContext(r.Context(), h.logger).Errorw("failed to delete webhook", "error", err)
+			apierror.Write(w, r, err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *webhooksHandler) GetDeliveries() http.HandlerFunc {
+	return response.Handle(h.logger, "failed to get webhook deliveries", h.handleGetDeliveries)
+}
+
+func (h *webhooksHandler) handleGetDeliveries(r *http.Request) (*dto.DeliveriesResponse, error) {
+	deliveries, err := h.service.ListDeliveries(r.Context(), chi.URLParam(r, "webhookId"))
+	if err != nil {
+		return nil, fmt.Errorf("listing webhook deliveries: %w", err)
+	}
+
+	return mapper.MapDeliveriesToDTO(deliveries), nil
+}
+
+func (h *webhooksHandler) GetDeadLetters() http.HandlerFunc {
+	return response.Handle(h.logger, "failed to get dead letters", h.handleGetDeadLetters)
+}
+
+func (h *webhooksHandler) handleGetDeadLetters(r *http.Request) (*dto.DeliveriesResponse, error) {
+	deliveries, err := h.service.ListDeadLetters(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("listing dead letters: %w", err)
+	}
+
+	return mapper.MapDeliveriesToDTO(deliveries), nil
+}
+
+func (h *webhooksHandler) RetryDeadLetter() http.HandlerFunc {
+	return response.Handle(
+		h.logger, "failed to retry dead letter", h.handleRetryDeadLetter, response.WithStatus(http.StatusAccepted),
+	)
+}
+
+func (h *webhooksHandler) handleRetryDeadLetter(r *http.Request) (*dto.Delivery, error) {
+	delivery, err := h.service.RetryDeadLetter(r.Context(), chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		return nil, fmt.Errorf("retrying dead letter: %w", err)
+	}
+
+	resp := mapper.MapDeliveryToDTO(delivery)
+
+	return &resp, nil
+}
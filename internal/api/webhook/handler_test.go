@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/webhook"
+	"surf_challenge/internal/webhook/domain"
+)
+
+func Test_webhooksHandler_CreateWebhook(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		mock        func(service *webhook.MockService)
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:        "When the registration is valid, should return the webhook with its secret",
+			contentType: "application/json",
+			body:        `{"url":"https://example.com/hooks","events":["action.created"],"actionTypes":["REFER_USER"]}`,
+			mock: func(service *webhook.MockService) {
+				service.EXPECT().Register(
+					gomock.Any(), domain.Registration{
+						URL: "https://example.com/hooks", Events: []string{"action.created"}, ActionTypes: []string{"REFER_USER"},
+					},
+				).Return(
+					&domain.Endpoint{
+						ID: "wh_1", URL: "https://example.com/hooks", Events: []string{"action.created"},
+						ActionTypes: []string{"REFER_USER"}, Secret: "whsec_1", CreatedAt: createdAt,
+					}, nil,
+				)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: `{
+				"id": "wh_1",
+				"url": "https://example.com/hooks",
+				"events": ["action.created"],
+				"actionTypes": ["REFER_USER"],
+				"secret": "whsec_1",
+				"createdAt": "2024-01-02T03:04:05Z"
+			}`,
+		},
+		{
+			name:        "When the registration is rejected, should return bad request with the reason",
+			contentType: "application/json",
+			body:        `{"url":"https://example.com/hooks","events":[]}`,
+			mock: func(service *webhook.MockService) {
+				service.EXPECT().Register(gomock.Any(), gomock.Any()).
+					Return(nil, &webhook.InvalidError{Reason: "events must not be empty"})
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "invalid webhook: events must not be empty",
+				"instance": "/api/v1/admin/webhooks"
+			}`,
+		},
+		{
+			name:        "When the body is not JSON, should return bad request",
+			contentType: "application/json",
+			body:        `{"url":`,
+			wantStatus:  http.StatusBadRequest,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Bad Request",
+				"status": 400,
+				"detail": "invalid request body",
+				"instance": "/api/v1/admin/webhooks"
+			}`,
+		},
+		{
+			name:        "When the content type is not JSON, should return unsupported media type",
+			contentType: "text/plain",
+			body:        `url=https://example.com`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantBody: `{
+				"type": "about:blank",
+				"title": "Unsupported Media Type",
+				"status": 415,
+				"detail": "content type must be application/json",
+				"instance": "/api/v1/admin/webhooks"
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				service := webhook.NewMockService(ctrl)
+				if tt.mock != nil {
+					tt.mock(service)
+				}
+
+				req := httptest.NewRequestWithContext(
+					t.Context(), http.MethodPost, "/api/v1/admin/webhooks", strings.NewReader(tt.body),
+				)
+				req.Header.Set("Content-Type", tt.contentType)
+
+				recorder := httptest.NewRecorder()
+				NewHandler(zap.NewNop().Sugar(), service).CreateWebhook().ServeHTTP(recorder, req)
+
+				require.Equal(t, tt.wantStatus, recorder.Code)
+				assert.JSONEq(t, tt.wantBody, recorder.Body.String())
+			},
+		)
+	}
+}
+
+func Test_webhooksHandler_DeleteWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "When the webhook exists, should return no content", wantStatus: http.StatusNoContent},
+		{name: "When the webhook is unknown, should return not found", err: webhook.ErrNotFound, wantStatus: http.StatusNotFound},
+		{name: "When the service fails, should return internal server error", err: assert.AnError, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				service := webhook.NewMockService(ctrl)
+				service.EXPECT().DeleteEndpoint(gomock.Any(), "wh_1").Return(tt.err)
+
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("webhookId", "wh_1")
+
+				req := httptest.NewRequestWithContext(
+					context.WithValue(t.Context(), chi.RouteCtxKey, rctx), http.MethodDelete, "/api/v1/admin/webhooks/wh_1", nil,
+				)
+
+				recorder := httptest.NewRecorder()
+				NewHandler(zap.NewNop().Sugar(), service).DeleteWebhook().ServeHTTP(recorder, req)
+
+				require.Equal(t, tt.wantStatus, recorder.Code)
+
+				if tt.err == nil {
+					assert.Empty(t, recorder.Body.String())
+				}
+			},
+		)
+	}
+}
+
+func Test_webhooksHandler_GetDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	next := at.Add(2 * time.Second)
+
+	service := webhook.NewMockService(ctrl)
+	service.EXPECT().ListDeliveries(gomock.Any(), "wh_1").Return(
+		[]*domain.Delivery{
+			{
+				ID: "dlv_1", EndpointID: "wh_1", Status: domain.DeliveryPending, CreatedAt: at, NextAttemptAt: &next,
+				Event: &domain.Event{ID: "evt_1", Type: domain.EventUserReferred},
+				Attempts: []domain.Attempt{
+					{Number: 1, At: at, Duration: 1500 * time.Millisecond, Error: "connection refused"},
+				},
+			},
+		}, nil,
+	)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("webhookId", "wh_1")
+
+	req := httptest.NewRequestWithContext(
+		context.WithValue(t.Context(), chi.RouteCtxKey, rctx), http.MethodGet, "/api/v1/admin/webhooks/wh_1/deliveries", nil,
+	)
+
+	recorder := httptest.NewRecorder()
+	NewHandler(zap.NewNop().Sugar(), service).GetDeliveries().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(
+		t, `{"deliveries": [{
+			"id": "dlv_1",
+			"webhookId": "wh_1",
+			"eventId": "evt_1",
+			"event": "user.referred",
+			"status": "pending",
+			"attempts": [{"attempt": 1, "at": "2024-01-02T03:04:05Z", "durationMs": 1500, "error": "connection refused"}],
+			"nextAttemptAt": "2024-01-02T03:04:07Z",
+			"createdAt": "2024-01-02T03:04:05Z"
+		}]}`, recorder.Body.String(),
+	)
+}
@@ -0,0 +1,76 @@
+package mapper
+
+import (
+	"time"
+
+	"surf_challenge/internal/api/webhook/dto"
+	"surf_challenge/internal/webhook/domain"
+)
+
+func MapRegistrationToDomain(req *dto.CreateWebhookRequest) domain.Registration {
+	return domain.Registration{
+		URL:         req.URL,
+		Events:      req.Events,
+		ActionTypes: req.ActionTypes,
+	}
+}
+
+// MapWebhookToDTO maps an endpoint without its secret, which is only shown once.
+func MapWebhookToDTO(e *domain.Endpoint) dto.Webhook {
+	return dto.Webhook{
+		ID:          e.ID,
+		URL:         e.URL,
+		Events:      e.Events,
+		ActionTypes: e.ActionTypes,
+		CreatedAt:   e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func MapWebhooksToDTO(endpoints []*domain.Endpoint) *dto.WebhooksResponse {
+	webhooks := make([]dto.Webhook, 0, len(endpoints))
+	for _, e := range endpoints {
+		webhooks = append(webhooks, MapWebhookToDTO(e))
+	}
+
+	return &dto.WebhooksResponse{Webhooks: webhooks}
+}
+
+func MapDeliveryToDTO(d *domain.Delivery) dto.Delivery {
+	attempts := make([]dto.Attempt, 0, len(d.Attempts))
+	for _, a := range d.Attempts {
+		attempts = append(
+			attempts, dto.Attempt{
+				Attempt:    a.Number,
+				At:         a.At.UTC().Format(time.RFC3339Nano),
+				DurationMs: a.Duration.Milliseconds(),
+				StatusCode: a.StatusCode,
+				Error:      a.Error,
+			},
+		)
+	}
+
+	delivery := dto.Delivery{
+		ID:        d.ID,
+		WebhookID: d.EndpointID,
+		EventID:   d.Event.ID,
+		Event:     d.Event.Type,
+		Status:    string(d.Status),
+		Attempts:  attempts,
+		CreatedAt: d.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	if d.NextAttemptAt != nil {
+		delivery.NextAttemptAt = d.NextAttemptAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return delivery
+}
+
+func MapDeliveriesToDTO(deliveries []*domain.Delivery) *dto.DeliveriesResponse {
+	resp := &dto.DeliveriesResponse{Deliveries: make([]dto.Delivery, 0, len(deliveries))}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, MapDeliveryToDTO(d))
+	}
+
+	return resp
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// APIKey is a static key as stored in the configuration: only the SHA-256 of the key
+// is kept, so leaking the config does not leak usable credentials.
+type APIKey struct {
+	Name   string
+	SHA256 string
+	Scopes []string
+}
+
+type apiKeyAuthenticator struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	name   string
+	hash   []byte
+	scopes []Scope
+}
+
+func NewAPIKeyAuthenticator(keys []APIKey) (Authenticator, error) {
+	parsed := make([]apiKey, 0, len(keys))
+
+	for _, key := range keys {
+		hash, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %q: sha256 must be a hex encoded SHA-256", key.Name)
+		}
+
+		scopes, err := ParseScopes(key.Scopes)
+		if err != nil {
+			return nil, fmt.Errorf("api key %q: %w", key.Name, err)
+		}
+
+		parsed = append(parsed, apiKey{name: key.Name, hash: hash, scopes: scopes})
+	}
+
+	return &apiKeyAuthenticator{
+		keys: parsed,
+	}, nil
+}
+
+// Authenticate matches the X-API-Key header against every configured key in constant
+// time, so the response time does not reveal which keys exist.
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(HeaderAPIKey)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	var match *apiKey
+
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].hash) == 1 {
+			match = &a.keys[i]
+		}
+	}
+
+	if match == nil {
+		return nil, ErrUnauthenticated
+	}
+
+	return &Principal{
+		Name:   match.name,
+		Scopes: match.scopes,
+	}, nil
+}
+
+func (a *apiKeyAuthenticator) Challenge() string {
+	return `APIKey header="` + HeaderAPIKey + `"`
+}
+
+// HashAPIKey returns the value to store in the configuration for key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_apiKeyAuthenticator_Authenticate(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator(
+		[]APIKey{
+			{Name: "analyst", SHA256: HashAPIKey("analyst-key"), Scopes: []string{"users:read", "analytics:read"}},
+			{Name: "ops", SHA256: HashAPIKey("ops-key"), Scopes: []string{"admin"}},
+		},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		key     string
+		want    *Principal
+		wantErr error
+	}{
+		{
+			name: "should return the principal owning the key",
+			key:  "analyst-key",
+			want: &Principal{Name: "analyst", Scopes: []Scope{ScopeUsersRead, ScopeAnalyticsRead}},
+		},
+		{
+			name: "should match keys beyond the first one",
+			key:  "ops-key",
+			want: &Principal{Name: "ops", Scopes: []Scope{ScopeAdmin}},
+		},
+		{
+			name:    "should fail when the key is unknown",
+			key:     "guess",
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:    "should fail when no key is sent",
+			wantErr: ErrUnauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				if tt.key != "" {
+					req.Header.Set(HeaderAPIKey, tt.key)
+				}
+
+				got, err := authenticator.Authenticate(req)
+
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
+
+func Test_NewAPIKeyAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     APIKey
+		wantErr string
+	}{
+		{
+			name:    "should reject hashes that are not SHA-256",
+			key:     APIKey{Name: "short", SHA256: "abcd", Scopes: []string{"admin"}},
+			wantErr: `api key "short": sha256 must be a hex encoded SHA-256`,
+		},
+		{
+			name:    "should reject unknown scopes",
+			key:     APIKey{Name: "typo", SHA256: HashAPIKey("k"), Scopes: []string{"user:read"}},
+			wantErr: `api key "typo": unknown scope: "user:read"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := NewAPIKeyAuthenticator([]APIKey{tt.key})
+
+				assert.EqualError(t, err, tt.wantErr)
+			},
+		)
+	}
+}
+
+func Test_Principal_HasScope(t *testing.T) {
+	analyst := &Principal{Scopes: []Scope{ScopeUsersRead}}
+	admin := &Principal{Scopes: []Scope{ScopeAdmin}}
+
+	assert.True(t, analyst.HasScope(ScopeUsersRead))
+	assert.False(t, analyst.HasScope(ScopeAnalyticsRead))
+	assert.True(t, admin.HasScope(ScopeActionsWrite))
+}
+
+func Test_Principal_CanAccessUser(t *testing.T) {
+	service := &Principal{Scopes: []Scope{ScopeUsersRead}}
+	endUser := &Principal{UserID: "7", Scopes: []Scope{ScopeUsersRead}}
+	endUserAdmin := &Principal{UserID: "1", Scopes: []Scope{ScopeAdmin}}
+
+	assert.True(t, service.CanAccessUser("7"))
+	assert.True(t, endUser.CanAccessUser("7"))
+	assert.False(t, endUser.CanAccessUser("8"))
+	assert.False(t, endUser.CanAccessUser(""))
+	assert.True(t, endUserAdmin.CanAccessUser("8"))
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type Scope string
+
+const (
+	ScopeUsersRead     Scope = "users:read"
+	ScopeActionsWrite  Scope = "actions:write"
+	ScopeAnalyticsRead Scope = "analytics:read"
+	// ScopeAdmin grants every other scope.
+	ScopeAdmin Scope = "admin"
+)
+
+var Scopes = []Scope{ScopeUsersRead, ScopeActionsWrite, ScopeAnalyticsRead, ScopeAdmin}
+
+var (
+	// ErrUnknownScope is returned when parsing a scope that is not in Scopes.
+	ErrUnknownScope = errors.New("unknown scope")
+	// ErrUnauthenticated is returned when credentials are missing or invalid.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrNoCredentials is returned by an Authenticator when the request carries none of
+	// its credentials, letting a chain fall through to the next one.
+	ErrNoCredentials = fmt.Errorf("%w: no credentials", ErrUnauthenticated)
+	// ErrForbidden is returned when the caller lacks the scope required by a route.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// ParseScopes converts configured scope names, rejecting unknown ones.
+func ParseScopes(names []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(names))
+
+	for _, name := range names {
+		scope := Scope(name)
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownScope, name)
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
+
+// Principal is the authenticated caller. UserID is set for end users authenticated
+// with a JWT, and empty for API keys which act on behalf of a service.
+type Principal struct {
+	Name   string
+	UserID string
+	Scopes []Scope
+}
+
+// HasScope reports whether the principal was granted scope, admin granting all.
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// CanAccessUser reports whether the principal may read the user with id: end users
+// only see themselves, services and admins see everyone.
+func (p *Principal) CanAccessUser(id string) bool {
+	if p.UserID == "" || p.HasScope(ScopeAdmin) {
+		return true
+	}
+
+	return p.UserID == id
+}
+
+//go:generate mockgen -source=auth.go -destination=auth_mock.go -package=auth
+type Authenticator interface {
+	// Authenticate returns the caller of r, or ErrUnauthenticated.
+	Authenticate(r *http.Request) (*Principal, error)
+	// Challenge is the WWW-Authenticate value sent along a 401.
+	Challenge() string
+}
+
+type chainAuthenticator struct {
+	authenticators []Authenticator
+}
+
+// NewChainAuthenticator tries each authenticator in order until one finds credentials
+// in the request. Invalid credentials are rejected without trying the next ones.
+func NewChainAuthenticator(authenticators ...Authenticator) Authenticator {
+	return &chainAuthenticator{
+		authenticators: authenticators,
+	}
+}
+
+func (c *chainAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	for _, authenticator := range c.authenticators {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+
+		return principal, err
+	}
+
+	return nil, ErrNoCredentials
+}
+
+func (c *chainAuthenticator) Challenge() string {
+	challenges := make([]string, 0, len(c.authenticators))
+	for _, authenticator := range c.authenticators {
+		challenges = append(challenges, authenticator.Challenge())
+	}
+
+	return strings.Join(challenges, ", ")
+}
+
+// AnonymousName is the principal name given to every request when auth is disabled.
+const AnonymousName = "anonymous"
+
+type anonymousAuthenticator struct{}
+
+// NewAnonymousAuthenticator accepts every request as an admin. It is used when auth is
+// disabled so routes can declare their scopes unconditionally.
+func NewAnonymousAuthenticator() Authenticator {
+	return anonymousAuthenticator{}
+}
+
+func (anonymousAuthenticator) Authenticate(*http.Request) (*Principal, error) {
+	return &Principal{Name: AnonymousName, Scopes: []Scope{ScopeAdmin}}, nil
+}
+
+func (anonymousAuthenticator) Challenge() string {
+	return ""
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(ctxKey{}).(*Principal)
+
+	return principal, ok
+}
@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig configures bearer token validation. At least one of the key files must be
+// set; keys from every file are candidates, narrowed down by the token "kid" header.
+type JWTConfig struct {
+	Issuer           string
+	Audience         string
+	HMACSecretFile   string
+	RSAPublicKeyFile string
+	JWKSFile         string
+	// ScopeClaim holds the granted scopes, as a space separated string or an array.
+	ScopeClaim string
+	// UserIDClaim holds the ID of the end user the token was issued to.
+	UserIDClaim string
+	Leeway      time.Duration
+}
+
+var hmacMethods = []string{"HS256", "HS384", "HS512"}
+
+var rsaMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+
+type verificationKey struct {
+	id  string
+	key any
+}
+
+type jwtAuthenticator struct {
+	parser      *jwt.Parser
+	keys        []verificationKey
+	scopeClaim  string
+	userIDClaim string
+}
+
+func NewJWTAuthenticator(cfg JWTConfig) (Authenticator, error) {
+	keys, err := loadVerificationKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwt: no verification key configured")
+	}
+
+	var methods []string
+
+	for _, key := range keys {
+		switch key.key.(type) {
+		case []byte:
+			methods = append(methods, hmacMethods...)
+		case *rsa.PublicKey:
+			methods = append(methods, rsaMethods...)
+		}
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &jwtAuthenticator{
+		parser:      jwt.NewParser(opts...),
+		keys:        keys,
+		scopeClaim:  cfg.ScopeClaim,
+		userIDClaim: cfg.UserIDClaim,
+	}, nil
+}
+
+// Authenticate validates the bearer token signature, expiry, issuer and audience, then
+// maps its claims to a principal. Scopes unknown to this server are ignored.
+func (a *jwtAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+
+	_, err := a.parser.ParseWithClaims(strings.TrimSpace(token), claims, a.keyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+
+	userID, _ := claims[a.userIDClaim].(string)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrUnauthenticated, a.userIDClaim)
+	}
+
+	var scopes []Scope
+
+	for _, name := range claimStrings(claims[a.scopeClaim]) {
+		if scope := Scope(name); slices.Contains(Scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &Principal{
+		Name:   "user:" + userID,
+		UserID: userID,
+		Scopes: scopes,
+	}, nil
+}
+
+func (a *jwtAuthenticator) Challenge() string {
+	return "Bearer"
+}
+
+// keyFunc returns every configured key matching the token kid and algorithm family.
+func (a *jwtAuthenticator) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var set jwt.VerificationKeySet
+
+	for _, key := range a.keys {
+		if kid != "" && key.id != "" && key.id != kid {
+			continue
+		}
+
+		switch key.key.(type) {
+		case []byte:
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				continue
+			}
+		case *rsa.PublicKey:
+			switch token.Method.(type) {
+			case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			default:
+				continue
+			}
+		}
+
+		set.Keys = append(set.Keys, key.key)
+	}
+
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("no key for kid %q and alg %s", kid, token.Method.Alg())
+	}
+
+	return set, nil
+}
+
+func claimStrings(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// Minimum strength of the verification keys, so that tokens cannot be forged with a
+// guessable secret or a factorable modulus.
+const (
+	minHMACSecretBytes = 32
+	minRSABits         = 2048
+)
+
+func loadVerificationKeys(cfg JWTConfig) ([]verificationKey, error) {
+	var keys []verificationKey
+
+	if cfg.HMACSecretFile != "" {
+		secret, err := os.ReadFile(cfg.HMACSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: reading HMAC secret: %w", err)
+		}
+
+		secret = []byte(strings.TrimSpace(string(secret)))
+		if len(secret) < minHMACSecretBytes {
+			return nil, fmt.Errorf("jwt: HMAC secret must be at least %d bytes", minHMACSecretBytes)
+		}
+
+		keys = append(keys, verificationKey{key: secret})
+	}
+
+	if cfg.RSAPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: reading RSA public key: %w", err)
+		}
+
+		key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: parsing RSA public key: %w", err)
+		}
+
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("jwt: RSA public key must be at least %d bits, got %d", minRSABits, key.N.BitLen())
+		}
+
+		keys = append(keys, verificationKey{key: key})
+	}
+
+	if cfg.JWKSFile != "" {
+		jwks, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: %w", err)
+		}
+
+		keys = append(keys, jwks...)
+	}
+
+	return keys, nil
+}
+
+// jwk holds the members of RFC 7517 keys this server understands: RSA public keys and
+// symmetric ("oct") keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+func loadJWKS(path string) ([]verificationKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	err = json.Unmarshal(data, &set)
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := make([]verificationKey, 0, len(set.Keys))
+
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.parse()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d (kid %q): %w", i, k.Kid, err)
+		}
+
+		keys = append(keys, verificationKey{id: k.Kid, key: key})
+	}
+
+	return keys, nil
+}
+
+func (k jwk) parse() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 {
+			return nil, errors.New("invalid exponent")
+		}
+
+		modulus := new(big.Int).SetBytes(n)
+		if modulus.BitLen() < minRSABits {
+			return nil, fmt.Errorf("modulus must be at least %d bits, got %d", minRSABits, modulus.BitLen())
+		}
+
+		return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret: %w", err)
+		}
+
+		// HMAC verification accepts any key, an empty one included.
+		if len(secret) < minHMACSecretBytes {
+			return nil, fmt.Errorf("secret must be at least %d bytes, got %d", minHMACSecretBytes, len(secret))
+		}
+
+		return secret, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_jwtAuthenticator_Authenticate(t *testing.T) {
+	dir := t.TempDir()
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretFile, append(secret, '\n'), 0o600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(
+		map[string]any{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "rsa-1",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(dir, "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	authenticator, err := NewJWTAuthenticator(
+		JWTConfig{
+			Issuer:         "surf-idp",
+			HMACSecretFile: secretFile,
+			JWKSFile:       jwksFile,
+			ScopeClaim:     "scope",
+			UserIDClaim:    "sub",
+		},
+	)
+	require.NoError(t, err)
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":   "surf-idp",
+			"sub":   "7",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "users:read unknown:scope",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+
+		return c
+	}
+
+	hmacToken := func(c jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(secret)
+		require.NoError(t, err)
+
+		return token
+	}
+
+	rsaToken := func(kid string, c jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		token.Header["kid"] = kid
+
+		signed, err := token.SignedString(rsaKey)
+		require.NoError(t, err)
+
+		return signed
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          *Principal
+		wantErr       error
+	}{
+		{
+			name:          "should accept an HMAC token and keep known scopes only",
+			authorization: "Bearer " + hmacToken(claims(nil)),
+			want:          &Principal{Name: "user:7", UserID: "7", Scopes: []Scope{ScopeUsersRead}},
+		},
+		{
+			name:          "should accept an RSA token signed with a JWKS key and array scopes",
+			authorization: "Bearer " + rsaToken("rsa-1", claims(jwt.MapClaims{"scope": []string{"admin"}})),
+			want:          &Principal{Name: "user:7", UserID: "7", Scopes: []Scope{ScopeAdmin}},
+		},
+		{
+			name:          "should reject an RSA token with an unknown kid",
+			authorization: "Bearer " + rsaToken("rsa-2", claims(nil)),
+			wantErr:       ErrUnauthenticated,
+		},
+		{
+			name:          "should reject an expired token",
+			authorization: "Bearer " + hmacToken(claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+			wantErr:       ErrUnauthenticated,
+		},
+		{
+			name:          "should reject a token from another issuer",
+			authorization: "Bearer " + hmacToken(claims(jwt.MapClaims{"iss": "elsewhere"})),
+			wantErr:       ErrUnauthenticated,
+		},
+		{
+			name:          "should reject a token without user ID",
+			authorization: "Bearer " + hmacToken(claims(jwt.MapClaims{"sub": ""})),
+			wantErr:       ErrUnauthenticated,
+		},
+		{
+			name:          "should reject a tampered token",
+			authorization: "Bearer " + hmacToken(claims(nil)) + "x",
+			wantErr:       ErrUnauthenticated,
+		},
+		{
+			name:          "should report missing credentials for other schemes",
+			authorization: "Basic dXNlcjpwYXNz",
+			wantErr:       ErrNoCredentials,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				req.Header.Set("Authorization", tt.authorization)
+
+				got, err := authenticator.Authenticate(req)
+
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
+
+func Test_chainAuthenticator_Authenticate(t *testing.T) {
+	apiKeys, err := NewAPIKeyAuthenticator(
+		[]APIKey{{Name: "service", SHA256: HashAPIKey("service-key"), Scopes: []string{"users:read"}}},
+	)
+	require.NoError(t, err)
+
+	chain := NewChainAuthenticator(apiKeys, NewAnonymousAuthenticator())
+
+	tests := []struct {
+		name     string
+		key      string
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "should use the first authenticator finding credentials",
+			key:      "service-key",
+			wantName: "service",
+		},
+		{
+			name:    "should not fall through on invalid credentials",
+			key:     "guess",
+			wantErr: ErrUnauthenticated,
+		},
+		{
+			name:     "should fall through when credentials are missing",
+			wantName: "anonymous",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+				if tt.key != "" {
+					req.Header.Set(HeaderAPIKey, tt.key)
+				}
+
+				got, err := chain.Authenticate(req)
+
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				if tt.wantName != "" {
+					require.NotNil(t, got)
+					assert.Equal(t, tt.wantName, got.Name)
+				}
+			},
+		)
+	}
+}
+
+func Test_loadJWKS(t *testing.T) {
+	modulus := func(bits int) string {
+		n := make([]byte, bits/8)
+		n[0] = 0x80
+
+		return base64.RawURLEncoding.EncodeToString(n)
+	}
+	exponent := base64.RawURLEncoding.EncodeToString(big.NewInt(65537).Bytes())
+	secret := func(size int) string {
+		return base64.RawURLEncoding.EncodeToString(make([]byte, size))
+	}
+
+	tests := []struct {
+		name    string
+		key     map[string]string
+		wantErr string
+	}{
+		{
+			name: "should accept a 2048-bit RSA key",
+			key:  map[string]string{"kty": "RSA", "kid": "rsa", "n": modulus(2048), "e": exponent},
+		},
+		{
+			name:    "should reject an RSA key under 2048 bits",
+			key:     map[string]string{"kty": "RSA", "kid": "rsa", "n": modulus(1024), "e": exponent},
+			wantErr: `JWKS key 0 (kid "rsa"): modulus must be at least 2048 bits, got 1024`,
+		},
+		{
+			name: "should accept a 32-byte symmetric key",
+			key:  map[string]string{"kty": "oct", "kid": "hs", "k": secret(32)},
+		},
+		{
+			name:    "should reject a symmetric key under 32 bytes",
+			key:     map[string]string{"kty": "oct", "kid": "hs", "k": secret(16)},
+			wantErr: `JWKS key 0 (kid "hs"): secret must be at least 32 bytes, got 16`,
+		},
+		{
+			name:    "should reject an empty symmetric key",
+			key:     map[string]string{"kty": "oct", "kid": "hs"},
+			wantErr: `JWKS key 0 (kid "hs"): secret must be at least 32 bytes, got 0`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				data, err := json.Marshal(map[string]any{"keys": []map[string]string{tt.key}})
+				require.NoError(t, err)
+
+				path := filepath.Join(t.TempDir(), "jwks.json")
+				require.NoError(t, os.WriteFile(path, data, 0o600))
+
+				keys, err := loadJWKS(path)
+
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Len(t, keys, 1)
+			},
+		)
+	}
+}
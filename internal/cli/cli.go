@@ -0,0 +1,258 @@
+// Package cli implements surfctl, a command-line client of the Surf API that can also
+// run the same services directly over the data files.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/config"
+)
+
+// Exit codes of Run.
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+	// ExitInvalid is returned by validate when the data files have issues, telling them
+	// apart from a failure to check them.
+	ExitInvalid = 3
+)
+
+// Env vars providing the defaults of the global flags. The data files are shared
+// with the server configuration.
+const (
+	envServer      = "SURFCTL_SERVER"
+	envAPIKey      = "SURFCTL_API_KEY"
+	envToken       = "SURFCTL_TOKEN"
+	envUsersFile   = "SURF_DATA_USERS_FILE"
+	envActionsFile = "SURF_DATA_ACTIONS_FILE"
+)
+
+// errUsage reports invalid arguments, the message having been printed along with the
+// usage.
+var errUsage = errors.New("usage")
+
+// options are the global flags, set before the command name.
+type options struct {
+	server      string
+	apiKey      string
+	token       string
+	usersFile   string
+	actionsFile string
+	output      string
+	timeout     time.Duration
+}
+
+// command is a surfctl subcommand. run parses the command arguments with flags, the
+// usage being printed on failure.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error)
+}
+
+// environment is what commands run with.
+type environment struct {
+	opts   options
+	client func() (Client, error)
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func commands() []command {
+	return []command{
+		{name: "user", args: "<userId>", summary: "get a user", run: runUser},
+		{name: "users", args: "[-page n] [-page-size n]", summary: "list users", run: runUsers},
+		{name: "action-count", args: "<userId>", summary: "count the actions of a user", run: runActionCount},
+		{
+			name: "next-probability", args: "<actionType>", summary: "probability of the actions following an action",
+			run: runNextProbability,
+		},
+		{name: "referrals", summary: "referral index of every user", run: runReferrals},
+		{name: "leaderboard", args: "[-limit n]", summary: "users with the most referrals", run: runLeaderboard},
+		{
+			name: "report", args: "[-out dir] [-funnel types] [-retention-periods n]",
+			summary: "write the analytics of the data files to a new report directory", run: runReport,
+		},
+		{
+			name: "validate", args: "[-types types] [-max-issues n]",
+			summary: "check the data files, exiting with 3 when issues are found", run: runValidate,
+		},
+		{
+			name: "generate", args: "-users n [-actions n] [-seed n] [-start time] [-end time] [-out dir]",
+			summary: "generate synthetic data files following the behaviour in the data files", run: runGenerate,
+		},
+	}
+}
+
+// Run executes surfctl with args, the program name excluded, and returns the exit code.
+func Run(ctx context.Context, args []string, lookupEnv config.LookupEnv, stdout, stderr io.Writer) int {
+	env := &environment{stdout: stdout, stderr: stderr}
+
+	global := flag.NewFlagSet("surfctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() {
+		usage(global)
+	}
+
+	global.StringVar(
+		&env.opts.server, "server", getenv(lookupEnv, envServer),
+		"URL of a running server, the data files are read directly when empty (env "+envServer+")",
+	)
+	global.StringVar(&env.opts.apiKey, "api-key", getenv(lookupEnv, envAPIKey), "API key sent to the server (env "+envAPIKey+")")
+	global.StringVar(&env.opts.token, "token", getenv(lookupEnv, envToken), "bearer token sent to the server (env "+envToken+")")
+	global.StringVar(
+		&env.opts.usersFile, "users-file", getenv(lookupEnv, envUsersFile),
+		"users JSON file, embedded dataset when empty (env "+envUsersFile+")",
+	)
+	global.StringVar(
+		&env.opts.actionsFile, "actions-file", getenv(lookupEnv, envActionsFile),
+		"actions JSON file, embedded dataset when empty (env "+envActionsFile+")",
+	)
+	global.StringVar(&env.opts.output, "o", FormatTable, "output format: table, json or csv")
+	global.DurationVar(&env.opts.timeout, "timeout", 30*time.Second, "server request timeout")
+
+	err := global.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+
+		return ExitUsage
+	}
+
+	switch env.opts.output {
+	case FormatTable, FormatJSON, FormatCSV:
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown output format %q\n", env.opts.output)
+
+		return ExitUsage
+	}
+
+	env.client = func() (Client, error) {
+		if env.opts.server != "" {
+			return NewHTTPClient(env.opts.server, env.opts.apiKey, env.opts.token, env.opts.timeout)
+		}
+
+		return NewLocalClient(zap.NewNop().Sugar(), env.opts.usersFile, env.opts.actionsFile), nil
+	}
+
+	if global.NArg() == 0 {
+		usage(global)
+
+		return ExitUsage
+	}
+
+	name := global.Arg(0)
+
+	for _, cmd := range commands() {
+		if cmd.name != name {
+			continue
+		}
+
+		return execute(ctx, env, cmd, global.Args()[1:])
+	}
+
+	_, _ = fmt.Fprintf(stderr, "unknown command %q\n", name)
+	usage(global)
+
+	return ExitUsage
+}
+
+func execute(ctx context.Context, env *environment, cmd command, args []string) int {
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(env.stderr, "usage: surfctl [flags] %s %s\n\n%s.\n", cmd.name, cmd.args, capitalize(cmd.summary))
+		flags.PrintDefaults()
+	}
+
+	res, err := cmd.run(ctx, env, flags, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return ExitOK
+		case errors.Is(err, errUsage):
+			return ExitUsage
+		}
+
+		_, _ = fmt.Fprintf(env.stderr, "surfctl %s: %v\n", cmd.name, err)
+
+		return ExitError
+	}
+
+	err = write(env.stdout, env.opts.output, res)
+	if err != nil {
+		_, _ = fmt.Fprintf(env.stderr, "surfctl %s: writing output: %v\n", cmd.name, err)
+
+		return ExitError
+	}
+
+	return res.code
+}
+
+func usage(global *flag.FlagSet) {
+	w := global.Output()
+
+	_, _ = fmt.Fprintln(w, "usage: surfctl [flags] <command> [arguments]\n\nCommands:")
+
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(w, "  %-17s %s\n", cmd.name, cmd.summary)
+	}
+
+	_, _ = fmt.Fprintln(w, "\nFlags:")
+	global.PrintDefaults()
+}
+
+// parse parses the command arguments, and checks that exactly positional ones remain.
+// The usage is printed on failure.
+func parse(flags *flag.FlagSet, args []string, positional int) error {
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if flags.NArg() != positional {
+		_, _ = fmt.Fprintf(flags.Output(), "expected %d argument(s), got %d\n", positional, flags.NArg())
+		flags.Usage()
+
+		return errUsage
+	}
+
+	return nil
+}
+
+// usageError prints the formatted message and the usage of the command.
+func usageError(flags *flag.FlagSet, format string, args ...any) error {
+	_, _ = fmt.Fprintf(flags.Output(), format+"\n", args...)
+	flags.Usage()
+
+	return errUsage
+}
+
+func getenv(lookupEnv config.LookupEnv, key string) string {
+	v, _ := lookupEnv(key)
+
+	return v
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
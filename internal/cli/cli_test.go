@@ -0,0 +1,348 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	actiondto "surf_challenge/internal/api/action/dto"
+	"surf_challenge/internal/api/router"
+	"surf_challenge/internal/config"
+	"surf_challenge/internal/container"
+	"surf_challenge/internal/report"
+)
+
+const (
+	testUsers = `[
+		{"id":1,"name":"Ada","createdAt":"2021-01-01T00:00:00Z"},
+		{"id":2,"name":"Bob","createdAt":"2021-01-02T00:00:00Z"},
+		{"id":3,"name":"Cy","createdAt":"2021-01-03T00:00:00Z"}
+	]`
+	testActions = `[
+		{"id":1,"type":"VIEW_CONTACTS","userId":1,"createdAt":"2021-02-01T00:00:00Z"},
+		{"id":2,"type":"REFER_USER","userId":1,"targetUser":2,"createdAt":"2021-02-02T00:00:00Z"},
+		{"id":3,"type":"VIEW_CONTACTS","userId":2,"createdAt":"2021-02-03T00:00:00Z"},
+		{"id":4,"type":"REFER_USER","userId":2,"targetUser":3,"createdAt":"2021-02-04T00:00:00Z"},
+		{"id":5,"type":"EDIT_CONTACT","userId":3,"createdAt":"2021-02-05T00:00:00Z"}
+	]`
+)
+
+// writeDatasets writes the test datasets and returns the env pointing surfctl to them.
+func writeDatasets(t *testing.T) map[string]string {
+	t.Helper()
+
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "users.json")
+	actionsFile := filepath.Join(dir, "actions.json")
+
+	require.NoError(t, os.WriteFile(usersFile, []byte(testUsers), 0o600))
+	require.NoError(t, os.WriteFile(actionsFile, []byte(testActions), 0o600))
+
+	return map[string]string{envUsersFile: usersFile, envActionsFile: actionsFile}
+}
+
+func run(t *testing.T, env map[string]string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	lookupEnv := func(key string) (string, bool) {
+		v, ok := env[key]
+
+		return v, ok
+	}
+
+	code := Run(t.Context(), args, lookupEnv, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func Test_Run(t *testing.T) {
+	env := writeDatasets(t)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "When getting a user, should print it as a table",
+			args:       []string{"user", "2"},
+			wantStdout: "ID  NAME  CREATEDAT\n2   Bob   2021-01-02T00:00:00Z\n",
+		},
+		{
+			name:       "When listing users as CSV, should print the page",
+			args:       []string{"-o", "csv", "users", "-page", "2", "-page-size", "2"},
+			wantStdout: "id,name,createdAt\n3,Cy,2021-01-03T00:00:00Z\n",
+		},
+		{
+			name:       "When listing users as a table, should print the pagination below",
+			args:       []string{"users", "-page-size", "2"},
+			wantStdout: "ID  NAME  CREATEDAT\n1   Ada   2021-01-01T00:00:00Z\n2   Bob   2021-01-02T00:00:00Z\npage 1 of 2, 3 users\n",
+		},
+		{
+			name:       "When counting actions as JSON, should print the count",
+			args:       []string{"-o", "json", "action-count", "1"},
+			wantStdout: "{\n  \"count\": 2\n}\n",
+		},
+		{
+			name:       "When getting next action probabilities, should list the most likely first",
+			args:       []string{"-o", "csv", "next-probability", "VIEW_CONTACTS"},
+			wantStdout: "action,probability\nREFER_USER,1.00\n",
+		},
+		{
+			name:       "When getting referrals, should list them by user ID",
+			args:       []string{"-o", "csv", "referrals"},
+			wantStdout: "userId,referrals\n1,2\n2,1\n",
+		},
+		{
+			name:       "When getting the leaderboard, should rank users by referrals",
+			args:       []string{"-o", "json", "leaderboard", "-limit", "1"},
+			wantStdout: "[\n  {\n    \"rank\": 1,\n    \"userId\": 1,\n    \"referrals\": 2\n  }\n]\n",
+		},
+		{
+			name:       "When the user does not exist, should fail",
+			args:       []string{"user", "9"},
+			wantCode:   ExitError,
+			wantStderr: "surfctl user: user 9 not found\n",
+		},
+		{
+			name:       "When the user ID is invalid, should print the usage",
+			args:       []string{"action-count", "abc"},
+			wantCode:   ExitUsage,
+			wantStderr: "invalid user ID \"abc\"\nusage: surfctl [flags] action-count <userId>",
+		},
+		{
+			name:       "When the command is unknown, should print the usage",
+			args:       []string{"delete-user"},
+			wantCode:   ExitUsage,
+			wantStderr: "unknown command \"delete-user\"\nusage: surfctl [flags] <command> [arguments]",
+		},
+		{
+			name:       "When reporting against a server, should print the usage",
+			args:       []string{"-server", "http://localhost:3000", "report"},
+			wantCode:   ExitUsage,
+			wantStderr: "report reads the data files, -server is not supported\nusage: surfctl [flags] report",
+		},
+		{
+			name:       "When the retention periods are not positive, should print the usage",
+			args:       []string{"report", "-retention-periods", "0"},
+			wantCode:   ExitUsage,
+			wantStderr: "funnel must list an action type and retention-periods must be positive\n",
+		},
+		{
+			name:       "When validating consistent data files, should report no issue",
+			args:       []string{"validate"},
+			wantStdout: "FILE  RECORD  LINE  OFFSET  ID  CODE  MESSAGE\n3 users and 5 actions checked, 0 issues found\n",
+		},
+		{
+			name:       "When validating against a server, should print the usage",
+			args:       []string{"-server", "http://localhost:3000", "validate"},
+			wantCode:   ExitUsage,
+			wantStderr: "validate reads the data files, -server is not supported\nusage: surfctl [flags] validate",
+		},
+		{
+			name:       "When generating without users, should print the usage",
+			args:       []string{"generate", "-actions", "10"},
+			wantCode:   ExitUsage,
+			wantStderr: "users must be positive and actions must not be negative\nusage: surfctl [flags] generate",
+		},
+		{
+			name:       "When the generation start is invalid, should print the usage",
+			args:       []string{"generate", "-users", "10", "-start", "2021-01-01"},
+			wantCode:   ExitUsage,
+			wantStderr: "invalid start \"2021-01-01\", expected an RFC 3339 time\n",
+		},
+		{
+			name:       "When the output format is unknown, should fail",
+			args:       []string{"-o", "xml", "referrals"},
+			wantCode:   ExitUsage,
+			wantStderr: "unknown output format \"xml\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				code, stdout, stderr := run(t, env, tt.args...)
+
+				assert.Equal(t, tt.wantCode, code, stderr)
+				assert.Equal(t, tt.wantStdout, stdout)
+				assert.Contains(t, stderr, tt.wantStderr)
+			},
+		)
+	}
+}
+
+func Test_Run_serverMatchesDataFiles(t *testing.T) {
+	env := writeDatasets(t)
+
+	cfg := config.Default()
+	cfg.Auth.Enabled = false
+	cfg.Data.UsersFile = env[envUsersFile]
+	cfg.Data.ActionsFile = env[envActionsFile]
+
+	deps, err := container.NewAppContainer(zap.NewNop().Sugar(), cfg)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(router.New(zap.NewNop().Sugar(), deps))
+	t.Cleanup(server.Close)
+
+	remote := map[string]string{envServer: server.URL}
+
+	commands := [][]string{
+		{"user", "1"},
+		{"users", "-page-size", "2"},
+		{"action-count", "2"},
+		{"next-probability", "VIEW_CONTACTS"},
+		{"referrals"},
+		{"leaderboard"},
+	}
+	for _, args := range commands {
+		for _, format := range []string{FormatTable, FormatJSON, FormatCSV} {
+			t.Run(
+				args[0]+"/"+format, func(t *testing.T) {
+					args := append([]string{"-o", format}, args...)
+
+					localCode, localStdout, localStderr := run(t, env, args...)
+					require.Equal(t, ExitOK, localCode, localStderr)
+
+					remoteCode, remoteStdout, remoteStderr := run(t, remote, args...)
+					require.Equal(t, ExitOK, remoteCode, remoteStderr)
+
+					assert.Equal(t, localStdout, remoteStdout)
+				},
+			)
+		}
+	}
+
+	code, _, stderr := run(t, remote, "user", "9")
+	assert.Equal(t, ExitError, code)
+	assert.Equal(t, "surfctl user: GET /api/v1/users/9: 404 Not Found: Resource not found\n", stderr)
+}
+
+func Test_Run_report(t *testing.T) {
+	env := writeDatasets(t)
+	out := t.TempDir()
+
+	code, stdout, stderr := run(t, env, "-o", "json", "report", "-out", out, "-funnel", "VIEW_CONTACTS, REFER_USER")
+	require.Equal(t, ExitOK, code, stderr)
+
+	var got ReportResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &got))
+
+	assert.Equal(t, out, filepath.Dir(got.Directory))
+	assert.Equal(t, 3, got.Datasets.Users)
+	assert.Equal(t, 5, got.Datasets.Actions)
+
+	for _, name := range got.Files {
+		assert.FileExists(t, filepath.Join(got.Directory, name))
+	}
+
+	funnel, err := os.ReadFile(filepath.Join(got.Directory, report.FileFunnel))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"step,users,fromPrevious,fromFirstStep,medianDuration\n"+
+			"SIGNUP,3,1.0000,1.0000,\nVIEW_CONTACTS,2,0.6667,0.6667,744h0m0s\nREFER_USER,2,1.0000,0.6667,24h0m0s\n",
+		string(funnel),
+	)
+}
+
+func Test_Run_validate(t *testing.T) {
+	env := writeDatasets(t)
+	require.NoError(
+		t, os.WriteFile(
+			env[envActionsFile],
+			[]byte(`[
+{"id":1,"type":"REFER_USER","userId":1,"createdAt":"2021-02-01T00:00:00Z"},
+{"id":1,"type":"CHECKOUT","userId":4,"createdAt":"2021-02-02T00:00:00Z"}
+]`), 0o600,
+		),
+	)
+
+	code, stdout, stderr := run(t, env, "-o", "csv", "validate", "-types", "REFER_USER, VIEW_CONTACTS")
+
+	assert.Equal(t, ExitInvalid, code, stderr)
+	assert.Equal(
+		t,
+		"file,record,line,offset,id,code,message\n"+
+			"actions,0,2,2,1,missing_target_user,REFER_USER action without targetUser\n"+
+			"actions,1,3,78,1,duplicate_id,action ID 1 already used by record 0\n"+
+			"actions,1,3,78,1,unknown_action_type,\"unknown action type \"\"CHECKOUT\"\"\"\n"+
+			"actions,1,3,78,1,unknown_user,userId 4 does not match any user\n",
+		stdout,
+	)
+
+	env[envActionsFile] = filepath.Join(t.TempDir(), "missing.json")
+
+	code, _, stderr = run(t, env, "validate")
+	assert.Equal(t, ExitError, code, "should fail apart from the issues found")
+	assert.Contains(t, stderr, "surfctl validate: failed to load actions")
+}
+
+func Test_Run_generate(t *testing.T) {
+	env := writeDatasets(t)
+	out := filepath.Join(t.TempDir(), "data")
+
+	code, stdout, stderr := run(t, env, "-o", "json", "generate", "-users", "50", "-actions", "400", "-seed", "3", "-out", out)
+	require.Equal(t, ExitOK, code, stderr)
+
+	var got GenerateResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &got))
+
+	assert.Equal(t, filepath.Join(out, "users.json"), got.UsersFile)
+	assert.Equal(t, 50, got.Users)
+	assert.Positive(t, got.Actions)
+
+	generated := map[string]string{envUsersFile: got.UsersFile, envActionsFile: got.ActionsFile}
+
+	code, stdout, stderr = run(t, generated, "validate")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, fmt.Sprintf("50 users and %d actions checked, 0 issues found", got.Actions))
+
+	entries, err := os.ReadDir(out)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "the temporary files are renamed")
+}
+
+func Test_leaderboard(t *testing.T) {
+	got := leaderboard(
+		[]actiondto.Referral{
+			{UserID: 1, Referrals: 3}, {UserID: 2, Referrals: 5}, {UserID: 3, Referrals: 3},
+			{UserID: 4, Referrals: 0}, {UserID: 5, Referrals: 1},
+		}, 4,
+	)
+
+	assert.Equal(
+		t, []LeaderboardEntry{
+			{Rank: 1, UserID: 2, Referrals: 5},
+			{Rank: 2, UserID: 1, Referrals: 3},
+			{Rank: 2, UserID: 3, Referrals: 3},
+			{Rank: 4, UserID: 5, Referrals: 1},
+		}, got,
+	)
+}
+
+func Test_write_csv(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := write(
+		&buf, FormatCSV, result{
+			columns: []string{"id", "name"},
+			rows:    [][]string{{"1", "=HYPERLINK(\"http://evil\")"}, {"-2", "@SUM(A1)"}},
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "id,name\n1,\"'=HYPERLINK(\"\"http://evil\"\")\"\n-2,'@SUM(A1)\n", buf.String(), "should escape formulas")
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action"
+	actionstorage "surf_challenge/internal/action/storage"
+	actiondto "surf_challenge/internal/api/action/dto"
+	actionmapper "surf_challenge/internal/api/action/mapper"
+	userdto "surf_challenge/internal/api/user/dto"
+	usermapper "surf_challenge/internal/api/user/mapper"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/user/domain"
+	"surf_challenge/internal/user/storage"
+)
+
+// Client reads the datasets, either from a running server or from the data files.
+// Both return the API representations so that every command prints the same in
+// either mode.
+type Client interface {
+	GetUser(ctx context.Context, id int64) (userdto.User, error)
+	ListUsers(ctx context.Context, page, pageSize int) (*userdto.UsersResponse, error)
+	GetUserActionCount(ctx context.Context, id int64) (userdto.ActionsCount, error)
+	// GetNextActionProbability returns the actions following next, most likely first.
+	GetNextActionProbability(ctx context.Context, next string) ([]actiondto.ActionProbability, error)
+	// GetReferrals returns the referral index in ascending user ID order.
+	GetReferrals(ctx context.Context) ([]actiondto.Referral, error)
+}
+
+type localClient struct {
+	users   user.Service
+	actions action.Service
+}
+
+// NewLocalClient returns a client running the services over the given data files,
+// the embedded datasets being used for empty paths.
+func NewLocalClient(logger *zap.SugaredLogger, usersFile, actionsFile string) Client {
+	actionService := action.NewService(logger, actionstorage.NewRepository(actionsFile))
+
+	return &localClient{
+		users:   user.NewService(logger, storage.NewRepository(usersFile), actionService),
+		actions: actionService,
+	}
+}
+
+func (c *localClient) GetUser(ctx context.Context, id int64) (userdto.User, error) {
+	u, err := c.users.GetUserByID(ctx, id)
+	if err != nil {
+		return userdto.User{}, userError(id, err)
+	}
+
+	return usermapper.MapUserToDTO(u), nil
+}
+
+func (c *localClient) ListUsers(ctx context.Context, page, pageSize int) (*userdto.UsersResponse, error) {
+	users, results, err := c.users.QueryUsers(ctx, domain.Query{Page: page, PageSize: pageSize})
+	if err != nil {
+		return nil, fmt.Errorf("querying users: %w", err)
+	}
+
+	return &userdto.UsersResponse{
+		Users:      usermapper.MapUsersToDTO(users),
+		Pagination: usermapper.MapPaginationToDTO(results, page, pageSize),
+	}, nil
+}
+
+func (c *localClient) GetUserActionCount(ctx context.Context, id int64) (userdto.ActionsCount, error) {
+	count, err := c.users.GetUserActionCount(ctx, id)
+	if err != nil {
+		return userdto.ActionsCount{}, userError(id, err)
+	}
+
+	return userdto.ActionsCount{Count: count}, nil
+}
+
+func (c *localClient) GetNextActionProbability(ctx context.Context, next string) ([]actiondto.ActionProbability, error) {
+	probability, err := c.actions.GetNextActionProbability(ctx, next)
+	if err != nil {
+		return nil, fmt.Errorf("computing next action probability: %w", err)
+	}
+
+	resp, err := actionmapper.MapProbabilityToDTO(probability)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Rows(), nil
+}
+
+func (c *localClient) GetReferrals(ctx context.Context) ([]actiondto.Referral, error) {
+	referrals, err := c.actions.GetUsersReferrals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("computing referrals: %w", err)
+	}
+
+	rows := make([]actiondto.Referral, 0, len(referrals))
+	for userID, count := range referrals {
+		rows = append(rows, actiondto.Referral{UserID: userID, Referrals: count})
+	}
+
+	slices.SortFunc(
+		rows, func(a, b actiondto.Referral) int {
+			return a.UserID - b.UserID
+		},
+	)
+
+	return rows, nil
+}
+
+func userError(id int64, err error) error {
+	if errors.Is(err, user.ErrNotFound) {
+		return fmt.Errorf("user %d not found", id)
+	}
+
+	return fmt.Errorf("getting user %d: %w", id, err)
+}
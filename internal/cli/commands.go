@@ -0,0 +1,505 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	actiondomain "surf_challenge/internal/action/domain"
+	actionstorage "surf_challenge/internal/action/storage"
+	actiondto "surf_challenge/internal/api/action/dto"
+	userdto "surf_challenge/internal/api/user/dto"
+	"surf_challenge/internal/generate"
+	"surf_challenge/internal/report"
+	userstorage "surf_challenge/internal/user/storage"
+	"surf_challenge/internal/validation"
+)
+
+// LeaderboardEntry is a row of the leaderboard command.
+type LeaderboardEntry struct {
+	Rank      int `json:"rank"`
+	UserID    int `json:"userId"`
+	Referrals int `json:"referrals"`
+}
+
+func runUser(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	id, err := parseUserID(flags, args)
+	if err != nil {
+		return result{}, err
+	}
+
+	client, err := env.client()
+	if err != nil {
+		return result{}, err
+	}
+
+	u, err := client.GetUser(ctx, id)
+	if err != nil {
+		return result{}, err
+	}
+
+	return result{value: u, columns: userdto.UserColumns, rows: [][]string{u.Record()}}, nil
+}
+
+func runUsers(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	page := flags.Int("page", 1, "page number, from 1")
+	pageSize := flags.Int("page-size", 10, "users per page")
+
+	err := parse(flags, args, 0)
+	if err != nil {
+		return result{}, err
+	}
+
+	if *page < 1 || *pageSize < 1 {
+		return result{}, usageError(flags, "page and page-size must be positive")
+	}
+
+	client, err := env.client()
+	if err != nil {
+		return result{}, err
+	}
+
+	resp, err := client.ListUsers(ctx, *page, *pageSize)
+	if err != nil {
+		return result{}, err
+	}
+
+	rows := make([][]string, 0, len(resp.Users))
+	for _, u := range resp.Users {
+		rows = append(rows, u.Record())
+	}
+
+	return result{
+		value:   resp,
+		columns: userdto.UserColumns,
+		rows:    rows,
+		footer: fmt.Sprintf(
+			"page %d of %d, %d users", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.TotalItems,
+		),
+	}, nil
+}
+
+func runActionCount(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	id, err := parseUserID(flags, args)
+	if err != nil {
+		return result{}, err
+	}
+
+	client, err := env.client()
+	if err != nil {
+		return result{}, err
+	}
+
+	count, err := client.GetUserActionCount(ctx, id)
+	if err != nil {
+		return result{}, err
+	}
+
+	return result{value: count, columns: []string{"count"}, rows: [][]string{{strconv.Itoa(count.Count)}}}, nil
+}
+
+func runNextProbability(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	err := parse(flags, args, 1)
+	if err != nil {
+		return result{}, err
+	}
+
+	client, err := env.client()
+	if err != nil {
+		return result{}, err
+	}
+
+	probabilities, err := client.GetNextActionProbability(ctx, flags.Arg(0))
+	if err != nil {
+		return result{}, err
+	}
+
+	rows := make([][]string, 0, len(probabilities))
+	for _, p := range probabilities {
+		rows = append(rows, p.Record())
+	}
+
+	return result{value: probabilities, columns: actiondto.ProbabilityColumns, rows: rows}, nil
+}
+
+func runReferrals(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	err := parse(flags, args, 0)
+	if err != nil {
+		return result{}, err
+	}
+
+	client, err := env.client()
+	if err != nil {
+		return result{}, err
+	}
+
+	referrals, err := client.GetReferrals(ctx)
+	if err != nil {
+		return result{}, err
+	}
+
+	rows := make([][]string, 0, len(referrals))
+	for _, r := range referrals {
+		rows = append(rows, r.Record())
+	}
+
+	return result{value: referrals, columns: actiondto.ReferralColumns, rows: rows}, nil
+}
+
+func runLeaderboard(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	limit := flags.Int("limit", 10, "number of users listed")
+
+	err := parse(flags, args, 0)
+	if err != nil {
+		return result{}, err
+	}
+
+	if *limit < 1 {
+		return result{}, usageError(flags, "limit must be positive")
+	}
+
+	client, err := env.client()
+	if err != nil {
+		return result{}, err
+	}
+
+	referrals, err := client.GetReferrals(ctx)
+	if err != nil {
+		return result{}, err
+	}
+
+	entries := leaderboard(referrals, *limit)
+
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, []string{strconv.Itoa(e.Rank), strconv.Itoa(e.UserID), strconv.Itoa(e.Referrals)})
+	}
+
+	return result{value: entries, columns: []string{"rank", "userId", "referrals"}, rows: rows}, nil
+}
+
+// leaderboard ranks the users with referrals by decreasing count, ties sharing a rank
+// and being listed by user ID.
+func leaderboard(referrals []actiondto.Referral, limit int) []LeaderboardEntry {
+	sorted := slices.DeleteFunc(
+		slices.Clone(referrals), func(r actiondto.Referral) bool {
+			return r.Referrals == 0
+		},
+	)
+
+	slices.SortFunc(
+		sorted, func(a, b actiondto.Referral) int {
+			return cmp.Or(b.Referrals-a.Referrals, a.UserID-b.UserID)
+		},
+	)
+
+	entries := make([]LeaderboardEntry, 0, min(limit, len(sorted)))
+
+	for i, r := range sorted[:min(limit, len(sorted))] {
+		rank := i + 1
+		if i > 0 && r.Referrals == entries[i-1].Referrals {
+			rank = entries[i-1].Rank
+		}
+
+		entries = append(entries, LeaderboardEntry{Rank: rank, UserID: r.UserID, Referrals: r.Referrals})
+	}
+
+	return entries
+}
+
+// ReportResult is the output of the report command: the directory written and its
+// manifest.
+type ReportResult struct {
+	Directory string `json:"directory"`
+	*report.Manifest
+}
+
+func runReport(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	out := flags.String("out", "reports", "directory receiving the report directories")
+	funnel := flags.String("funnel", strings.Join(report.DefaultFunnel, ","), "comma-separated action types of the funnel steps")
+	periods := flags.Int("retention-periods", 6, "months after signup measured per retention cohort")
+
+	err := parse(flags, args, 0)
+	if err != nil {
+		return result{}, err
+	}
+
+	if env.opts.server != "" {
+		return result{}, usageError(flags, "report reads the data files, -server is not supported")
+	}
+
+	opts := report.Options{
+		GeneratedAt:      time.Now().UTC(),
+		Funnel:           splitList(*funnel),
+		RetentionPeriods: *periods,
+	}
+
+	if len(opts.Funnel) == 0 || opts.RetentionPeriods < 1 {
+		return result{}, usageError(flags, "funnel must list an action type and retention-periods must be positive")
+	}
+
+	r, err := report.New(
+		ctx, zap.NewNop().Sugar(),
+		userstorage.NewRepository(env.opts.usersFile), actionstorage.NewRepository(env.opts.actionsFile), opts,
+	)
+	if err != nil {
+		return result{}, err
+	}
+
+	dir, manifest, err := report.Write(*out, r)
+	if err != nil {
+		return result{}, err
+	}
+
+	rows := make([][]string, 0, len(manifest.Files))
+	for _, f := range manifest.Files {
+		rows = append(rows, []string{filepath.Join(dir, f)})
+	}
+
+	return result{
+		value:   ReportResult{Directory: dir, Manifest: manifest},
+		columns: []string{"file"},
+		rows:    rows,
+		footer:  fmt.Sprintf("report of %d users and %d actions written to %s", r.Datasets.Users, r.Datasets.Actions, dir),
+	}, nil
+}
+
+func runValidate(_ context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	types := flags.String("types", strings.Join(actiondomain.ActionTypes, ","), "comma-separated known action types")
+	maxIssues := flags.Int("max-issues", validation.DefaultMaxIssues, "issues listed, all of them being counted")
+
+	err := parse(flags, args, 0)
+	if err != nil {
+		return result{}, err
+	}
+
+	if env.opts.server != "" {
+		return result{}, usageError(flags, "validate reads the data files, -server is not supported")
+	}
+
+	if *maxIssues < 1 {
+		return result{}, usageError(flags, "max-issues must be positive")
+	}
+
+	users, err := userstorage.Open(env.opts.usersFile)
+	if err != nil {
+		return result{}, err
+	}
+
+	defer func() {
+		_ = users.Close()
+	}()
+
+	actions, err := actionstorage.Open(env.opts.actionsFile)
+	if err != nil {
+		return result{}, err
+	}
+
+	defer func() {
+		_ = actions.Close()
+	}()
+
+	r, err := validation.Validate(users, actions, validation.Options{ActionTypes: splitList(*types), MaxIssues: *maxIssues})
+	if err != nil {
+		return result{}, err
+	}
+
+	rows := make([][]string, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		id := ""
+		if issue.ID != nil {
+			id = strconv.FormatInt(*issue.ID, 10)
+		}
+
+		rows = append(
+			rows, []string{
+				issue.File, strconv.Itoa(issue.Record), strconv.Itoa(issue.Line), strconv.FormatInt(issue.Offset, 10), id, issue.Code,
+				issue.Message,
+			},
+		)
+	}
+
+	footer := fmt.Sprintf(
+		"%d users and %d actions checked, %d issues found", r.Users.Records, r.Actions.Records, r.Users.Issues+r.Actions.Issues,
+	)
+	if r.Truncated {
+		footer += fmt.Sprintf(", the first %d listed", len(r.Issues))
+	}
+
+	code := ExitOK
+	if !r.Valid {
+		code = ExitInvalid
+	}
+
+	return result{
+		value:   r,
+		columns: []string{"file", "record", "line", "offset", "id", "code", "message"},
+		rows:    rows,
+		footer:  footer,
+		code:    code,
+	}, nil
+}
+
+// GenerateResult is the output of the generate command: the files written and what
+// they hold.
+type GenerateResult struct {
+	UsersFile   string `json:"usersFile"`
+	ActionsFile string `json:"actionsFile"`
+	Seed        uint64 `json:"seed"`
+	generate.Stats
+}
+
+func runGenerate(ctx context.Context, env *environment, flags *flag.FlagSet, args []string) (result, error) {
+	users := flags.Int("users", 0, "users generated")
+	actions := flags.Int("actions", 0, "actions aimed at, the mean per user of the data files being kept when 0")
+	seed := flags.Uint64("seed", 1, "seed of the random generator, the same seed giving the same files")
+	start := flags.String("start", "", "RFC 3339 time of the first signup, the first one of the data files when empty")
+	end := flags.String("end", "", "RFC 3339 time of the last action, the last one of the data files when empty")
+	out := flags.String("out", ".", "directory receiving users.json and actions.json")
+
+	err := parse(flags, args, 0)
+	if err != nil {
+		return result{}, err
+	}
+
+	if env.opts.server != "" {
+		return result{}, usageError(flags, "generate reads the data files, -server is not supported")
+	}
+
+	opts := generate.Options{Seed: *seed, Users: *users, Actions: *actions}
+
+	if opts.Users < 1 || opts.Actions < 0 {
+		return result{}, usageError(flags, "users must be positive and actions must not be negative")
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value string
+		time  *time.Time
+	}{{"start", *start, &opts.Start}, {"end", *end, &opts.End}} {
+		if bound.value == "" {
+			continue
+		}
+
+		*bound.time, err = time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return result{}, usageError(flags, "invalid %s %q, expected an RFC 3339 time", bound.name, bound.value)
+		}
+	}
+
+	model, err := generate.LoadModel(
+		ctx, zap.NewNop().Sugar(), userstorage.NewRepository(env.opts.usersFile), actionstorage.NewRepository(env.opts.actionsFile),
+	)
+	if err != nil {
+		return result{}, err
+	}
+
+	res := GenerateResult{UsersFile: filepath.Join(*out, "users.json"), ActionsFile: filepath.Join(*out, "actions.json"), Seed: *seed}
+
+	res.Stats, err = writeGenerated(model, opts, res.UsersFile, res.ActionsFile)
+	if err != nil {
+		return result{}, err
+	}
+
+	return result{
+		value:   res,
+		columns: []string{"file", "records"},
+		rows: [][]string{
+			{res.UsersFile, strconv.Itoa(res.Users)},
+			{res.ActionsFile, strconv.Itoa(res.Actions)},
+		},
+		footer: fmt.Sprintf("%d users referred, seed %d", res.Referrals, res.Seed),
+	}, nil
+}
+
+// writeGenerated generates the datasets into temporary files renamed once complete.
+func writeGenerated(model *generate.Model, opts generate.Options, usersPath, actionsPath string) (generate.Stats, error) {
+	err := os.MkdirAll(filepath.Dir(usersPath), 0o755)
+	if err != nil {
+		return generate.Stats{}, err
+	}
+
+	usersFile, err := os.CreateTemp(filepath.Dir(usersPath), ".users-*.json")
+	if err != nil {
+		return generate.Stats{}, err
+	}
+
+	defer func() {
+		_ = usersFile.Close()
+		_ = os.Remove(usersFile.Name())
+	}()
+
+	actionsFile, err := os.CreateTemp(filepath.Dir(actionsPath), ".actions-*.json")
+	if err != nil {
+		return generate.Stats{}, err
+	}
+
+	defer func() {
+		_ = actionsFile.Close()
+		_ = os.Remove(actionsFile.Name())
+	}()
+
+	stats, err := generate.Generate(model, opts, usersFile, actionsFile)
+	if err != nil {
+		return generate.Stats{}, err
+	}
+
+	for _, f := range []struct {
+		file *os.File
+		path string
+	}{{usersFile, usersPath}, {actionsFile, actionsPath}} {
+		err = f.file.Close()
+		if err != nil {
+			return generate.Stats{}, err
+		}
+
+		err = os.Chmod(f.file.Name(), 0o644)
+		if err != nil {
+			return generate.Stats{}, err
+		}
+
+		err = os.Rename(f.file.Name(), f.path)
+		if err != nil {
+			return generate.Stats{}, err
+		}
+	}
+
+	return stats, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func parseUserID(flags *flag.FlagSet, args []string) (int64, error) {
+	err := parse(flags, args, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+	if err != nil || id < 1 {
+		return 0, usageError(flags, "invalid user ID %q", flags.Arg(0))
+	}
+
+	return id, nil
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	actiondto "surf_challenge/internal/api/action/dto"
+	"surf_challenge/internal/api/apierror"
+	"surf_challenge/internal/api/response"
+	userdto "surf_challenge/internal/api/user/dto"
+	"surf_challenge/internal/auth"
+)
+
+type httpClient struct {
+	base   *url.URL
+	apiKey string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPClient returns a client of the REST API served at server, authenticating
+// with apiKey or the bearer token when set.
+func NewHTTPClient(server, apiKey, token string, timeout time.Duration) (Client, error) {
+	base, err := url.Parse(server)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", server)
+	}
+
+	return &httpClient{
+		base:   base,
+		apiKey: apiKey,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (c *httpClient) GetUser(ctx context.Context, id int64) (userdto.User, error) {
+	var u userdto.User
+
+	err := c.getJSON(ctx, "/api/v1/users/"+strconv.FormatInt(id, 10), nil, &u)
+
+	return u, err
+}
+
+func (c *httpClient) ListUsers(ctx context.Context, page, pageSize int) (*userdto.UsersResponse, error) {
+	query := url.Values{"page": {strconv.Itoa(page)}, "pageSize": {strconv.Itoa(pageSize)}}
+
+	var resp userdto.UsersResponse
+
+	err := c.getJSON(ctx, "/api/v1/users", query, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *httpClient) GetUserActionCount(ctx context.Context, id int64) (userdto.ActionsCount, error) {
+	var count userdto.ActionsCount
+
+	err := c.getJSON(ctx, "/api/v1/users/"+strconv.FormatInt(id, 10)+"/actions/count", nil, &count)
+
+	return count, err
+}
+
+// GetNextActionProbability reads the NDJSON representation, which keeps the order of
+// the actions.
+func (c *httpClient) GetNextActionProbability(ctx context.Context, next string) ([]actiondto.ActionProbability, error) {
+	body, err := c.get(ctx, "/api/v1/actions/next-probability", url.Values{"next": {next}}, response.ContentTypeNDJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	return decodeNDJSON[actiondto.ActionProbability](body)
+}
+
+func (c *httpClient) GetReferrals(ctx context.Context) ([]actiondto.Referral, error) {
+	body, err := c.get(ctx, "/api/v1/actions/referrals", nil, response.ContentTypeNDJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	return decodeNDJSON[actiondto.Referral](body)
+}
+
+func (c *httpClient) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	body, err := c.get(ctx, path, query, response.ContentTypeJSON)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	err = json.NewDecoder(body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// get requests path and returns the body of a successful response. Problem responses
+// are turned into errors carrying their detail.
+func (c *httpClient) get(ctx context.Context, path string, query url.Values, accept string) (io.ReadCloser, error) {
+	u := c.base.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", accept)
+
+	if c.apiKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, c.apiKey)
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.Body, nil
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var problem apierror.Problem
+
+	detail := resp.Status
+	if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&problem) == nil && problem.Detail != "" {
+		detail = fmt.Sprintf("%s: %s", resp.Status, problem.Detail)
+	}
+
+	return nil, fmt.Errorf("GET %s: %s", path, detail)
+}
+
+func decodeNDJSON[T any](r io.Reader) ([]T, error) {
+	rows := []T{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var row T
+
+		err := json.Unmarshal([]byte(line), &row)
+		if err != nil {
+			return nil, fmt.Errorf("decoding NDJSON line %q: %w", line, err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"surf_challenge/internal/api/response"
+)
+
+// Output formats.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// result is what a command prints: value as JSON, columns and rows as a table or CSV.
+// The footer is only printed below tables. code is the exit code once printed.
+type result struct {
+	value   any
+	columns []string
+	rows    [][]string
+	footer  string
+	code    int
+}
+
+func write(w io.Writer, format string, r result) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(r.value)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+
+		err := cw.Write(r.columns)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range r.rows {
+			err = cw.Write(response.EscapeFormulas(row))
+			if err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+
+		return cw.Error()
+	default:
+		return writeTable(w, r)
+	}
+}
+
+func writeTable(w io.Writer, r result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		header[i] = strings.ToUpper(column)
+	}
+
+	_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range r.rows {
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	if r.footer != "" {
+		_, err = fmt.Fprintln(w, r.footer)
+	}
+
+	return err
+}
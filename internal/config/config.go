@@ -0,0 +1,548 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const (
+	LogFormatJSON    = "json"
+	LogFormatConsole = "console"
+
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+
+	maxPort = 65535
+
+	minCompressionLevel = 1
+	maxCompressionLevel = 9
+)
+
+var (
+	logLevels        = []string{"debug", "info", "warn", "error"}
+	logFormats       = []string{LogFormatJSON, LogFormatConsole}
+	tracingExporters = []string{TracingExporterStdout, TracingExporterFile}
+
+	sha256Hex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+)
+
+// Config holds every runtime setting of the server. Values are resolved, from lowest
+// to highest precedence, from defaults, the config file, SURF_* env vars and CLI flags.
+type Config struct {
+	Server      Server      `yaml:"server"`
+	GRPC        GRPC        `yaml:"grpc"`
+	Log         Log         `yaml:"log"`
+	Data        Data        `yaml:"data"`
+	Cache       Cache       `yaml:"cache"`
+	Stream      Stream      `yaml:"stream"`
+	Webhooks    Webhooks    `yaml:"webhooks"`
+	Tracing     Tracing     `yaml:"tracing"`
+	Auth        Auth        `yaml:"auth"`
+	RateLimit   RateLimit   `yaml:"rate_limit"`
+	Compression Compression `yaml:"compression"`
+	Features    Features    `yaml:"features"`
+}
+
+type Server struct {
+	Port              int           `yaml:"port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`
+}
+
+// GRPC configures the gRPC server, listening next to the HTTP one.
+type GRPC struct {
+	Enabled bool `yaml:"enabled"`
+	Port    int  `yaml:"port"`
+	// Reflection lets clients such as grpcurl discover the services.
+	Reflection bool `yaml:"reflection"`
+}
+
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Data points to the JSON datasets. Empty paths use the datasets embedded in the binary.
+type Data struct {
+	UsersFile   string `yaml:"users_file"`
+	ActionsFile string `yaml:"actions_file"`
+}
+
+type Cache struct {
+	Enabled    bool          `yaml:"enabled"`
+	TTL        time.Duration `yaml:"ttl"`
+	MaxEntries int           `yaml:"max_entries"`
+}
+
+// Stream configures the live feed of ingested actions: the actions dataset is polled
+// for changes and the last buffer_size actions are kept for clients to resume from.
+type Stream struct {
+	Enabled      bool          `yaml:"enabled"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BufferSize   int           `yaml:"buffer_size"`
+	Heartbeat    time.Duration `yaml:"heartbeat"`
+}
+
+// Webhooks configures the delivery of action and referral events to the endpoints
+// registered through the admin API. Events are derived from the stream feed, polled and
+// buffered as the stream settings say even when the stream itself is disabled.
+type Webhooks struct {
+	Enabled bool `yaml:"enabled"`
+	// File persists the registered endpoints, kept in memory only when empty.
+	File           string        `yaml:"file"`
+	Workers        int           `yaml:"workers"`
+	Timeout        time.Duration `yaml:"timeout"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// DeliveryLogSize bounds both the delivery log and the dead-letter list.
+	DeliveryLogSize int `yaml:"delivery_log_size"`
+	// ReferralMilestones are the referral counts announced by referral.milestone events.
+	ReferralMilestones []int `yaml:"referral_milestones"`
+	// AllowPrivateHosts accepts endpoints on loopback, link-local and private addresses,
+	// for local testing only.
+	AllowPrivateHosts bool `yaml:"allow_private_hosts"`
+}
+
+// Tracing configures OpenTelemetry spans. They are exported locally, to stdout by
+// default or to a file, so no collector is required.
+type Tracing struct {
+	Enabled     bool    `yaml:"enabled"`
+	Exporter    string  `yaml:"exporter"`
+	File        string  `yaml:"file"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Compression negotiates brotli or gzip response encoding with clients.
+type Compression struct {
+	Enabled bool `yaml:"enabled"`
+	// Level trades speed (1) for size (9).
+	Level int `yaml:"level"`
+}
+
+// RateLimit bounds the load each client can put on /api/v1: a token bucket per API
+// key, token user or remote IP, and a concurrency gate on the analytics endpoints.
+type RateLimit struct {
+	Enabled               bool          `yaml:"enabled"`
+	RequestsPerSecond     float64       `yaml:"requests_per_second"`
+	Burst                 int           `yaml:"burst"`
+	MaxClients            int           `yaml:"max_clients"`
+	AnalyticsConcurrency  int           `yaml:"analytics_concurrency"`
+	AnalyticsQueueTimeout time.Duration `yaml:"analytics_queue_timeout"`
+}
+
+// Auth protects the /api/v1 routes with API keys, JWTs or both. API keys are only read
+// from the config file since they do not fit a single env var or flag.
+type Auth struct {
+	Enabled bool     `yaml:"enabled"`
+	APIKeys []APIKey `yaml:"api_keys"`
+	JWT     JWT      `yaml:"jwt"`
+}
+
+// APIKey stores the hex encoded SHA-256 of a key, never the key itself.
+type APIKey struct {
+	Name   string   `yaml:"name"`
+	SHA256 string   `yaml:"sha256"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// JWT validates bearer tokens against local keys: a shared HMAC secret, an RSA public
+// key in PEM and/or a JWKS document.
+type JWT struct {
+	Enabled          bool          `yaml:"enabled"`
+	Issuer           string        `yaml:"issuer"`
+	Audience         string        `yaml:"audience"`
+	HMACSecretFile   string        `yaml:"hmac_secret_file"`
+	RSAPublicKeyFile string        `yaml:"rsa_public_key_file"`
+	JWKSFile         string        `yaml:"jwks_file"`
+	ScopeClaim       string        `yaml:"scope_claim"`
+	UserIDClaim      string        `yaml:"user_id_claim"`
+	Leeway           time.Duration `yaml:"leeway"`
+}
+
+type Features struct {
+	// Analytics exposes the /actions analytics endpoints.
+	Analytics bool `yaml:"analytics"`
+	// Metrics exposes Prometheus metrics at /metrics.
+	Metrics bool `yaml:"metrics"`
+	// Docs exposes the OpenAPI document at /openapi.json and Swagger UI at /docs.
+	Docs bool `yaml:"docs"`
+	// RequestValidation rejects /api/v1 requests not matching the OpenAPI document.
+	RequestValidation bool `yaml:"request_validation"`
+	// GraphQL exposes the GraphQL endpoint at /graphql.
+	GraphQL bool `yaml:"graphql"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:              3000,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+		},
+		GRPC: GRPC{
+			Port: 50051,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: LogFormatJSON,
+		},
+		Cache: Cache{
+			Enabled:    true,
+			TTL:        5 * time.Minute,
+			MaxEntries: 1024,
+		},
+		Stream: Stream{
+			Enabled:      true,
+			PollInterval: time.Second,
+			BufferSize:   1000,
+			Heartbeat:    15 * time.Second,
+		},
+		Webhooks: Webhooks{
+			Enabled:            false,
+			Workers:            4,
+			Timeout:            10 * time.Second,
+			MaxAttempts:        5,
+			InitialBackoff:     time.Second,
+			MaxBackoff:         5 * time.Minute,
+			DeliveryLogSize:    1000,
+			ReferralMilestones: []int{5, 10, 25, 50, 100},
+		},
+		Tracing: Tracing{
+			Enabled:     true,
+			Exporter:    TracingExporterStdout,
+			SampleRatio: 1,
+		},
+		Auth: Auth{
+			JWT: JWT{
+				ScopeClaim:  "scope",
+				UserIDClaim: "sub",
+				Leeway:      30 * time.Second,
+			},
+		},
+		RateLimit: RateLimit{
+			Enabled:               true,
+			RequestsPerSecond:     20,
+			Burst:                 40,
+			MaxClients:            10000,
+			AnalyticsConcurrency:  4,
+			AnalyticsQueueTimeout: time.Second,
+		},
+		Compression: Compression{
+			Enabled: true,
+			Level:   5,
+		},
+		Features: Features{
+			Analytics:         true,
+			Metrics:           true,
+			Docs:              true,
+			RequestValidation: true,
+			GraphQL:           true,
+		},
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port < 1 || c.Server.Port > maxPort {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and %d, got %d", maxPort, c.Server.Port))
+	}
+
+	timeouts := map[string]time.Duration{
+		"server.read_timeout":        c.Server.ReadTimeout,
+		"server.read_header_timeout": c.Server.ReadHeaderTimeout,
+		"server.write_timeout":       c.Server.WriteTimeout,
+		"server.idle_timeout":        c.Server.IdleTimeout,
+		"server.shutdown_timeout":    c.Server.ShutdownTimeout,
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(timeouts)) {
+		if timeouts[key] <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", key, timeouts[key]))
+		}
+	}
+
+	if c.GRPC.Enabled {
+		errs = append(errs, c.GRPC.validate(c.Server.Port))
+	}
+
+	if c.Server.MaxHeaderBytes <= 0 {
+		errs = append(errs, fmt.Errorf("server.max_header_bytes must be positive, got %d", c.Server.MaxHeaderBytes))
+	}
+
+	if !slices.Contains(logLevels, c.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level must be one of %v, got %q", logLevels, c.Log.Level))
+	}
+
+	if !slices.Contains(logFormats, c.Log.Format) {
+		errs = append(errs, fmt.Errorf("log.format must be one of %v, got %q", logFormats, c.Log.Format))
+	}
+
+	errs = append(errs, validateFile("data.users_file", c.Data.UsersFile))
+	errs = append(errs, validateFile("data.actions_file", c.Data.ActionsFile))
+
+	if c.Cache.Enabled {
+		if c.Cache.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("cache.ttl must be positive when cache is enabled, got %s", c.Cache.TTL))
+		}
+
+		if c.Cache.MaxEntries <= 0 {
+			errs = append(errs, fmt.Errorf("cache.max_entries must be positive when cache is enabled, got %d", c.Cache.MaxEntries))
+		}
+	}
+
+	if c.Stream.Enabled || c.Webhooks.Enabled {
+		errs = append(errs, c.Stream.validate())
+	}
+
+	if c.Webhooks.Enabled {
+		errs = append(errs, c.Webhooks.validate())
+	}
+
+	if c.Tracing.Enabled {
+		errs = append(errs, c.Tracing.validate())
+	}
+
+	if c.Auth.Enabled {
+		errs = append(errs, c.Auth.validate())
+	}
+
+	if c.RateLimit.Enabled {
+		errs = append(errs, c.RateLimit.validate())
+	}
+
+	if c.Compression.Enabled && (c.Compression.Level < minCompressionLevel || c.Compression.Level > maxCompressionLevel) {
+		errs = append(
+			errs, fmt.Errorf(
+				"compression.level must be between %d and %d, got %d",
+				minCompressionLevel, maxCompressionLevel, c.Compression.Level,
+			),
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (g GRPC) validate(httpPort int) error {
+	if g.Port < 1 || g.Port > maxPort {
+		return fmt.Errorf("grpc.port must be between 1 and %d, got %d", maxPort, g.Port)
+	}
+
+	if g.Port == httpPort {
+		return fmt.Errorf("grpc.port must differ from server.port, both are %d", g.Port)
+	}
+
+	return nil
+}
+
+// validate checks the feed settings, which webhooks rely on too. The heartbeat is only
+// checked when the stream is served.
+func (s Stream) validate() error {
+	var errs []error
+
+	reason := "stream is enabled"
+	if !s.Enabled {
+		reason = "webhooks are enabled"
+	}
+
+	intervals := map[string]time.Duration{
+		"stream.poll_interval": s.PollInterval,
+	}
+
+	if s.Enabled {
+		intervals["stream.heartbeat"] = s.Heartbeat
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(intervals)) {
+		if intervals[key] <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive when %s, got %s", key, reason, intervals[key]))
+		}
+	}
+
+	if s.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("stream.buffer_size must be positive when %s, got %d", reason, s.BufferSize))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w Webhooks) validate() error {
+	var errs []error
+
+	limits := map[string]int{
+		"webhooks.workers":           w.Workers,
+		"webhooks.max_attempts":      w.MaxAttempts,
+		"webhooks.delivery_log_size": w.DeliveryLogSize,
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(limits)) {
+		if limits[key] <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive when webhooks are enabled, got %d", key, limits[key]))
+		}
+	}
+
+	durations := map[string]time.Duration{
+		"webhooks.timeout":         w.Timeout,
+		"webhooks.initial_backoff": w.InitialBackoff,
+		"webhooks.max_backoff":     w.MaxBackoff,
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(durations)) {
+		if durations[key] <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive when webhooks are enabled, got %s", key, durations[key]))
+		}
+	}
+
+	if w.MaxBackoff < w.InitialBackoff {
+		errs = append(
+			errs, fmt.Errorf(
+				"webhooks.max_backoff must not be lower than webhooks.initial_backoff, got %s < %s",
+				w.MaxBackoff, w.InitialBackoff,
+			),
+		)
+	}
+
+	for i, milestone := range w.ReferralMilestones {
+		if milestone <= 0 {
+			errs = append(errs, fmt.Errorf("webhooks.referral_milestones[%d] must be positive, got %d", i, milestone))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t Tracing) validate() error {
+	var errs []error
+
+	if !slices.Contains(tracingExporters, t.Exporter) {
+		errs = append(errs, fmt.Errorf("tracing.exporter must be one of %v, got %q", tracingExporters, t.Exporter))
+	}
+
+	if t.Exporter == TracingExporterFile && t.File == "" {
+		errs = append(errs, errors.New("tracing.file is required when tracing.exporter is file"))
+	}
+
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got %g", t.SampleRatio))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r RateLimit) validate() error {
+	var errs []error
+
+	if r.RequestsPerSecond <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.requests_per_second must be positive, got %g", r.RequestsPerSecond))
+	}
+
+	limits := map[string]int{
+		"rate_limit.burst":                 r.Burst,
+		"rate_limit.max_clients":           r.MaxClients,
+		"rate_limit.analytics_concurrency": r.AnalyticsConcurrency,
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(limits)) {
+		if limits[key] <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %d", key, limits[key]))
+		}
+	}
+
+	if r.AnalyticsQueueTimeout < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.analytics_queue_timeout must not be negative, got %s", r.AnalyticsQueueTimeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a Auth) validate() error {
+	if len(a.APIKeys) == 0 && !a.JWT.Enabled {
+		return errors.New("auth.api_keys must not be empty when auth is enabled without auth.jwt")
+	}
+
+	var errs []error
+
+	if a.JWT.Enabled {
+		errs = append(errs, a.JWT.validate())
+	}
+
+	names := make(map[string]bool, len(a.APIKeys))
+
+	for i, key := range a.APIKeys {
+		if key.Name == "" {
+			errs = append(errs, fmt.Errorf("auth.api_keys[%d].name is required", i))
+		} else if names[key.Name] {
+			errs = append(errs, fmt.Errorf("auth.api_keys[%d].name %q is duplicated", i, key.Name))
+		}
+
+		names[key.Name] = true
+
+		if !sha256Hex.MatchString(key.SHA256) {
+			errs = append(errs, fmt.Errorf("auth.api_keys[%d].sha256 must be 64 hex characters", i))
+		}
+
+		if len(key.Scopes) == 0 {
+			errs = append(errs, fmt.Errorf("auth.api_keys[%d].scopes must not be empty", i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (j JWT) validate() error {
+	var errs []error
+
+	if j.HMACSecretFile == "" && j.RSAPublicKeyFile == "" && j.JWKSFile == "" {
+		errs = append(errs, errors.New("auth.jwt requires one of hmac_secret_file, rsa_public_key_file or jwks_file"))
+	}
+
+	errs = append(errs, validateFile("auth.jwt.hmac_secret_file", j.HMACSecretFile))
+	errs = append(errs, validateFile("auth.jwt.rsa_public_key_file", j.RSAPublicKeyFile))
+	errs = append(errs, validateFile("auth.jwt.jwks_file", j.JWKSFile))
+
+	if j.ScopeClaim == "" {
+		errs = append(errs, errors.New("auth.jwt.scope_claim must not be empty"))
+	}
+
+	if j.UserIDClaim == "" {
+		errs = append(errs, errors.New("auth.jwt.user_id_claim must not be empty"))
+	}
+
+	if j.Leeway < 0 {
+		errs = append(errs, fmt.Errorf("auth.jwt.leeway must not be negative, got %s", j.Leeway))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateFile(key, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("%s: %s is a directory", key, path)
+	}
+
+	return nil
+}
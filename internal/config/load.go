@@ -0,0 +1,244 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix     = "SURF_"
+	envConfigFile = envPrefix + "CONFIG"
+	flagConfig    = "config"
+)
+
+// LookupEnv matches os.LookupEnv so tests can provide their own environment.
+type LookupEnv func(key string) (string, bool)
+
+// setting binds a dotted config key to its field. The env var and flag names are
+// derived from the key: "server.read_timeout" is SURF_SERVER_READ_TIMEOUT and
+// -server-read-timeout.
+type setting struct {
+	key   string
+	usage string
+	field func(c *Config) any
+}
+
+var settings = []setting{
+	{"server.port", "HTTP listen port", func(c *Config) any { return &c.Server.Port }},
+	{"server.read_timeout", "HTTP read timeout", func(c *Config) any { return &c.Server.ReadTimeout }},
+	{"server.read_header_timeout", "HTTP read header timeout", func(c *Config) any { return &c.Server.ReadHeaderTimeout }},
+	{"server.write_timeout", "HTTP write timeout", func(c *Config) any { return &c.Server.WriteTimeout }},
+	{"server.idle_timeout", "HTTP idle timeout", func(c *Config) any { return &c.Server.IdleTimeout }},
+	{"server.shutdown_timeout", "graceful shutdown timeout", func(c *Config) any { return &c.Server.ShutdownTimeout }},
+	{"server.max_header_bytes", "maximum request header size", func(c *Config) any { return &c.Server.MaxHeaderBytes }},
+	{"grpc.enabled", "serve the gRPC API", func(c *Config) any { return &c.GRPC.Enabled }},
+	{"grpc.port", "gRPC listen port", func(c *Config) any { return &c.GRPC.Port }},
+	{"grpc.reflection", "enable gRPC server reflection", func(c *Config) any { return &c.GRPC.Reflection }},
+	{"log.level", "log level (debug, info, warn, error)", func(c *Config) any { return &c.Log.Level }},
+	{"log.format", "log format (json, console)", func(c *Config) any { return &c.Log.Format }},
+	{"data.users_file", "users JSON file, embedded dataset when empty", func(c *Config) any { return &c.Data.UsersFile }},
+	{"data.actions_file", "actions JSON file, embedded dataset when empty", func(c *Config) any { return &c.Data.ActionsFile }},
+	{"cache.enabled", "enable analytics result cache", func(c *Config) any { return &c.Cache.Enabled }},
+	{"cache.ttl", "analytics cache entry TTL", func(c *Config) any { return &c.Cache.TTL }},
+	{"cache.max_entries", "analytics cache size bound", func(c *Config) any { return &c.Cache.MaxEntries }},
+	{"stream.enabled", "stream ingested actions at /api/v1/actions/stream", func(c *Config) any { return &c.Stream.Enabled }},
+	{"stream.poll_interval", "actions dataset polling interval", func(c *Config) any { return &c.Stream.PollInterval }},
+	{"stream.buffer_size", "ingested actions kept for clients to resume from", func(c *Config) any { return &c.Stream.BufferSize }},
+	{"stream.heartbeat", "interval of keep-alive comments on idle streams", func(c *Config) any { return &c.Stream.Heartbeat }},
+	{"webhooks.enabled", "deliver events to the registered webhooks", func(c *Config) any { return &c.Webhooks.Enabled }},
+	{"webhooks.file", "file persisting the registered webhooks, in memory when empty", func(c *Config) any { return &c.Webhooks.File }},
+	{"webhooks.workers", "webhook deliveries sent at once", func(c *Config) any { return &c.Webhooks.Workers }},
+	{"webhooks.timeout", "webhook delivery attempt timeout", func(c *Config) any { return &c.Webhooks.Timeout }},
+	{"webhooks.max_attempts", "webhook delivery attempts before dead-lettering", func(c *Config) any { return &c.Webhooks.MaxAttempts }},
+	{"webhooks.initial_backoff", "wait before the first webhook retry, doubled every retry", func(c *Config) any { return &c.Webhooks.InitialBackoff }},
+	{"webhooks.max_backoff", "longest wait between webhook retries", func(c *Config) any { return &c.Webhooks.MaxBackoff }},
+	{"webhooks.delivery_log_size", "webhook delivery attempts and dead letters kept", func(c *Config) any { return &c.Webhooks.DeliveryLogSize }},
+	{"webhooks.allow_private_hosts", "accept private webhook hosts, for local testing", func(c *Config) any { return &c.Webhooks.AllowPrivateHosts }},
+	{"tracing.enabled", "enable OpenTelemetry tracing", func(c *Config) any { return &c.Tracing.Enabled }},
+	{"tracing.exporter", "span exporter (stdout, file)", func(c *Config) any { return &c.Tracing.Exporter }},
+	{"tracing.file", "span output file for the file exporter", func(c *Config) any { return &c.Tracing.File }},
+	{"tracing.sample_ratio", "fraction of traces sampled, between 0 and 1", func(c *Config) any { return &c.Tracing.SampleRatio }},
+	{"auth.enabled", "require an API key on /api/v1 routes", func(c *Config) any { return &c.Auth.Enabled }},
+	{"auth.jwt.enabled", "accept JWT bearer tokens", func(c *Config) any { return &c.Auth.JWT.Enabled }},
+	{"auth.jwt.issuer", "required JWT iss claim", func(c *Config) any { return &c.Auth.JWT.Issuer }},
+	{"auth.jwt.audience", "required JWT aud claim", func(c *Config) any { return &c.Auth.JWT.Audience }},
+	{"auth.jwt.hmac_secret_file", "file holding the HMAC secret of HS* tokens", func(c *Config) any { return &c.Auth.JWT.HMACSecretFile }},
+	{"auth.jwt.rsa_public_key_file", "PEM RSA public key of RS*/PS* tokens", func(c *Config) any { return &c.Auth.JWT.RSAPublicKeyFile }},
+	{"auth.jwt.jwks_file", "JWKS file with the token verification keys", func(c *Config) any { return &c.Auth.JWT.JWKSFile }},
+	{"auth.jwt.scope_claim", "claim holding the granted scopes", func(c *Config) any { return &c.Auth.JWT.ScopeClaim }},
+	{"auth.jwt.user_id_claim", "claim holding the end user ID", func(c *Config) any { return &c.Auth.JWT.UserIDClaim }},
+	{"auth.jwt.leeway", "clock skew tolerated on exp/nbf/iat", func(c *Config) any { return &c.Auth.JWT.Leeway }},
+	{"rate_limit.enabled", "enable per client rate limiting and the analytics concurrency gate", func(c *Config) any { return &c.RateLimit.Enabled }},
+	{"rate_limit.requests_per_second", "sustained requests per second per client", func(c *Config) any { return &c.RateLimit.RequestsPerSecond }},
+	{"rate_limit.burst", "requests a client can send at once", func(c *Config) any { return &c.RateLimit.Burst }},
+	{"rate_limit.max_clients", "clients tracked by the rate limiter", func(c *Config) any { return &c.RateLimit.MaxClients }},
+	{"rate_limit.analytics_concurrency", "analytics requests computed at once", func(c *Config) any { return &c.RateLimit.AnalyticsConcurrency }},
+	{"rate_limit.analytics_queue_timeout", "wait for an analytics slot before 429", func(c *Config) any { return &c.RateLimit.AnalyticsQueueTimeout }},
+	{"compression.enabled", "compress responses with brotli or gzip", func(c *Config) any { return &c.Compression.Enabled }},
+	{"compression.level", "compression level, 1 (fastest) to 9 (smallest)", func(c *Config) any { return &c.Compression.Level }},
+	{"features.analytics", "expose the /actions analytics endpoints", func(c *Config) any { return &c.Features.Analytics }},
+	{"features.metrics", "expose Prometheus metrics at /metrics", func(c *Config) any { return &c.Features.Metrics }},
+	{"features.docs", "expose the OpenAPI document and Swagger UI", func(c *Config) any { return &c.Features.Docs }},
+	{"features.request_validation", "validate /api/v1 requests against the OpenAPI document", func(c *Config) any { return &c.Features.RequestValidation }},
+	{"features.graphql", "expose the GraphQL endpoint at /graphql", func(c *Config) any { return &c.Features.GraphQL }},
+}
+
+// Load resolves the configuration from defaults, the config file given by -config or
+// SURF_CONFIG, SURF_* env vars and CLI flags, in increasing order of precedence, and
+// validates the result.
+func Load(args []string, lookupEnv LookupEnv) (*Config, error) {
+	fs := flag.NewFlagSet("surf-challenge", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configFile := fs.String(flagConfig, "", "YAML or JSON config file (env "+envConfigFile+")")
+
+	type override struct {
+		setting setting
+		value   string
+	}
+
+	var flagOverrides []override
+
+	for _, s := range settings {
+		record := func(value string) error {
+			flagOverrides = append(flagOverrides, override{setting: s, value: value})
+
+			return nil
+		}
+
+		usage := fmt.Sprintf("%s (env %s)", s.usage, envName(s.key))
+
+		if _, ok := s.field(&Config{}).(*bool); ok {
+			fs.BoolFunc(flagName(s.key), usage, record)
+
+			continue
+		}
+
+		fs.Func(flagName(s.key), usage, record)
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+
+		return nil, fmt.Errorf("parsing flags: %w", err)
+	}
+
+	cfg := Default()
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv(envConfigFile)
+	}
+
+	if path != "" {
+		err = loadFile(cfg, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		value, ok := lookupEnv(envName(s.key))
+		if !ok {
+			continue
+		}
+
+		err = set(s.field(cfg), value)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", envName(s.key), err)
+		}
+	}
+
+	for _, o := range flagOverrides {
+		err = set(o.setting.field(cfg), o.value)
+		if err != nil {
+			return nil, fmt.Errorf("flag -%s: %w", flagName(o.setting.key), err)
+		}
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// loadFile decodes a YAML file into cfg. JSON files are accepted as well since JSON
+// is a subset of YAML. Unknown keys are rejected to catch typos early.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("decoding config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func set(field any, value string) error {
+	switch f := field.(type) {
+	case *string:
+		*f = value
+	case *int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+
+		*f = v
+	case *bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+
+		*f = v
+	case *float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+
+		*f = v
+	case *time.Duration:
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+
+		*f = v
+	default:
+		return fmt.Errorf("unsupported setting type %T", field)
+	}
+
+	return nil
+}
+
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func flagName(key string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(key)
+}
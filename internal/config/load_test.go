@@ -0,0 +1,221 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Load(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlFile := filepath.Join(dir, "config.yaml")
+	require.NoError(
+		t, os.WriteFile(
+			yamlFile, []byte(`
+server:
+  port: 8080
+  write_timeout: 30s
+log:
+  level: debug
+cache:
+  ttl: 1m
+`), 0o600,
+		),
+	)
+
+	jsonFile := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"server": {"port": 9090}, "features": {"analytics": false}}`), 0o600))
+
+	authFile := filepath.Join(dir, "auth.yaml")
+	require.NoError(
+		t, os.WriteFile(
+			authFile, []byte(`
+auth:
+  enabled: true
+  api_keys:
+    - name: analyst
+      sha256: 2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b
+      scopes: [users:read, analytics:read]
+`), 0o600,
+		),
+	)
+
+	unknownKeyFile := filepath.Join(dir, "unknown.yaml")
+	require.NoError(t, os.WriteFile(unknownKeyFile, []byte("server:\n  prot: 8080\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    func(c *Config)
+		wantErr string
+	}{
+		{
+			name: "When nothing is provided, should return defaults",
+			want: func(*Config) {},
+		},
+		{
+			name: "When a YAML config file is provided, should override defaults",
+			args: []string{"-config", yamlFile},
+			want: func(c *Config) {
+				c.Server.Port = 8080
+				c.Server.WriteTimeout = 30 * time.Second
+				c.Log.Level = "debug"
+				c.Cache.TTL = time.Minute
+			},
+		},
+		{
+			name: "When a JSON config file is provided through env, should override defaults",
+			env:  map[string]string{"SURF_CONFIG": jsonFile},
+			want: func(c *Config) {
+				c.Server.Port = 9090
+				c.Features.Analytics = false
+			},
+		},
+		{
+			name: "When env vars are set, should override the config file",
+			args: []string{"-config", yamlFile},
+			env:  map[string]string{"SURF_SERVER_PORT": "4000", "SURF_LOG_FORMAT": "console"},
+			want: func(c *Config) {
+				c.Server.Port = 4000
+				c.Server.WriteTimeout = 30 * time.Second
+				c.Log.Level = "debug"
+				c.Log.Format = LogFormatConsole
+				c.Cache.TTL = time.Minute
+			},
+		},
+		{
+			name: "When flags are set, should override env vars",
+			args: []string{"-server-port", "5000", "-cache-enabled=false", "-log-level", "warn"},
+			env:  map[string]string{"SURF_SERVER_PORT": "4000"},
+			want: func(c *Config) {
+				c.Server.Port = 5000
+				c.Cache.Enabled = false
+				c.Log.Level = "warn"
+			},
+		},
+		{
+			name: "When API keys are configured, should load them",
+			args: []string{"-config", authFile},
+			want: func(c *Config) {
+				c.Auth = Auth{
+					Enabled: true,
+					JWT:     Default().Auth.JWT,
+					APIKeys: []APIKey{
+						{
+							Name:   "analyst",
+							SHA256: "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b",
+							Scopes: []string{"users:read", "analytics:read"},
+						},
+					},
+				}
+			},
+		},
+		{
+			name:    "When auth is enabled without API keys, should fail",
+			env:     map[string]string{"SURF_AUTH_ENABLED": "true"},
+			wantErr: "auth.api_keys must not be empty when auth is enabled",
+		},
+		{
+			name:    "When JWT is enabled without keys, should fail",
+			env:     map[string]string{"SURF_AUTH_ENABLED": "true", "SURF_AUTH_JWT_ENABLED": "true"},
+			wantErr: "auth.jwt requires one of hmac_secret_file, rsa_public_key_file or jwks_file",
+		},
+		{
+			name:    "When rate limiting is enabled with a zero burst, should fail",
+			args:    []string{"-rate-limit-burst", "0"},
+			wantErr: "rate_limit.burst must be positive, got 0",
+		},
+		{
+			name:    "When the compression level is out of range, should fail",
+			env:     map[string]string{"SURF_COMPRESSION_LEVEL": "12"},
+			wantErr: "compression.level must be between 1 and 9, got 12",
+		},
+		{
+			name:    "When the config file has unknown keys, should fail",
+			args:    []string{"-config", unknownKeyFile},
+			wantErr: "field prot not found",
+		},
+		{
+			name:    "When the config file does not exist, should fail",
+			args:    []string{"-config", filepath.Join(dir, "missing.yaml")},
+			wantErr: "reading config file",
+		},
+		{
+			name:    "When an env var cannot be parsed, should fail naming it",
+			env:     map[string]string{"SURF_SERVER_READ_TIMEOUT": "soon"},
+			wantErr: `env SURF_SERVER_READ_TIMEOUT: invalid duration "soon"`,
+		},
+		{
+			name:    "When a flag is unknown, should fail",
+			args:    []string{"-unknown"},
+			wantErr: "parsing flags",
+		},
+		{
+			name:    "When values are invalid, should report all of them",
+			args:    []string{"-server-port", "0", "-log-format", "xml", "-data-users-file", filepath.Join(dir, "users.json")},
+			wantErr: "server.port must be between 1 and 65535, got 0\nlog.format must be one of [json console], got \"xml\"\ndata.users_file:",
+		},
+		{
+			name:    "When gRPC shares the HTTP port, should fail",
+			env:     map[string]string{"SURF_SERVER_PORT": "8080", "SURF_GRPC_ENABLED": "true", "SURF_GRPC_PORT": "8080"},
+			wantErr: "grpc.port must differ from server.port, both are 8080",
+		},
+		{
+			name:    "When cache is enabled with a non positive TTL, should fail",
+			args:    []string{"-cache-ttl", "0s"},
+			wantErr: "cache.ttl must be positive when cache is enabled",
+		},
+		{
+			name:    "When the stream is enabled with an empty buffer, should fail",
+			env:     map[string]string{"SURF_STREAM_BUFFER_SIZE": "0"},
+			wantErr: "stream.buffer_size must be positive when stream is enabled, got 0",
+		},
+		{
+			name: "When webhooks are enabled without the stream, should still check the feed settings",
+			env: map[string]string{
+				"SURF_STREAM_ENABLED": "false", "SURF_WEBHOOKS_ENABLED": "true",
+				"SURF_STREAM_POLL_INTERVAL": "0s", "SURF_STREAM_BUFFER_SIZE": "0",
+			},
+			wantErr: "stream.poll_interval must be positive when webhooks are enabled, got 0s\n" +
+				"stream.buffer_size must be positive when webhooks are enabled, got 0",
+		},
+		{
+			name: "When webhooks back off for longer than allowed, should fail",
+			env: map[string]string{
+				"SURF_WEBHOOKS_ENABLED": "true", "SURF_WEBHOOKS_INITIAL_BACKOFF": "10m",
+			},
+			wantErr: "webhooks.max_backoff must not be lower than webhooks.initial_backoff, got 5m0s < 10m0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				lookupEnv := func(key string) (string, bool) {
+					v, ok := tt.env[key]
+
+					return v, ok
+				}
+
+				got, err := Load(tt.args, lookupEnv)
+				if tt.wantErr != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+
+				want := Default()
+				tt.want(want)
+				assert.Equal(t, want, got)
+			},
+		)
+	}
+}
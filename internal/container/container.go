@@ -1,27 +1,207 @@
 package container
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
 	"go.uber.org/zap"
 
 	"surf_challenge/internal/action"
+	actiondomain "surf_challenge/internal/action/domain"
 	actionstorage "surf_challenge/internal/action/storage"
+	"surf_challenge/internal/api/openapi"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/cache"
+	"surf_challenge/internal/config"
+	"surf_challenge/internal/feed"
+	"surf_challenge/internal/health"
+	"surf_challenge/internal/metrics"
+	"surf_challenge/internal/ratelimit"
+	"surf_challenge/internal/tracing"
 	"surf_challenge/internal/user"
 	"surf_challenge/internal/user/storage"
+	"surf_challenge/internal/webhook"
+	webhookstorage "surf_challenge/internal/webhook/storage"
 )
 
 type AppContainer struct {
+	Config        *config.Config
 	UserService   user.Service
 	ActionService action.Service
+	Health        health.Checker
+	Metrics       *metrics.Metrics
+	Tracing       *tracing.Provider
+	Authenticator auth.Authenticator
+	RateLimiter   *ratelimit.Limiter
+	AnalyticsGate *ratelimit.Gate
+	OpenAPI       *openapi3.T
+	OpenAPIRouter routers.Router
+	// Feed publishes the actions ingested while running, polled from FeedSource.
+	Feed       *feed.Feed
+	FeedSource feed.Source
+	// WebhookService and WebhookDispatcher are only set when webhooks are enabled.
+	WebhookService    webhook.Service
+	WebhookDispatcher *webhook.Dispatcher
 }
 
-func NewAppContainer(logger *zap.SugaredLogger) *AppContainer {
-	usersRepository := storage.NewRepository()
-	actionsRepository := actionstorage.NewRepository()
+func NewAppContainer(logger *zap.SugaredLogger, cfg *config.Config) (*AppContainer, error) {
+	appMetrics := metrics.New()
+
+	tracingProvider, err := tracing.New(cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("initializing tracing: %w", err)
+	}
+
+	tracer := tracingProvider.Tracer()
+
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("initializing auth: %w", err)
+	}
+
+	doc, err := openapi.Load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	openAPIRouter, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("routing OpenAPI document: %w", err)
+	}
+
+	usersRepository := storage.NewTracedRepository(
+		storage.NewRepository(
+			cfg.Data.UsersFile,
+			storage.WithLoadObserver(appMetrics.DatasetLoadObserver("users")),
+		),
+		tracer,
+	)
+	actionsRepository := actionstorage.NewTracedRepository(
+		actionstorage.NewRepository(
+			cfg.Data.ActionsFile,
+			actionstorage.WithLoadObserver(appMetrics.DatasetLoadObserver("actions")),
+		),
+		tracer,
+	)
+
+	actionService := action.NewInstrumentedService(
+		action.NewTracedService(action.NewService(logger, actionsRepository), tracer),
+		appMetrics.ObserveComputation,
+	)
+
+	if cfg.Cache.Enabled {
+		actionService = action.NewCachedService(
+			actionService,
+			cache.New[string, any](cfg.Cache.TTL, cfg.Cache.MaxEntries),
+			actionsRepository.Version,
+			appMetrics.ObserveCacheLookup,
+		)
+	}
+
+	rateLimiter := ratelimit.NewLimiter(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst, cfg.RateLimit.MaxClients)
+	analyticsGate := ratelimit.NewGate(
+		cfg.RateLimit.AnalyticsConcurrency,
+		cfg.RateLimit.AnalyticsQueueTimeout,
+		ratelimit.WithInFlightObserver(appMetrics.GateObserver("analytics")),
+	)
 
-	actionService := action.NewService(logger, actionsRepository)
+	var (
+		webhookService    webhook.Service
+		webhookDispatcher *webhook.Dispatcher
+	)
+
+	if cfg.Webhooks.Enabled {
+		webhooksRepository, err := webhookstorage.NewRepository(cfg.Webhooks.File)
+		if err != nil {
+			return nil, fmt.Errorf("initializing webhooks: %w", err)
+		}
+
+		webhookDispatcher = webhook.NewDispatcher(
+			logger, webhooksRepository, actionService.GetUsersReferrals, webhook.Options{
+				Workers:        cfg.Webhooks.Workers,
+				Timeout:        cfg.Webhooks.Timeout,
+				MaxAttempts:    cfg.Webhooks.MaxAttempts,
+				InitialBackoff: cfg.Webhooks.InitialBackoff,
+				MaxBackoff:     cfg.Webhooks.MaxBackoff,
+				LogSize:        cfg.Webhooks.DeliveryLogSize,
+				Milestones:     cfg.Webhooks.ReferralMilestones,
+				// Off unless configured: endpoints could otherwise reach the internal network.
+				AllowPrivateHosts: cfg.Webhooks.AllowPrivateHosts,
+			},
+		)
+		webhookService = webhook.NewService(logger, webhooksRepository, webhookDispatcher)
+	}
 
 	return &AppContainer{
-		UserService:   user.NewService(logger, usersRepository, actionService),
+		Config:        cfg,
+		UserService:   user.NewTracedService(user.NewService(logger, usersRepository, actionService), tracer),
 		ActionService: actionService,
+		Health: health.NewChecker(
+			health.Dataset{Name: "users", Count: usersRepository.Count},
+			health.Dataset{Name: "actions", Count: actionsRepository.Count},
+		),
+		Metrics:       appMetrics,
+		Tracing:       tracingProvider,
+		Authenticator: authenticator,
+		RateLimiter:   rateLimiter,
+		AnalyticsGate: analyticsGate,
+		OpenAPI:       doc,
+		OpenAPIRouter: openAPIRouter,
+		Feed:          feed.New(cfg.Stream.BufferSize),
+		FeedSource: feed.Source{
+			Version: actionsRepository.Version,
+			List: func(ctx context.Context) ([]*actiondomain.Action, error) {
+				return actionService.ListActions(ctx, actiondomain.Filter{})
+			},
+		},
+		WebhookService:    webhookService,
+		WebhookDispatcher: webhookDispatcher,
+	}, nil
+}
+
+func newAuthenticator(cfg config.Auth) (auth.Authenticator, error) {
+	if !cfg.Enabled {
+		return auth.NewAnonymousAuthenticator(), nil
+	}
+
+	var authenticators []auth.Authenticator
+
+	if len(cfg.APIKeys) > 0 {
+		keys := make([]auth.APIKey, 0, len(cfg.APIKeys))
+		for _, key := range cfg.APIKeys {
+			keys = append(keys, auth.APIKey{Name: key.Name, SHA256: key.SHA256, Scopes: key.Scopes})
+		}
+
+		apiKeys, err := auth.NewAPIKeyAuthenticator(keys)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, apiKeys)
 	}
+
+	if cfg.JWT.Enabled {
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(
+			auth.JWTConfig{
+				Issuer:           cfg.JWT.Issuer,
+				Audience:         cfg.JWT.Audience,
+				HMACSecretFile:   cfg.JWT.HMACSecretFile,
+				RSAPublicKeyFile: cfg.JWT.RSAPublicKeyFile,
+				JWKSFile:         cfg.JWT.JWKSFile,
+				ScopeClaim:       cfg.JWT.ScopeClaim,
+				UserIDClaim:      cfg.JWT.UserIDClaim,
+				Leeway:           cfg.JWT.Leeway,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	return auth.NewChainAuthenticator(authenticators...), nil
 }
@@ -0,0 +1,132 @@
+// Package feed publishes newly ingested actions to live subscribers, keeping the last
+// events in a ring buffer so that subscribers can resume after a disconnection.
+package feed
+
+import (
+	"sync"
+
+	"surf_challenge/internal/action/domain"
+)
+
+// subscriberBuffer is the number of events a subscriber may lag behind before being
+// dropped. Dropped subscribers resume from the ring buffer when they reconnect.
+const subscriberBuffer = 64
+
+// Event is a published action. IDs increase by one with every event.
+type Event struct {
+	ID     uint64
+	Action *domain.Action
+}
+
+type Feed struct {
+	mu          sync.Mutex
+	ring        []Event
+	start       int
+	lastID      uint64
+	subscribers map[*Subscription]struct{}
+}
+
+// New returns a feed retaining the last size events.
+func New(size int) *Feed {
+	return &Feed{
+		ring:        make([]Event, 0, size),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the next IDs to actions and delivers them to every subscriber whose
+// filter they match.
+func (f *Feed) Publish(actions ...*domain.Action) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, a := range actions {
+		f.lastID++
+		event := Event{ID: f.lastID, Action: a}
+
+		if len(f.ring) < cap(f.ring) {
+			f.ring = append(f.ring, event)
+		} else {
+			f.ring[f.start] = event
+			f.start = (f.start + 1) % len(f.ring)
+		}
+
+		for s := range f.subscribers {
+			if !s.filter.Matches(a) {
+				continue
+			}
+
+			select {
+			case s.events <- event:
+			default:
+				f.drop(s)
+			}
+		}
+	}
+}
+
+// Subscription receives the events matching its filter until closed.
+type Subscription struct {
+	feed   *Feed
+	filter domain.Filter
+	events chan Event
+
+	// Backlog holds the retained events published after the ID the subscription
+	// resumed from, to be sent before the live ones.
+	Backlog []Event
+}
+
+// Events is closed when the subscription is closed, or dropped for lagging behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the delivery of events. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	s.feed.drop(s)
+}
+
+// Subscribe registers a subscriber for the events matching filter. With resume set,
+// the retained events published after lastEventID are returned as the backlog; events
+// already evicted from the ring buffer are lost. An ID ahead of the feed, issued before
+// a restart, replays every retained event.
+func (f *Feed) Subscribe(filter domain.Filter, lastEventID uint64, resume bool) *Subscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := &Subscription{
+		feed:   f,
+		filter: filter,
+		events: make(chan Event, subscriberBuffer),
+	}
+
+	if resume {
+		if lastEventID > f.lastID {
+			lastEventID = 0
+		}
+
+		for i := range f.ring {
+			event := f.ring[(f.start+i)%len(f.ring)]
+			if event.ID > lastEventID && filter.Matches(event.Action) {
+				s.Backlog = append(s.Backlog, event)
+			}
+		}
+	}
+
+	f.subscribers[s] = struct{}{}
+
+	return s
+}
+
+// drop must be called with the lock held.
+func (f *Feed) drop(s *Subscription) {
+	if _, ok := f.subscribers[s]; !ok {
+		return
+	}
+
+	delete(f.subscribers, s)
+	close(s.events)
+}
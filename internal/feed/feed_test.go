@@ -0,0 +1,111 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"surf_challenge/internal/action/domain"
+)
+
+func eventIDs(events []Event) []uint64 {
+	ids := make([]uint64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	return ids
+}
+
+func Test_Feed_Subscribe(t *testing.T) {
+	userID := int64(1)
+
+	tests := []struct {
+		name        string
+		filter      domain.Filter
+		lastEventID uint64
+		resume      bool
+		wantBacklog []uint64
+	}{
+		{
+			name:        "should not replay events without resuming",
+			wantBacklog: []uint64{},
+		},
+		{
+			name:        "should replay the retained events after the last event ID",
+			lastEventID: 3,
+			resume:      true,
+			wantBacklog: []uint64{4, 5},
+		},
+		{
+			name:        "should replay the retained events when the last event was evicted",
+			lastEventID: 1,
+			resume:      true,
+			wantBacklog: []uint64{3, 4, 5},
+		},
+		{
+			name:        "should replay every retained event when the last event ID is ahead of the feed",
+			lastEventID: 42,
+			resume:      true,
+			wantBacklog: []uint64{3, 4, 5},
+		},
+		{
+			name:        "should only replay the events matching the filter",
+			filter:      domain.Filter{UserID: &userID},
+			resume:      true,
+			wantBacklog: []uint64{3, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				f := New(3)
+				for i := range 5 {
+					f.Publish(&domain.Action{ID: i + 1, UserID: 1 - i%2})
+				}
+
+				s := f.Subscribe(tt.filter, tt.lastEventID, tt.resume)
+				defer s.Close()
+
+				assert.Equal(t, tt.wantBacklog, eventIDs(s.Backlog))
+			},
+		)
+	}
+}
+
+func Test_Feed_Publish(t *testing.T) {
+	f := New(10)
+
+	all := f.Subscribe(domain.Filter{}, 0, false)
+	defer all.Close()
+
+	referrals := f.Subscribe(domain.Filter{Type: "refer_user"}, 0, false)
+	defer referrals.Close()
+
+	f.Publish(&domain.Action{ID: 1, Type: "VIEW_CONTACTS"}, &domain.Action{ID: 2, Type: domain.ActionTypeReferUser})
+
+	assert.Equal(t, uint64(1), (<-all.Events()).ID)
+	assert.Equal(t, uint64(2), (<-all.Events()).ID)
+	assert.Equal(t, uint64(2), (<-referrals.Events()).ID, "should deliver the events matching the filter")
+	assert.Empty(t, referrals.Events())
+}
+
+func Test_Feed_Publish_lagging(t *testing.T) {
+	f := New(10)
+
+	s := f.Subscribe(domain.Filter{}, 0, false)
+
+	for i := range subscriberBuffer + 1 {
+		f.Publish(&domain.Action{ID: i})
+	}
+
+	received := 0
+	for range s.Events() {
+		received++
+	}
+
+	require.Equal(t, subscriberBuffer, received, "should close the events of a subscriber lagging behind")
+
+	s.Close()
+}
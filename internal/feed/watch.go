@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action/domain"
+)
+
+// Source is the dataset the feed watches. Version changes whenever the dataset does,
+// and List returns every action in chronological order.
+type Source struct {
+	Version func(ctx context.Context) (string, error)
+	List    func(ctx context.Context) ([]*domain.Action, error)
+}
+
+// Watch polls the source every interval until ctx is done, and publishes the actions
+// that appeared since the previous version. Actions present at the first successful
+// poll are not published: they were not ingested while watching.
+func (f *Feed) Watch(ctx context.Context, logger *zap.SugaredLogger, source Source, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		version string
+		seen    map[int]struct{}
+	)
+
+	for {
+		current, err := source.Version(ctx)
+
+		switch {
+		case err != nil:
+			logger.Warnw("failed to read the actions dataset version", "error", err)
+		case seen == nil || current != version:
+			actions, err := source.List(ctx)
+			if err != nil {
+				logger.Warnw("failed to list actions", "error", err)
+
+				break
+			}
+
+			if fresh := newActions(seen, actions); len(fresh) > 0 {
+				logger.Infow("Publishing ingested actions", "actions", len(fresh))
+				f.Publish(fresh...)
+			}
+
+			version, seen = current, make(map[int]struct{}, len(actions))
+			for _, a := range actions {
+				seen[a.ID] = struct{}{}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newActions returns the actions whose ID is not in seen, in chronological order. With
+// no previous version, nothing is new.
+func newActions(seen map[int]struct{}, actions []*domain.Action) []*domain.Action {
+	if seen == nil {
+		return nil
+	}
+
+	return slices.DeleteFunc(
+		slices.Clone(actions), func(a *domain.Action) bool {
+			_, ok := seen[a.ID]
+
+			return ok
+		},
+	)
+}
@@ -0,0 +1,68 @@
+package feed
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action/domain"
+)
+
+func Test_Feed_Watch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		version = "v1"
+		actions = []*domain.Action{{ID: 1}, {ID: 2}}
+		listed  = make(chan struct{})
+		once    sync.Once
+	)
+
+	source := Source{
+		Version: func(context.Context) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return version, nil
+		},
+		List: func(context.Context) ([]*domain.Action, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			once.Do(
+				func() {
+					close(listed)
+				},
+			)
+
+			return actions, nil
+		},
+	}
+
+	f := New(10)
+
+	s := f.Subscribe(domain.Filter{}, 0, false)
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go f.Watch(ctx, zap.NewNop().Sugar(), source, time.Millisecond)
+
+	<-listed
+
+	mu.Lock()
+	version, actions = "v2", []*domain.Action{{ID: 1}, {ID: 2}, {ID: 3}}
+	mu.Unlock()
+
+	select {
+	case event := <-s.Events():
+		assert.Equal(t, 3, event.Action.ID, "should only publish the actions ingested while watching")
+	case <-time.After(time.Second):
+		require.Fail(t, "should publish the new action")
+	}
+}
@@ -0,0 +1,397 @@
+package generate
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	actiondomain "surf_challenge/internal/action/domain"
+)
+
+// timeLayout is the timestamp format of the embedded datasets.
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+// ErrInvalidOptions is returned for options a dataset cannot be generated with.
+var ErrInvalidOptions = errors.New("invalid generation options")
+
+// Options size the generated datasets. Generating twice with the same model and
+// options gives the same datasets.
+type Options struct {
+	Seed  uint64
+	Users int
+	// Actions is the number of actions aimed at, the model mean per user being kept
+	// when zero. The count is reached on average, not exactly.
+	Actions int
+	// Start and End bound the signups and actions, the model ones being used when zero.
+	Start time.Time
+	End   time.Time
+}
+
+// Stats counts what was generated.
+type Stats struct {
+	Users     int `json:"users"`
+	Actions   int `json:"actions"`
+	Referrals int `json:"referrals"`
+}
+
+type userRecord struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type actionRecord struct {
+	ID         int    `json:"id"`
+	Type       string `json:"type"`
+	UserID     int    `json:"userId"`
+	TargetUser *int   `json:"targetUser,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type generator struct {
+	model *Model
+	opts  Options
+	rng   *rand.Rand
+	// scale multiplies the number of actions of the users.
+	scale   float64
+	signups []time.Time
+	// bySignup lists the user IDs by signup time, position giving the index of a user in it.
+	bySignup []int
+	// next finds the first user not referred yet from an index of bySignup, with path
+	// compression. len(bySignup) means none.
+	next []int
+	// referralsLeft keeps the share of referred users to the model rate.
+	referralsLeft int
+	stats         Stats
+}
+
+// Generate writes users and their actions as JSON arrays laid out like the embedded
+// datasets. Users sign up uniformly over the period and act following the model:
+// their first action, the transitions between actions, the number of actions and the
+// time between them are drawn from it. The actions of a user are written together,
+// kept within the period, and referrals target a user not referred yet who signs up
+// after the referral when there is one. Memory grows with the users, not the actions.
+func Generate(model *Model, opts Options, users, actions io.Writer) (Stats, error) {
+	if opts.Users < 1 {
+		return Stats{}, fmt.Errorf("%w: users must be positive, got %d", ErrInvalidOptions, opts.Users)
+	}
+
+	if opts.Actions < 0 {
+		return Stats{}, fmt.Errorf("%w: actions must not be negative, got %d", ErrInvalidOptions, opts.Actions)
+	}
+
+	if opts.Start.IsZero() {
+		opts.Start = model.Start
+	}
+
+	if opts.End.IsZero() {
+		opts.End = model.End
+	}
+
+	if !opts.End.After(opts.Start) {
+		return Stats{}, fmt.Errorf("%w: end %s is not after start %s", ErrInvalidOptions, opts.End, opts.Start)
+	}
+
+	g := &generator{
+		model:         model,
+		opts:          opts,
+		rng:           rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)), //nolint:gosec // reproducible, not secret
+		scale:         1,
+		referralsLeft: int(math.Round(model.ReferralRate * float64(opts.Users))),
+	}
+
+	if opts.Actions > 0 {
+		g.scale = float64(opts.Actions) / (model.meanActions() * float64(opts.Users))
+	}
+
+	err := g.users(users)
+	if err != nil {
+		return Stats{}, fmt.Errorf("writing users: %w", err)
+	}
+
+	err = g.actions(actions)
+	if err != nil {
+		return Stats{}, fmt.Errorf("writing actions: %w", err)
+	}
+
+	return g.stats, nil
+}
+
+func (g *generator) users(w io.Writer) error {
+	span := g.opts.End.Sub(g.opts.Start)
+	g.signups = make([]time.Time, g.opts.Users)
+
+	return writeArray(
+		w, g.opts.Users, func(id int) any {
+			g.signups[id] = g.opts.Start.Add(time.Duration(g.rng.Int64N(int64(span)))).Truncate(time.Millisecond)
+			g.stats.Users++
+
+			return userRecord{
+				ID:        id,
+				Name:      g.model.Names[g.rng.IntN(len(g.model.Names))],
+				CreatedAt: g.signups[id].UTC().Format(timeLayout),
+			}
+		},
+	)
+}
+
+func (g *generator) actions(w io.Writer) error {
+	g.bySignup = make([]int, len(g.signups))
+	for id := range g.bySignup {
+		g.bySignup[id] = id
+	}
+
+	slices.SortStableFunc(
+		g.bySignup, func(a, b int) int {
+			return g.signups[a].Compare(g.signups[b])
+		},
+	)
+
+	g.next = make([]int, len(g.bySignup)+1)
+	for i := range g.next {
+		g.next[i] = i
+	}
+
+	bw := bufio.NewWriter(w)
+
+	_, err := bw.WriteString("[")
+	if err != nil {
+		return err
+	}
+
+	for userID := range g.signups {
+		for _, a := range g.stream(userID) {
+			err = writeElement(bw, g.stats.Actions, a)
+			if err != nil {
+				return err
+			}
+
+			g.stats.Actions++
+		}
+	}
+
+	return closeArray(bw, g.stats.Actions)
+}
+
+// stream draws the actions of a user.
+func (g *generator) stream(userID int) []actionRecord {
+	count := g.count()
+	if count == 0 {
+		return nil
+	}
+
+	signup := g.signups[userID]
+
+	// Gaps shrink as the actions per user grow, so that the period stays the same.
+	gaps := make([]time.Duration, count)
+	gaps[0] = g.model.FirstGaps[g.rng.IntN(len(g.model.FirstGaps))]
+	total := gaps[0]
+
+	for i := 1; i < count; i++ {
+		gaps[i] = time.Duration(float64(g.model.Gaps[g.rng.IntN(len(g.model.Gaps))]) / max(g.scale, 1))
+		total += gaps[i]
+	}
+
+	// The actions are compressed into what is left of the period after signup.
+	compress := 1.0
+	if left := g.opts.End.Sub(signup); total > left {
+		compress = float64(left) / float64(total)
+	}
+
+	records := make([]actionRecord, 0, count)
+	at := signup
+	typ := draw(g.rng, g.model.First)
+
+	for i := range count {
+		if i > 0 {
+			typ = draw(g.rng, g.model.Transitions[typ])
+		}
+
+		at = at.Add(time.Duration(float64(gaps[i]) * compress))
+
+		record := actionRecord{
+			ID:        g.stats.Actions + len(records),
+			Type:      g.model.Types[typ],
+			UserID:    userID,
+			CreatedAt: at.Truncate(time.Millisecond).UTC().Format(timeLayout),
+		}
+
+		if strings.EqualFold(record.Type, actiondomain.ActionTypeReferUser) {
+			target, ok := g.target(userID, at)
+			if !ok {
+				typ = g.redraw(typ)
+				record.Type = g.model.Types[typ]
+			} else {
+				record.TargetUser = &target
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// count draws the number of actions of a user, scaled and rounded randomly so that
+// the mean is kept.
+func (g *generator) count() int {
+	n := float64(g.model.ActionsPerUser[g.rng.IntN(len(g.model.ActionsPerUser))]) * g.scale
+	whole := math.Floor(n)
+
+	if g.rng.Float64() < n-whole {
+		whole++
+	}
+
+	return int(whole)
+}
+
+// target picks the user referred at time at: one not referred yet, signing up after
+// at when possible, other than the referrer. It fails once the referral rate is met.
+func (g *generator) target(referrer int, at time.Time) (int, bool) {
+	if g.referralsLeft == 0 {
+		return 0, false
+	}
+
+	after, _ := slices.BinarySearchFunc(
+		g.bySignup, at, func(id int, t time.Time) int {
+			return g.signups[id].Compare(t)
+		},
+	)
+
+	for _, from := range []int{after + g.rng.IntN(len(g.bySignup)-after+1), after, 0} {
+		pos := g.free(from)
+
+		if pos < len(g.bySignup) && g.bySignup[pos] == referrer {
+			pos = g.free(pos + 1)
+		}
+
+		if pos < len(g.bySignup) {
+			g.next[pos] = pos + 1
+			g.referralsLeft--
+			g.stats.Referrals++
+
+			return g.bySignup[pos], true
+		}
+	}
+
+	return 0, false
+}
+
+// free returns the first position from pos of a user not referred yet.
+func (g *generator) free(pos int) int {
+	root := pos
+	for g.next[root] != root {
+		root = g.next[root]
+	}
+
+	for g.next[pos] != root {
+		g.next[pos], pos = root, g.next[pos]
+	}
+
+	return root
+}
+
+// redraw replaces a referral that has no target by another action type, drawn from
+// the overall frequencies of the types.
+func (g *generator) redraw(refer int) int {
+	weights := slices.Clone(g.model.First)
+
+	for _, row := range g.model.Transitions {
+		for i, count := range row {
+			weights[i] += count
+		}
+	}
+
+	weights[refer] = 0
+
+	if slices.Max(weights) == 0 {
+		return refer
+	}
+
+	return draw(g.rng, weights)
+}
+
+// draw returns an index with a probability proportional to its weight.
+func draw(rng *rand.Rand, weights []int) int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	if total == 0 {
+		return rng.IntN(len(weights))
+	}
+
+	n := rng.IntN(total)
+
+	for i, w := range weights {
+		if n < w {
+			return i
+		}
+
+		n -= w
+	}
+
+	return len(weights) - 1
+}
+
+// writeArray writes count elements produced by element as a JSON array.
+func writeArray(w io.Writer, count int, element func(i int) any) error {
+	bw := bufio.NewWriter(w)
+
+	_, err := bw.WriteString("[")
+	if err != nil {
+		return err
+	}
+
+	for i := range count {
+		err = writeElement(bw, i, element(i))
+		if err != nil {
+			return err
+		}
+	}
+
+	return closeArray(bw, count)
+}
+
+func writeElement(bw *bufio.Writer, index int, v any) error {
+	data, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if index == 0 {
+		separator = "\n  "
+	}
+
+	_, err = bw.WriteString(separator)
+	if err != nil {
+		return err
+	}
+
+	_, err = bw.Write(data)
+
+	return err
+}
+
+func closeArray(bw *bufio.Writer, count int) error {
+	end := "\n]\n"
+	if count == 0 {
+		end = "]\n"
+	}
+
+	_, err := bw.WriteString(end)
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
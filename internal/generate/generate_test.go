@@ -0,0 +1,166 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	actionstorage "surf_challenge/internal/action/storage"
+	userstorage "surf_challenge/internal/user/storage"
+	"surf_challenge/internal/validation"
+)
+
+// embeddedModel learns the model of the embedded datasets.
+func embeddedModel(t *testing.T) *Model {
+	t.Helper()
+
+	model, err := LoadModel(t.Context(), zap.NewNop().Sugar(), userstorage.NewRepository(""), actionstorage.NewRepository(""))
+	require.NoError(t, err)
+
+	return model
+}
+
+func generate(t *testing.T, model *Model, opts Options) (Stats, []byte, []byte) {
+	t.Helper()
+
+	var users, actions bytes.Buffer
+
+	stats, err := Generate(model, opts, &users, &actions)
+	require.NoError(t, err)
+
+	return stats, users.Bytes(), actions.Bytes()
+}
+
+func Test_Generate(t *testing.T) {
+	model := embeddedModel(t)
+	opts := Options{Seed: 42, Users: 2000, Actions: 100000}
+
+	stats, users, actions := generate(t, model, opts)
+
+	t.Run(
+		"should generate the users and about the actions asked for", func(t *testing.T) {
+			assert.Equal(t, 2000, stats.Users)
+			assert.InDelta(t, 100000, stats.Actions, 5000)
+		},
+	)
+
+	t.Run(
+		"should generate valid datasets", func(t *testing.T) {
+			report, err := validation.Validate(bytes.NewReader(users), bytes.NewReader(actions), validation.Options{})
+			require.NoError(t, err)
+
+			assert.True(t, report.Valid, report.Issues)
+			assert.Equal(t, stats.Actions, report.Actions.Records)
+		},
+	)
+
+	t.Run(
+		"should refer each user once at most, at the model rate", func(t *testing.T) {
+			var records []actionRecord
+			require.NoError(t, json.Unmarshal(actions, &records))
+
+			referred := make(map[int]bool)
+
+			for _, r := range records {
+				if r.TargetUser == nil {
+					continue
+				}
+
+				assert.NotEqual(t, r.UserID, *r.TargetUser)
+				assert.False(t, referred[*r.TargetUser], "user %d referred twice", *r.TargetUser)
+				referred[*r.TargetUser] = true
+			}
+
+			assert.Len(t, referred, stats.Referrals)
+			assert.InDelta(t, model.ReferralRate, float64(stats.Referrals)/2000, 0.01)
+		},
+	)
+
+	t.Run(
+		"should keep the actions within the period", func(t *testing.T) {
+			var records []actionRecord
+			require.NoError(t, json.Unmarshal(actions, &records))
+
+			for _, r := range records {
+				at, err := time.Parse(time.RFC3339, r.CreatedAt)
+				require.NoError(t, err)
+				assert.False(t, at.After(model.End), r)
+			}
+		},
+	)
+
+	t.Run(
+		"should generate the same datasets with the same seed", func(t *testing.T) {
+			_, sameUsers, sameActions := generate(t, model, opts)
+
+			assert.Equal(t, users, sameUsers)
+			assert.Equal(t, actions, sameActions)
+		},
+	)
+
+	t.Run(
+		"should generate other datasets with another seed", func(t *testing.T) {
+			_, _, otherActions := generate(t, model, Options{Seed: 43, Users: 2000, Actions: 100000})
+
+			assert.NotEqual(t, actions, otherActions)
+		},
+	)
+}
+
+func Test_Generate_layout(t *testing.T) {
+	users, actions := testData()
+
+	model, err := NewModel(users, actions)
+	require.NoError(t, err)
+
+	model.Names = []string{"Ada"}
+	model.ActionsPerUser = []int{1}
+	model.First = []int{0, 0, 1}
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, gotUsers, gotActions := generate(t, model, Options{Users: 1, Start: start, End: start.Add(time.Millisecond)})
+
+	assert.Equal(t, "[\n  {\n    \"id\": 0,\n    \"name\": \"Ada\",\n    \"createdAt\": \"2022-01-01T00:00:00.000Z\"\n  }\n]\n", string(gotUsers))
+	assert.Equal(
+		t,
+		"[\n  {\n    \"id\": 0,\n    \"type\": \"WELCOME\",\n    \"userId\": 0,\n    \"createdAt\": \"2022-01-01T00:00:00.001Z\"\n  }\n]\n",
+		string(gotActions),
+	)
+}
+
+func Test_Generate_invalidOptions(t *testing.T) {
+	model := &Model{Start: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "should reject no users",
+			opts: Options{},
+		},
+		{
+			name: "should reject negative actions",
+			opts: Options{Users: 1, Actions: -1},
+		},
+		{
+			name: "should reject an end before the start",
+			opts: Options{Users: 1, End: model.Start.Add(-time.Hour)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := Generate(model, tt.opts, &bytes.Buffer{}, &bytes.Buffer{})
+
+				assert.ErrorIs(t, err, ErrInvalidOptions)
+			},
+		)
+	}
+}
@@ -0,0 +1,187 @@
+// Package generate produces synthetic users and actions datasets at any scale, following
+// the behaviour observed in an existing dataset.
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action"
+	actiondomain "surf_challenge/internal/action/domain"
+	actionstorage "surf_challenge/internal/action/storage"
+	userdomain "surf_challenge/internal/user/domain"
+	"surf_challenge/internal/user/mapper"
+	userstorage "surf_challenge/internal/user/storage"
+)
+
+// ErrEmptyDataset is returned when there is no user or action to learn a model from,
+// or when no action belongs to the users.
+var ErrEmptyDataset = errors.New("dataset has no users or actions")
+
+// Model is the empirical behaviour of the users of a dataset. Distributions are kept as
+// samples, drawn from uniformly.
+type Model struct {
+	// Types are the action types, sorted.
+	Types []string `json:"types"`
+	// First counts the first action of the users per type, indexed like Types.
+	First []int `json:"first"`
+	// Transitions counts, per type, the types of the action that follows it for the
+	// same user, indexed like Types.
+	Transitions [][]int `json:"transitions"`
+	// ActionsPerUser samples the number of actions of a user, users without actions
+	// included.
+	ActionsPerUser []int `json:"actionsPerUser"`
+	// FirstGaps samples the time between the signup of a user and their first action.
+	FirstGaps []time.Duration `json:"firstGaps"`
+	// Gaps samples the time between two consecutive actions of a user.
+	Gaps []time.Duration `json:"gaps"`
+	// ReferralRate is the share of users referred by another one.
+	ReferralRate float64 `json:"referralRate"`
+	// Names are the user names of the dataset.
+	Names []string `json:"names"`
+	// Start and End bound the signups and actions of the dataset.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// LoadModel loads the datasets through the repositories and learns a model from them.
+func LoadModel(
+	ctx context.Context, logger *zap.SugaredLogger, users userstorage.Repository, actions actionstorage.Repository,
+) (*Model, error) {
+	count, err := users.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading users: %w", err)
+	}
+
+	entities, _, err := users.QueryUsers(ctx, nil, 1, max(count, 1))
+	if err != nil {
+		return nil, fmt.Errorf("loading users: %w", err)
+	}
+
+	domainUsers, err := mapper.MapUsersEntToDomain(entities)
+	if err != nil {
+		return nil, fmt.Errorf("loading users: %w", err)
+	}
+
+	domainActions, err := action.NewService(logger, actions).ListActions(ctx, actiondomain.Filter{})
+	if err != nil {
+		return nil, fmt.Errorf("loading actions: %w", err)
+	}
+
+	return NewModel(domainUsers, domainActions)
+}
+
+// NewModel learns the model of users and their actions, given in chronological order.
+// The model only depends on the data, not on its order.
+func NewModel(users []*userdomain.User, actions []*actiondomain.Action) (*Model, error) {
+	if len(users) == 0 || len(actions) == 0 {
+		return nil, ErrEmptyDataset
+	}
+
+	m := &Model{Start: users[0].CreatedAt, End: actions[len(actions)-1].CreatedAt}
+
+	signups := make(map[int]time.Time, len(users))
+
+	for _, u := range users {
+		signups[int(u.ID)] = u.CreatedAt
+		m.Names = append(m.Names, u.Name)
+
+		if u.CreatedAt.Before(m.Start) {
+			m.Start = u.CreatedAt
+		}
+
+		if u.CreatedAt.After(m.End) {
+			m.End = u.CreatedAt
+		}
+	}
+
+	slices.Sort(m.Names)
+
+	if actions[0].CreatedAt.Before(m.Start) {
+		m.Start = actions[0].CreatedAt
+	}
+
+	byUser := make(map[int][]*actiondomain.Action)
+	referred := make(map[int]struct{})
+
+	for _, a := range actions {
+		byUser[a.UserID] = append(byUser[a.UserID], a)
+
+		if !slices.Contains(m.Types, a.Type) {
+			m.Types = append(m.Types, a.Type)
+		}
+
+		if strings.EqualFold(a.Type, actiondomain.ActionTypeReferUser) && a.TargetUser != a.UserID {
+			referred[a.TargetUser] = struct{}{}
+		}
+	}
+
+	slices.Sort(m.Types)
+
+	m.First = make([]int, len(m.Types))
+	m.Transitions = make([][]int, len(m.Types))
+
+	for i := range m.Transitions {
+		m.Transitions[i] = make([]int, len(m.Types))
+	}
+
+	for _, u := range users {
+		acts := byUser[int(u.ID)]
+		m.ActionsPerUser = append(m.ActionsPerUser, len(acts))
+	}
+
+	if m.meanActions() == 0 {
+		return nil, ErrEmptyDataset
+	}
+
+	for userID, acts := range byUser {
+		m.First[m.typeIndex(acts[0].Type)]++
+
+		if signup, ok := signups[userID]; ok {
+			m.FirstGaps = append(m.FirstGaps, max(0, acts[0].CreatedAt.Sub(signup)))
+		}
+
+		for i := 0; i+1 < len(acts); i++ {
+			m.Transitions[m.typeIndex(acts[i].Type)][m.typeIndex(acts[i+1].Type)]++
+			m.Gaps = append(m.Gaps, acts[i+1].CreatedAt.Sub(acts[i].CreatedAt))
+		}
+	}
+
+	slices.Sort(m.ActionsPerUser)
+	slices.Sort(m.FirstGaps)
+	slices.Sort(m.Gaps)
+
+	if len(m.FirstGaps) == 0 {
+		m.FirstGaps = []time.Duration{0}
+	}
+
+	if len(m.Gaps) == 0 {
+		m.Gaps = []time.Duration{0}
+	}
+
+	m.ReferralRate = float64(len(referred)) / float64(len(users))
+
+	return m, nil
+}
+
+func (m *Model) typeIndex(typ string) int {
+	i, _ := slices.BinarySearch(m.Types, typ)
+
+	return i
+}
+
+// meanActions is the mean number of actions per user.
+func (m *Model) meanActions() float64 {
+	sum := 0
+	for _, n := range m.ActionsPerUser {
+		sum += n
+	}
+
+	return float64(sum) / float64(len(m.ActionsPerUser))
+}
@@ -0,0 +1,75 @@
+package generate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	actiondomain "surf_challenge/internal/action/domain"
+	userdomain "surf_challenge/internal/user/domain"
+)
+
+func day(d int) time.Time {
+	return time.Date(2021, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testData() ([]*userdomain.User, []*actiondomain.Action) {
+	users := []*userdomain.User{
+		{ID: 0, Name: "Cy", CreatedAt: day(2)},
+		{ID: 1, Name: "Ada", CreatedAt: day(1)},
+		{ID: 2, Name: "Bob", CreatedAt: day(3)},
+	}
+	actions := []*actiondomain.Action{
+		{ID: 0, Type: "WELCOME", UserID: 1, CreatedAt: day(2)},
+		{ID: 1, Type: "WELCOME", UserID: 0, CreatedAt: day(3)},
+		{ID: 2, Type: "REFER_USER", UserID: 1, TargetUser: 2, CreatedAt: day(4)},
+		{ID: 3, Type: "ADD_CONTACT", UserID: 1, CreatedAt: day(7)},
+		{ID: 4, Type: "REFER_USER", UserID: 0, TargetUser: 0, CreatedAt: day(9)},
+	}
+
+	return users, actions
+}
+
+func Test_NewModel(t *testing.T) {
+	users, actions := testData()
+
+	got, err := NewModel(users, actions)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t, &Model{
+			Types:          []string{"ADD_CONTACT", "REFER_USER", "WELCOME"},
+			First:          []int{0, 0, 2},
+			Transitions:    [][]int{{0, 0, 0}, {1, 0, 0}, {0, 2, 0}},
+			ActionsPerUser: []int{0, 2, 3},
+			FirstGaps:      []time.Duration{24 * time.Hour, 24 * time.Hour},
+			Gaps:           []time.Duration{48 * time.Hour, 72 * time.Hour, 144 * time.Hour},
+			ReferralRate:   1.0 / 3,
+			Names:          []string{"Ada", "Bob", "Cy"},
+			Start:          day(1),
+			End:            day(9),
+		}, got,
+	)
+}
+
+func Test_NewModel_emptyDataset(t *testing.T) {
+	users, actions := testData()
+
+	_, err := NewModel(users, nil)
+	assert.ErrorIs(t, err, ErrEmptyDataset)
+
+	_, err = NewModel(users[:1], actions[2:4])
+	assert.ErrorIs(t, err, ErrEmptyDataset, "should fail when no action belongs to the users")
+}
+
+func Test_NewModel_referralCase(t *testing.T) {
+	users, actions := testData()
+	actions[2] = &actiondomain.Action{ID: 2, Type: "refer_user", UserID: 1, TargetUser: 2, CreatedAt: day(4)}
+
+	got, err := NewModel(users, actions)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 1.0/3, got.ReferralRate, 1e-9, "should count referrals whatever their case")
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CountFunc loads a dataset, if not loaded yet, and returns its number of records.
+type CountFunc func(ctx context.Context) (int, error)
+
+type Dataset struct {
+	Name  string
+	Count CountFunc
+}
+
+type DatasetStatus struct {
+	Name    string
+	Records int
+	Err     error
+}
+
+type Report struct {
+	Ready    bool
+	Datasets []DatasetStatus
+}
+
+// ErrNotLoaded is reported for a dataset whose first load did not complete yet.
+var ErrNotLoaded = errors.New("not loaded yet")
+
+//go:generate mockgen -source=health.go -destination=health_mock.go -package=health
+type Checker interface {
+	// Load loads every dataset and records the outcome for Readiness.
+	Load(ctx context.Context) *Report
+	// Readiness returns the outcome of the last Load without loading anything, so that
+	// probes answer at once while the datasets load.
+	Readiness(ctx context.Context) *Report
+}
+
+type checker struct {
+	datasets []Dataset
+
+	mu   sync.RWMutex
+	last *Report
+}
+
+func NewChecker(datasets ...Dataset) Checker {
+	return &checker{
+		datasets: datasets,
+	}
+}
+
+// Load checks every dataset concurrently. The server is ready only when all of them
+// loaded and parsed successfully.
+func (c *checker) Load(ctx context.Context) *Report {
+	statuses := make([]DatasetStatus, len(c.datasets))
+
+	var wg sync.WaitGroup
+
+	for i, dataset := range c.datasets {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			records, err := dataset.Count(ctx)
+			statuses[i] = DatasetStatus{
+				Name:    dataset.Name,
+				Records: records,
+				Err:     err,
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	report := newReport(statuses)
+
+	c.mu.Lock()
+	c.last = report
+	c.mu.Unlock()
+
+	return report
+}
+
+func (c *checker) Readiness(_ context.Context) *Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.last != nil {
+		return c.last
+	}
+
+	statuses := make([]DatasetStatus, len(c.datasets))
+	for i, dataset := range c.datasets {
+		statuses[i] = DatasetStatus{Name: dataset.Name, Err: ErrNotLoaded}
+	}
+
+	return newReport(statuses)
+}
+
+func newReport(statuses []DatasetStatus) *Report {
+	report := &Report{
+		Ready:    true,
+		Datasets: statuses,
+	}
+
+	for _, status := range statuses {
+		if status.Err != nil {
+			report.Ready = false
+		}
+	}
+
+	return report
+}
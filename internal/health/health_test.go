@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checker_Load(t *testing.T) {
+	count := func(records int, err error) CountFunc {
+		return func(context.Context) (int, error) {
+			return records, err
+		}
+	}
+
+	tests := []struct {
+		name     string
+		datasets []Dataset
+		want     *Report
+	}{
+		{
+			name: "should be ready when every dataset loads",
+			datasets: []Dataset{
+				{Name: "users", Count: count(2, nil)},
+				{Name: "actions", Count: count(5, nil)},
+			},
+			want: &Report{
+				Ready: true,
+				Datasets: []DatasetStatus{
+					{Name: "users", Records: 2},
+					{Name: "actions", Records: 5},
+				},
+			},
+		},
+		{
+			name: "should not be ready when a dataset fails to load",
+			datasets: []Dataset{
+				{Name: "users", Count: count(2, nil)},
+				{Name: "actions", Count: count(0, assert.AnError)},
+			},
+			want: &Report{
+				Ready: false,
+				Datasets: []DatasetStatus{
+					{Name: "users", Records: 2},
+					{Name: "actions", Records: 0, Err: assert.AnError},
+				},
+			},
+		},
+		{
+			name:     "should be ready when there is nothing to check",
+			datasets: nil,
+			want: &Report{
+				Ready:    true,
+				Datasets: []DatasetStatus{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				c := NewChecker(tt.datasets...)
+
+				got := c.Load(t.Context())
+
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.want, c.Readiness(t.Context()))
+			},
+		)
+	}
+}
+
+func Test_checker_Readiness(t *testing.T) {
+	loaded := make(chan struct{})
+	c := NewChecker(
+		Dataset{
+			Name: "users", Count: func(context.Context) (int, error) {
+				<-loaded
+
+				return 2, nil
+			},
+		},
+	)
+
+	done := make(chan *Report)
+
+	go func() {
+		done <- c.Load(context.Background())
+	}()
+
+	assert.Equal(
+		t, &Report{Ready: false, Datasets: []DatasetStatus{{Name: "users", Err: ErrNotLoaded}}}, c.Readiness(t.Context()),
+		"readiness should not wait for a dataset being loaded",
+	)
+
+	close(loaded)
+	<-done
+
+	assert.Equal(t, &Report{Ready: true, Datasets: []DatasetStatus{{Name: "users", Records: 2}}}, c.Readiness(t.Context()))
+}
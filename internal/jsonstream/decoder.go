@@ -0,0 +1,225 @@
+// Package jsonstream decodes a JSON array one element at a time, so that datasets are
+// read without buffering their content, and reports the position of every element for error messages.
+package jsonstream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrNotArray is returned when the input is not a JSON array.
+	ErrNotArray = errors.New("expected a JSON array")
+	// ErrTrailingData is returned when the array is followed by more than whitespace.
+	ErrTrailingData = errors.New("unexpected data after the array")
+)
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Decompress returns r, decompressed when it starts like a gzip stream.
+func Decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+
+	return gzip.NewReader(br)
+}
+
+// Position locates an element in the input: its 1-based line and its byte offset.
+type Position struct {
+	Line   int   `json:"line"`
+	Offset int64 `json:"offset"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, offset %d", p.Line, p.Offset)
+}
+
+// Error is a decoding error and where it happened.
+type Error struct {
+	Position
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Position, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads the elements of a JSON array.
+type Decoder struct {
+	lines   *lineReader
+	dec     *json.Decoder
+	started bool
+	done    bool
+}
+
+// NewDecoder returns a decoder reading the array from r.
+func NewDecoder(r io.Reader) *Decoder {
+	lines := &lineReader{r: r}
+
+	return &Decoder{lines: lines, dec: json.NewDecoder(lines)}
+}
+
+// Next decodes the next element into v and returns its position, or io.EOF after the
+// last one, once the input was read to its end. Syntax errors end the stream. Elements
+// that do not fit v are skipped: the *Error wraps a *json.UnmarshalTypeError and Next
+// can be called again.
+func (d *Decoder) Next(v any) (Position, error) {
+	if d.done {
+		return Position{}, io.EOF
+	}
+
+	if !d.started {
+		d.started = true
+
+		tok, err := d.dec.Token()
+		if err != nil {
+			return Position{}, d.fail(err)
+		}
+
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return Position{}, d.fail(&Error{Position: d.position(0), Err: ErrNotArray})
+		}
+	}
+
+	if !d.dec.More() {
+		_, err := d.dec.Token()
+		if err != nil {
+			return Position{}, d.fail(err)
+		}
+
+		offset := d.nextOffset(false)
+
+		_, err = d.dec.Token()
+		if !errors.Is(err, io.EOF) {
+			return Position{}, d.trailing(offset, err)
+		}
+
+		d.done = true
+
+		return Position{}, io.EOF
+	}
+
+	pos := d.position(d.nextOffset(true))
+
+	err := d.dec.Decode(v)
+	if err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return pos, &Error{Position: pos, Err: err}
+		}
+
+		return Position{}, d.fail(err)
+	}
+
+	return pos, nil
+}
+
+// nextOffset returns the offset of the next value: the buffered input ends where the
+// reading stopped, and starts with the whitespace, and the separator when skipped, that
+// precede the value.
+func (d *Decoder) nextOffset(separator bool) int64 {
+	buffered := d.dec.Buffered()
+
+	for {
+		b, err := buffered.(io.ByteReader).ReadByte()
+		if err != nil {
+			return d.lines.read
+		}
+
+		if (b != ',' || !separator) && b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			left, _ := io.Copy(io.Discard, buffered)
+
+			return d.lines.read - left - 1
+		}
+	}
+}
+
+// fail ends the stream with err, located when it is a syntax error.
+func (d *Decoder) fail(err error) error {
+	d.done = true
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The offset counts the bytes read up to the invalid one included.
+		return &Error{Position: d.position(max(0, syntaxErr.Offset-1)), Err: err}
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &Error{Position: d.position(d.lines.read), Err: io.ErrUnexpectedEOF}
+	}
+
+	return err
+}
+
+// trailing reports what follows the array, err being nil when it is valid JSON.
+func (d *Decoder) trailing(offset int64, err error) error {
+	d.done = true
+
+	var syntaxErr *json.SyntaxError
+	if err == nil || errors.As(err, &syntaxErr) {
+		return &Error{Position: d.position(offset), Err: ErrTrailingData}
+	}
+
+	return err
+}
+
+func (d *Decoder) position(offset int64) Position {
+	return Position{Line: d.lines.lineAt(offset), Offset: offset}
+}
+
+// lineReader counts the lines of what it reads. Only the newlines beyond the last
+// position asked for are kept, as positions are asked for in increasing order.
+type lineReader struct {
+	r        io.Reader
+	read     int64
+	line     int
+	newlines []int64
+}
+
+func (l *lineReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+
+	for i := 0; i < n; {
+		j := bytes.IndexByte(p[i:n], '\n')
+		if j < 0 {
+			break
+		}
+
+		l.newlines = append(l.newlines, l.read+int64(i+j))
+		i += j + 1
+	}
+
+	l.read += int64(n)
+
+	return n, err
+}
+
+func (l *lineReader) lineAt(offset int64) int {
+	passed := 0
+	for passed < len(l.newlines) && l.newlines[passed] < offset {
+		passed++
+	}
+
+	l.line += passed
+	l.newlines = l.newlines[passed:]
+
+	return l.line + 1
+}
@@ -0,0 +1,179 @@
+package jsonstream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID int `json:"id"`
+}
+
+// decodeAll returns the IDs and positions decoded from input, and the errors met.
+func decodeAll(input string) ([]int, []Position, []error) {
+	d := NewDecoder(strings.NewReader(input))
+
+	var (
+		ids       []int
+		positions []Position
+		errs      []error
+	)
+
+	for {
+		var r record
+
+		pos, err := d.Next(&r)
+		if errors.Is(err, io.EOF) {
+			return ids, positions, errs
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				continue
+			}
+
+			return ids, positions, errs
+		}
+
+		ids = append(ids, r.ID)
+		positions = append(positions, pos)
+	}
+}
+
+func Test_Decoder_Next(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantIDs       []int
+		wantPositions []Position
+		wantErrs      []string
+	}{
+		{
+			name:          "should decode the elements with their position",
+			input:         "[\n  {\"id\": 1},\n  {\"id\": 2}\n]\n",
+			wantIDs:       []int{1, 2},
+			wantPositions: []Position{{Line: 2, Offset: 4}, {Line: 3, Offset: 17}},
+		},
+		{
+			name:    "should decode an array on a single line",
+			input:   `[{"id":1},{"id":2}]`,
+			wantIDs: []int{1, 2},
+			wantPositions: []Position{
+				{Line: 1, Offset: 1}, {Line: 1, Offset: 10},
+			},
+		},
+		{
+			name:  "should decode an empty array",
+			input: " [ ] ",
+		},
+		{
+			name:          "should skip elements of the wrong type and go on",
+			input:         "[\n{\"id\":1},\n{\"id\":\"two\"},\n{\"id\":3}\n]",
+			wantIDs:       []int{1, 3},
+			wantPositions: []Position{{Line: 2, Offset: 2}, {Line: 4, Offset: 26}},
+			wantErrs:      []string{"line 3, offset 12: json: cannot unmarshal string into Go struct field record.id of type int"},
+		},
+		{
+			name:          "should stop at a syntax error and locate it",
+			input:         "[\n{\"id\":1},\n{\"id\":2,,}\n]",
+			wantIDs:       []int{1},
+			wantPositions: []Position{{Line: 2, Offset: 2}},
+			wantErrs:      []string{"line 3, offset 20: invalid character ',' looking for beginning of object key string"},
+		},
+		{
+			name:          "should report a truncated input",
+			input:         "[\n{\"id\":1},\n{\"id\"",
+			wantIDs:       []int{1},
+			wantPositions: []Position{{Line: 2, Offset: 2}},
+			wantErrs:      []string{"line 3, offset 17: unexpected EOF"},
+		},
+		{
+			name:     "should reject an input which is not an array",
+			input:    `{"id":1}`,
+			wantErrs: []string{"line 1, offset 0: expected a JSON array"},
+		},
+		{
+			name:          "should reject data after the array",
+			input:         "[{\"id\":1}]\n{}",
+			wantIDs:       []int{1},
+			wantPositions: []Position{{Line: 1, Offset: 1}},
+			wantErrs:      []string{"line 2, offset 11: unexpected data after the array"},
+		},
+		{
+			name:          "should reject invalid data after the array",
+			input:         "[{\"id\":1}] x",
+			wantIDs:       []int{1},
+			wantPositions: []Position{{Line: 1, Offset: 1}},
+			wantErrs:      []string{"line 1, offset 11: unexpected data after the array"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ids, positions, errs := decodeAll(tt.input)
+
+				assert.Equal(t, tt.wantIDs, ids)
+				assert.Equal(t, tt.wantPositions, positions)
+				require.Len(t, errs, len(tt.wantErrs))
+
+				for i, err := range errs {
+					assert.EqualError(t, err, tt.wantErrs[i])
+				}
+			},
+		)
+	}
+}
+
+func Test_Decompress(t *testing.T) {
+	var compressed bytes.Buffer
+
+	w := gzip.NewWriter(&compressed)
+	_, err := w.Write([]byte(`[{"id":1}]`))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{
+			name:  "should decompress a gzip stream",
+			input: compressed.Bytes(),
+			want:  `[{"id":1}]`,
+		},
+		{
+			name:  "should pass plain input through",
+			input: []byte(`[{"id":1}]`),
+			want:  `[{"id":1}]`,
+		},
+		{
+			name:  "should pass an empty input through",
+			input: []byte{},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r, err := Decompress(bytes.NewReader(tt.input))
+				require.NoError(t, err)
+
+				got, err := io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, string(got))
+			},
+		)
+	}
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"surf_challenge/internal/config"
+)
+
+// New builds a zap logger for the configured level and format. The json format uses
+// zap's production encoder, console the human-friendly development one.
+func New(cfg config.Log) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("parsing log level: %w", err)
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	if cfg.Format == config.LogFormatConsole {
+		zapConfig = zap.NewDevelopmentConfig()
+	}
+
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+
+	return zapConfig.Build()
+}
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "surf"
+
+	resultSuccess = "success"
+	resultError   = "error"
+	resultHit     = "hit"
+	resultMiss    = "miss"
+)
+
+// Metrics owns a dedicated Prometheus registry so tests and multiple servers in the
+// same process do not share state.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	datasetRecords      *prometheus.GaugeVec
+	datasetLoads        *prometheus.CounterVec
+	computationDuration *prometheus.HistogramVec
+	cacheLookups        *prometheus.CounterVec
+	rejectedRequests    *prometheus.CounterVec
+	inFlightRequests    *prometheus.GaugeVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "http_requests_total",
+				Help:      "HTTP requests by method, chi route pattern and status code.",
+			},
+			[]string{"method", "route", "status"},
+		),
+		httpRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "http_request_duration_seconds",
+				Help:      "HTTP request latency by method, chi route pattern and status code.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"method", "route", "status"},
+		),
+		datasetRecords: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "dataset_records",
+				Help:      "Number of records in each loaded dataset.",
+			},
+			[]string{"dataset"},
+		),
+		datasetLoads: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "dataset_loads_total",
+				Help:      "Dataset (re)loads by dataset and result.",
+			},
+			[]string{"dataset", "result"},
+		),
+		computationDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "computation_duration_seconds",
+				Help:      "Time spent computing analytics by operation and result.",
+				Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+			},
+			[]string{"operation", "result"},
+		),
+		cacheLookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "cache_lookups_total",
+				Help:      "Cache lookups by cache and result (hit or miss); hit ratio is hit / total.",
+			},
+			[]string{"cache", "result"},
+		),
+		rejectedRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "rate_limited_requests_total",
+				Help:      "Requests rejected with 429 by reason (rate or concurrency).",
+			},
+			[]string{"reason"},
+		),
+		inFlightRequests: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "gate_in_flight_requests",
+				Help:      "Requests currently holding a slot of each concurrency gate.",
+			},
+			[]string{"gate"},
+		),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.datasetRecords,
+		m.datasetLoads,
+		m.computationDuration,
+		m.cacheLookups,
+		m.rejectedRequests,
+		m.inFlightRequests,
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// DatasetLoadObserver returns a hook for repositories to report each (re)load of dataset.
+func (m *Metrics) DatasetLoadObserver(dataset string) func(records int, err error) {
+	return func(records int, err error) {
+		if err != nil {
+			m.datasetLoads.WithLabelValues(dataset, resultError).Inc()
+
+			return
+		}
+
+		m.datasetLoads.WithLabelValues(dataset, resultSuccess).Inc()
+		m.datasetRecords.WithLabelValues(dataset).Set(float64(records))
+	}
+}
+
+func (m *Metrics) ObserveComputation(operation string, duration time.Duration, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+
+	m.computationDuration.WithLabelValues(operation, result).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveCacheLookup(cache string, hit bool) {
+	result := resultMiss
+	if hit {
+		result = resultHit
+	}
+
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+func (m *Metrics) ObserveRateLimited(reason string) {
+	m.rejectedRequests.WithLabelValues(reason).Inc()
+}
+
+// GateObserver returns a hook for a concurrency gate to report its in-flight requests.
+func (m *Metrics) GateObserver(gate string) func(delta int) {
+	return func(delta int) {
+		m.inFlightRequests.WithLabelValues(gate).Add(float64(delta))
+	}
+}
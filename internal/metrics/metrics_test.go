@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Metrics_Middleware(t *testing.T) {
+	m := New()
+
+	router := chi.NewRouter()
+	router.Use(m.Middleware)
+	router.Get(
+		"/users/{userId}", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+	)
+	router.Get("/ok", func(http.ResponseWriter, *http.Request) {})
+
+	for _, path := range []string{"/users/1", "/users/2", "/ok", "/missing"} {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.InDelta(t, 2, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/users/{userId}", "404")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/ok", "200")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", routeUnmatched, "404")), 0)
+	assert.Equal(t, 3, testutil.CollectAndCount(m.httpRequestDuration))
+}
+
+func Test_Metrics_Handler(t *testing.T) {
+	m := New()
+
+	m.DatasetLoadObserver("actions")(42, nil)
+	m.DatasetLoadObserver("users")(0, assert.AnError)
+	m.ObserveComputation("users_referrals", 10*time.Millisecond, nil)
+	m.ObserveCacheLookup("analytics", true)
+	m.ObserveCacheLookup("analytics", false)
+	m.ObserveRateLimited("rate")
+	m.GateObserver("analytics")(1)
+	m.GateObserver("analytics")(1)
+	m.GateObserver("analytics")(-1)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/metrics", nil)
+	m.Handler().ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	for _, want := range []string{
+		`surf_dataset_records{dataset="actions"} 42`,
+		`surf_dataset_loads_total{dataset="actions",result="success"} 1`,
+		`surf_dataset_loads_total{dataset="users",result="error"} 1`,
+		`surf_computation_duration_seconds_count{operation="users_referrals",result="success"} 1`,
+		`surf_cache_lookups_total{cache="analytics",result="hit"} 1`,
+		`surf_cache_lookups_total{cache="analytics",result="miss"} 1`,
+		`surf_rate_limited_requests_total{reason="rate"} 1`,
+		`surf_gate_in_flight_requests{gate="analytics"} 1`,
+		`go_goroutines`,
+	} {
+		assert.True(t, strings.Contains(body, want), "missing %q", want)
+	}
+}
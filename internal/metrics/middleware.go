@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// routeUnmatched labels requests that did not match any route, keeping the route
+// label cardinality bounded to the patterns registered in the router.
+const routeUnmatched = "unmatched"
+
+// Middleware records request count and latency labelled by the chi route pattern,
+// e.g. /api/v1/users/{userId}, rather than the raw path.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			route := routeUnmatched
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			m.ObserveHTTPRequest(r.Method, route, status, time.Since(start))
+		},
+	)
+}
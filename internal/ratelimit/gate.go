@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Gate bounds the number of requests running an expensive operation at once. Callers
+// wait up to the queue timeout for a slot.
+type Gate struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+	onChange     func(delta int)
+}
+
+type GateOption func(*Gate)
+
+// WithInFlightObserver registers a hook called with +1 when a slot is taken and -1 when
+// it is released.
+func WithInFlightObserver(observer func(delta int)) GateOption {
+	return func(g *Gate) {
+		g.onChange = observer
+	}
+}
+
+func NewGate(limit int, queueTimeout time.Duration, opts ...GateOption) *Gate {
+	g := &Gate{
+		slots:        make(chan struct{}, limit),
+		queueTimeout: queueTimeout,
+		onChange:     func(int) {},
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// Acquire waits for a slot and returns the function releasing it. It returns false
+// when no slot freed up within the queue timeout or ctx was cancelled.
+func (g *Gate) Acquire(ctx context.Context) (func(), bool) {
+	select {
+	case g.slots <- struct{}{}:
+		return g.acquired(), true
+	default:
+	}
+
+	timer := time.NewTimer(g.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case g.slots <- struct{}{}:
+		return g.acquired(), true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+func (g *Gate) acquired() func() {
+	g.onChange(1)
+
+	return func() {
+		<-g.slots
+		g.onChange(-1)
+	}
+}
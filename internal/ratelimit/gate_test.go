@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Gate_Acquire(t *testing.T) {
+	var inFlight atomic.Int64
+
+	gate := NewGate(1, 50*time.Millisecond, WithInFlightObserver(func(delta int) { inFlight.Add(int64(delta)) }))
+
+	release, ok := gate.Acquire(t.Context())
+	require.True(t, ok)
+	assert.Equal(t, int64(1), inFlight.Load())
+
+	_, ok = gate.Acquire(t.Context())
+	assert.False(t, ok, "should time out while the slot is held")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, ok = gate.Acquire(ctx)
+	assert.False(t, ok, "should give up when the request is cancelled")
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		release()
+	}()
+
+	release, ok = gate.Acquire(t.Context())
+	require.True(t, ok, "should get the slot once released")
+
+	release()
+	assert.Eventually(t, func() bool { return inFlight.Load() == 0 }, time.Second, time.Millisecond)
+}
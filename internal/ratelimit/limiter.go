@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// ReasonRate labels requests rejected because the client exhausted its bucket.
+	ReasonRate = "rate"
+	// ReasonConcurrency labels requests rejected because a Gate stayed full.
+	ReasonConcurrency = "concurrency"
+	// ReasonAuth labels requests rejected because their remote IP failed to authenticate
+	// too often.
+	ReasonAuth = "auth"
+)
+
+// Limiter keeps one token bucket per client key. The number of tracked clients is
+// bounded: when full, clients whose bucket refilled are forgotten first, since a new
+// bucket behaves the same, then the least recently seen ones, so that flooding new keys
+// cannot reset the bucket of an active client.
+type Limiter struct {
+	mu         sync.Mutex
+	limit      rate.Limit
+	burst      int
+	maxClients int
+	clients    map[string]*list.Element
+	// recent orders the clients from the most to the least recently seen.
+	recent *list.List
+	now    func() time.Time
+}
+
+type client struct {
+	key    string
+	bucket *rate.Limiter
+}
+
+func NewLimiter(requestsPerSecond float64, burst, maxClients int) *Limiter {
+	return &Limiter{
+		limit:      rate.Limit(requestsPerSecond),
+		burst:      burst,
+		maxClients: maxClients,
+		clients:    make(map[string]*list.Element),
+		recent:     list.New(),
+		now:        time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key. When the bucket is empty it returns false
+// with the time until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	element, ok := l.clients[key]
+	if ok {
+		l.recent.MoveToFront(element)
+	} else {
+		if len(l.clients) >= l.maxClients {
+			l.evict(now)
+		}
+
+		element = l.recent.PushFront(&client{key: key, bucket: rate.NewLimiter(l.limit, l.burst)})
+		l.clients[key] = element
+	}
+
+	reservation := element.Value.(*client).bucket.ReserveN(now, 1)
+
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// Exhausted reports whether the bucket of key is empty, with the time until the next
+// token, without taking one.
+func (l *Limiter) Exhausted(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.clients[key]
+	if !ok {
+		return false, 0
+	}
+
+	now := l.now()
+	bucket := element.Value.(*client).bucket
+
+	tokens := bucket.TokensAt(now)
+	if tokens >= 1 {
+		return false, 0
+	}
+
+	return true, time.Duration((1 - tokens) / float64(l.limit) * float64(time.Second))
+}
+
+func (l *Limiter) evict(now time.Time) {
+	for key, element := range l.clients {
+		if element.Value.(*client).bucket.TokensAt(now) >= float64(l.burst) {
+			l.remove(key, element)
+		}
+	}
+
+	// Every tracked client is active: drop the least recently seen rather than growing.
+	for len(l.clients) >= l.maxClients {
+		oldest := l.recent.Back()
+		l.remove(oldest.Value.(*client).key, oldest)
+	}
+}
+
+func (l *Limiter) remove(key string, element *list.Element) {
+	l.recent.Remove(element)
+	delete(l.clients, key)
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Limiter_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewLimiter(2, 2, 10)
+	limiter.now = func() time.Time { return now }
+
+	for range 2 {
+		ok, _ := limiter.Allow("a")
+		assert.True(t, ok)
+	}
+
+	ok, retryAfter := limiter.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	ok, _ = limiter.Allow("b")
+	assert.True(t, ok, "clients have their own bucket")
+
+	now = now.Add(500 * time.Millisecond)
+
+	ok, _ = limiter.Allow("a")
+	assert.True(t, ok, "a token refilled")
+}
+
+func Test_Limiter_evicts_when_full(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewLimiter(1, 1, 2)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("a")
+	limiter.Allow("b")
+
+	now = now.Add(time.Second)
+
+	ok, _ := limiter.Allow("c")
+	assert.True(t, ok)
+	assert.Len(t, limiter.clients, 1, "refilled buckets are forgotten first")
+
+	limiter.Allow("d")
+	limiter.Allow("e")
+	assert.LessOrEqual(t, len(limiter.clients), 2)
+}
+
+func Test_Limiter_evicts_the_least_recently_seen(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewLimiter(1, 1, 2)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("victim")
+	limiter.Allow("flood-1")
+	limiter.Allow("victim")
+
+	ok, _ := limiter.Allow("flood-2")
+	assert.True(t, ok)
+
+	ok, _ = limiter.Allow("victim")
+	assert.False(t, ok, "the bucket of the recently seen client should be kept")
+	assert.Len(t, limiter.clients, 2)
+}
+
+func Test_Limiter_Exhausted(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewLimiter(2, 1, 10)
+	limiter.now = func() time.Time { return now }
+
+	exhausted, _ := limiter.Exhausted("a")
+	assert.False(t, exhausted, "an unknown client has a full bucket")
+	assert.Empty(t, limiter.clients, "checking should not track the client")
+
+	limiter.Allow("a")
+
+	exhausted, retryAfter := limiter.Exhausted("a")
+	assert.True(t, exhausted)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+
+	exhausted, _ = limiter.Exhausted("a")
+	assert.False(t, exhausted)
+}
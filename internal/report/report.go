@@ -0,0 +1,540 @@
+// Package report computes the analytics of the datasets in one run and writes them as
+// JSON, CSV and Markdown files, so that batch jobs do not need the server.
+package report
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/action"
+	actiondomain "surf_challenge/internal/action/domain"
+	actionstorage "surf_challenge/internal/action/storage"
+	userdomain "surf_challenge/internal/user/domain"
+	"surf_challenge/internal/user/mapper"
+	userstorage "surf_challenge/internal/user/storage"
+)
+
+// StepSignup is the first funnel step, reached by every user.
+const StepSignup = "SIGNUP"
+
+// DefaultFunnel is the funnel measured when none is given, the onboarding path from
+// the welcome screen to the first referral.
+var DefaultFunnel = []string{
+	actiondomain.ActionTypeWelcome, actiondomain.ActionTypeViewContacts, actiondomain.ActionTypeAddContact,
+	actiondomain.ActionTypeEditContact, actiondomain.ActionTypeReferUser,
+}
+
+// Options select what the report measures.
+type Options struct {
+	// GeneratedAt stamps the report and names its directory.
+	GeneratedAt time.Time
+	// Funnel lists the action types of the funnel steps, in order.
+	Funnel []string
+	// RetentionPeriods is the number of months after signup measured per cohort.
+	RetentionPeriods int
+}
+
+// Report holds the analytics of the datasets.
+type Report struct {
+	GeneratedAt time.Time        `json:"generatedAt"`
+	Datasets    Datasets         `json:"datasets"`
+	Activity    Activity         `json:"activity"`
+	Transitions TransitionMatrix `json:"transitions"`
+	Referrals   []Referral       `json:"referrals"`
+	Funnel      []FunnelStep     `json:"funnel"`
+	Retention   []Cohort         `json:"retention"`
+}
+
+// Datasets describes the data the report was computed from.
+type Datasets struct {
+	Users          int    `json:"users"`
+	Actions        int    `json:"actions"`
+	ActionsVersion string `json:"actionsVersion"`
+}
+
+// Activity summarizes the actions.
+type Activity struct {
+	Users          int             `json:"users"`
+	ActiveUsers    int             `json:"activeUsers"`
+	Actions        int             `json:"actions"`
+	FirstAction    *time.Time      `json:"firstAction,omitempty"`
+	LastAction     *time.Time      `json:"lastAction,omitempty"`
+	ActionsPerUser Distribution    `json:"actionsPerUser"`
+	ByType         []TypeActivity  `json:"byType"`
+	ByMonth        []MonthActivity `json:"byMonth"`
+}
+
+// Distribution summarizes the number of actions of the active users.
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	Median int     `json:"median"`
+	P90    int     `json:"p90"`
+	Max    int     `json:"max"`
+}
+
+// TypeActivity counts the actions of a type and the users who performed it.
+type TypeActivity struct {
+	Type    string `json:"type"`
+	Actions int    `json:"actions"`
+	Users   int    `json:"users"`
+}
+
+// MonthActivity counts the signups and actions of a month, formatted as YYYY-MM.
+type MonthActivity struct {
+	Month       string `json:"month"`
+	Signups     int    `json:"signups"`
+	Actions     int    `json:"actions"`
+	ActiveUsers int    `json:"activeUsers"`
+}
+
+// TransitionMatrix counts how often each action type follows another one in the
+// chronological actions of a user. Probabilities are given per row.
+type TransitionMatrix struct {
+	Types []string     `json:"types"`
+	Rows  []Transition `json:"rows"`
+}
+
+// Transition is a row of the matrix, Counts and Probabilities being indexed like Types.
+type Transition struct {
+	From          string    `json:"from"`
+	Total         int       `json:"total"`
+	Counts        []int     `json:"counts"`
+	Probabilities []float64 `json:"probabilities"`
+}
+
+// Referral is the referral index of a user: the users they referred directly, and
+// all the users down their referral tree.
+type Referral struct {
+	UserID int `json:"userId"`
+	Direct int `json:"direct"`
+	Total  int `json:"total"`
+}
+
+// FunnelStep counts the users who performed the step action after reaching the
+// previous step.
+type FunnelStep struct {
+	Step           string  `json:"step"`
+	Users          int     `json:"users"`
+	FromPrevious   float64 `json:"fromPrevious"`
+	FromFirstStep  float64 `json:"fromFirstStep"`
+	MedianDuration string  `json:"medianDuration,omitempty"`
+}
+
+// Cohort gathers the users who signed up in a month, and the share of them active
+// in each following month. Months after the last action are left out.
+type Cohort struct {
+	Month   string            `json:"month"`
+	Users   int               `json:"users"`
+	Periods []RetentionPeriod `json:"periods"`
+}
+
+// RetentionPeriod counts the users of a cohort active Period months after signup.
+type RetentionPeriod struct {
+	Period      int     `json:"period"`
+	ActiveUsers int     `json:"activeUsers"`
+	Rate        float64 `json:"rate"`
+}
+
+// ErrInvalidOptions is returned for options the report cannot be computed with.
+var ErrInvalidOptions = errors.New("invalid report options")
+
+const monthLayout = "2006-01"
+
+// New loads the datasets through the repositories and computes the report.
+func New(
+	ctx context.Context, logger *zap.SugaredLogger, users userstorage.Repository, actions actionstorage.Repository, opts Options,
+) (*Report, error) {
+	if len(opts.Funnel) == 0 {
+		return nil, fmt.Errorf("%w: the funnel needs at least one step", ErrInvalidOptions)
+	}
+
+	if opts.RetentionPeriods < 1 {
+		return nil, fmt.Errorf("%w: retention periods must be positive, got %d", ErrInvalidOptions, opts.RetentionPeriods)
+	}
+
+	domainUsers, err := loadUsers(ctx, users)
+	if err != nil {
+		return nil, err
+	}
+
+	service := action.NewService(logger, actions)
+
+	domainActions, err := service.ListActions(ctx, actiondomain.Filter{})
+	if err != nil {
+		return nil, fmt.Errorf("loading actions: %w", err)
+	}
+
+	version, err := actions.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading actions version: %w", err)
+	}
+
+	totals, err := service.GetUsersReferrals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("computing referrals: %w", err)
+	}
+
+	ids := make([]int64, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, int64(id))
+	}
+
+	direct, err := service.GetReferredUsers(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("computing referrals: %w", err)
+	}
+
+	r := Build(domainUsers, domainActions, opts)
+	r.Datasets.ActionsVersion = version
+	r.Referrals = referrals(totals, direct)
+
+	return r, nil
+}
+
+func loadUsers(ctx context.Context, repo userstorage.Repository) ([]*userdomain.User, error) {
+	count, err := repo.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading users: %w", err)
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	entities, _, err := repo.QueryUsers(ctx, nil, 1, count)
+	if err != nil {
+		return nil, fmt.Errorf("loading users: %w", err)
+	}
+
+	users, err := mapper.MapUsersEntToDomain(entities)
+	if err != nil {
+		return nil, fmt.Errorf("loading users: %w", err)
+	}
+
+	return users, nil
+}
+
+// Build computes the report over users and their actions, given in chronological
+// order. The referral index is left to New, which reuses the action service graph.
+func Build(users []*userdomain.User, actions []*actiondomain.Action, opts Options) *Report {
+	byUser := make(map[int][]*actiondomain.Action)
+	for _, a := range actions {
+		byUser[a.UserID] = append(byUser[a.UserID], a)
+	}
+
+	return &Report{
+		GeneratedAt: opts.GeneratedAt,
+		Datasets:    Datasets{Users: len(users), Actions: len(actions)},
+		Activity:    activity(users, actions, byUser),
+		Transitions: transitions(actions, byUser),
+		Referrals:   []Referral{},
+		Funnel:      funnel(users, byUser, opts.Funnel),
+		Retention:   retention(users, actions, byUser, opts.RetentionPeriods),
+	}
+}
+
+func activity(users []*userdomain.User, actions []*actiondomain.Action, byUser map[int][]*actiondomain.Action) Activity {
+	a := Activity{
+		Users:       len(users),
+		ActiveUsers: len(byUser),
+		Actions:     len(actions),
+		ByType:      []TypeActivity{},
+		ByMonth:     []MonthActivity{},
+	}
+
+	if len(actions) > 0 {
+		first, last := actions[0].CreatedAt, actions[len(actions)-1].CreatedAt
+		a.FirstAction, a.LastAction = &first, &last
+	}
+
+	perUser := make([]int, 0, len(byUser))
+	for _, acts := range byUser {
+		perUser = append(perUser, len(acts))
+	}
+
+	a.ActionsPerUser = distribution(perUser)
+
+	types := make(map[string]*TypeActivity)
+	typeUsers := make(map[string]map[int]struct{})
+	months := make(map[string]*MonthActivity)
+	monthUsers := make(map[string]map[int]struct{})
+
+	month := func(key string) *MonthActivity {
+		m, ok := months[key]
+		if !ok {
+			m = &MonthActivity{Month: key}
+			months[key] = m
+			monthUsers[key] = make(map[int]struct{})
+		}
+
+		return m
+	}
+
+	for _, u := range users {
+		month(u.CreatedAt.UTC().Format(monthLayout)).Signups++
+	}
+
+	for _, act := range actions {
+		t, ok := types[act.Type]
+		if !ok {
+			t = &TypeActivity{Type: act.Type}
+			types[act.Type] = t
+			typeUsers[act.Type] = make(map[int]struct{})
+		}
+
+		t.Actions++
+		typeUsers[act.Type][act.UserID] = struct{}{}
+
+		key := act.CreatedAt.UTC().Format(monthLayout)
+		month(key).Actions++
+		monthUsers[key][act.UserID] = struct{}{}
+	}
+
+	for typ, t := range types {
+		t.Users = len(typeUsers[typ])
+		a.ByType = append(a.ByType, *t)
+	}
+
+	slices.SortFunc(
+		a.ByType, func(x, y TypeActivity) int {
+			return cmp.Or(y.Actions-x.Actions, strings.Compare(x.Type, y.Type))
+		},
+	)
+
+	for key, m := range months {
+		m.ActiveUsers = len(monthUsers[key])
+		a.ByMonth = append(a.ByMonth, *m)
+	}
+
+	slices.SortFunc(
+		a.ByMonth, func(x, y MonthActivity) int {
+			return strings.Compare(x.Month, y.Month)
+		},
+	)
+
+	return a
+}
+
+func distribution(values []int) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	slices.Sort(values)
+
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+
+	return Distribution{
+		Mean:   round(float64(sum) / float64(len(values))),
+		Median: values[(len(values)-1)/2],
+		P90:    values[(len(values)*9+9)/10-1],
+		Max:    values[len(values)-1],
+	}
+}
+
+// transitions counts consecutive actions per user, like the next action probability
+// of the action service, over every action type at once.
+func transitions(actions []*actiondomain.Action, byUser map[int][]*actiondomain.Action) TransitionMatrix {
+	types := make([]string, 0)
+	for _, a := range actions {
+		if !slices.Contains(types, a.Type) {
+			types = append(types, a.Type)
+		}
+	}
+
+	slices.Sort(types)
+
+	rows := make([]Transition, len(types))
+	for i, typ := range types {
+		rows[i] = Transition{From: typ, Counts: make([]int, len(types)), Probabilities: make([]float64, len(types))}
+	}
+
+	for _, acts := range byUser {
+		for i := 0; i+1 < len(acts); i++ {
+			from, _ := slices.BinarySearch(types, acts[i].Type)
+			to, _ := slices.BinarySearch(types, acts[i+1].Type)
+
+			rows[from].Counts[to]++
+			rows[from].Total++
+		}
+	}
+
+	for i := range rows {
+		for j, count := range rows[i].Counts {
+			if rows[i].Total > 0 {
+				rows[i].Probabilities[j] = round(float64(count) / float64(rows[i].Total))
+			}
+		}
+	}
+
+	return TransitionMatrix{Types: types, Rows: rows}
+}
+
+func referrals(totals map[int]int, direct map[int64][]int64) []Referral {
+	index := make([]Referral, 0, len(totals))
+	for userID, total := range totals {
+		index = append(index, Referral{UserID: userID, Direct: len(direct[int64(userID)]), Total: total})
+	}
+
+	slices.SortFunc(
+		index, func(a, b Referral) int {
+			return cmp.Or(b.Total-a.Total, b.Direct-a.Direct, a.UserID-b.UserID)
+		},
+	)
+
+	return index
+}
+
+// funnel follows each user through the steps, a step being reached by the first
+// action of its type after the previous step was reached.
+func funnel(users []*userdomain.User, byUser map[int][]*actiondomain.Action, steps []string) []FunnelStep {
+	reached := make([]int, len(steps)+1)
+	durations := make([][]time.Duration, len(steps)+1)
+
+	for _, u := range users {
+		reached[0]++
+
+		at := u.CreatedAt
+		step := 0
+
+		for _, act := range byUser[int(u.ID)] {
+			if step == len(steps) {
+				break
+			}
+
+			if !strings.EqualFold(act.Type, steps[step]) || act.CreatedAt.Before(at) {
+				continue
+			}
+
+			durations[step+1] = append(durations[step+1], act.CreatedAt.Sub(at))
+			at = act.CreatedAt
+			step++
+			reached[step]++
+		}
+	}
+
+	result := make([]FunnelStep, len(steps)+1)
+
+	for i := range result {
+		name := StepSignup
+		if i > 0 {
+			name = steps[i-1]
+		}
+
+		result[i] = FunnelStep{Step: name, Users: reached[i], FromPrevious: 1, FromFirstStep: 1}
+
+		if i > 0 {
+			result[i].FromPrevious = rate(reached[i], reached[i-1])
+			result[i].FromFirstStep = rate(reached[i], reached[0])
+			result[i].MedianDuration = medianDuration(durations[i])
+		}
+	}
+
+	return result
+}
+
+func medianDuration(durations []time.Duration) string {
+	if len(durations) == 0 {
+		return ""
+	}
+
+	slices.Sort(durations)
+
+	return durations[(len(durations)-1)/2].Round(time.Second).String()
+}
+
+// retention groups the users by signup month and counts, for the months following
+// it up to the last action, the users of each cohort with at least one action.
+func retention(
+	users []*userdomain.User, actions []*actiondomain.Action, byUser map[int][]*actiondomain.Action, periods int,
+) []Cohort {
+	cohorts := []Cohort{}
+	if len(actions) == 0 {
+		return cohorts
+	}
+
+	lastMonth := monthIndex(actions[len(actions)-1].CreatedAt)
+
+	type cohort struct {
+		users  int
+		active []map[int64]struct{}
+	}
+
+	byMonth := make(map[int]*cohort)
+
+	for _, u := range users {
+		signup := monthIndex(u.CreatedAt)
+
+		c, ok := byMonth[signup]
+		if !ok {
+			c = &cohort{active: make([]map[int64]struct{}, max(0, min(periods, lastMonth-signup+1)))}
+			for i := range c.active {
+				c.active[i] = make(map[int64]struct{})
+			}
+
+			byMonth[signup] = c
+		}
+
+		c.users++
+
+		for _, act := range byUser[int(u.ID)] {
+			period := monthIndex(act.CreatedAt) - signup
+			if period >= 0 && period < len(c.active) {
+				c.active[period][u.ID] = struct{}{}
+			}
+		}
+	}
+
+	for signup, c := range byMonth {
+		entry := Cohort{
+			Month:   time.Date(signup/12, time.Month(signup%12+1), 1, 0, 0, 0, 0, time.UTC).Format(monthLayout),
+			Users:   c.users,
+			Periods: make([]RetentionPeriod, len(c.active)),
+		}
+
+		for period, active := range c.active {
+			entry.Periods[period] = RetentionPeriod{Period: period, ActiveUsers: len(active), Rate: rate(len(active), c.users)}
+		}
+
+		cohorts = append(cohorts, entry)
+	}
+
+	slices.SortFunc(
+		cohorts, func(a, b Cohort) int {
+			return strings.Compare(a.Month, b.Month)
+		},
+	)
+
+	return cohorts
+}
+
+func monthIndex(t time.Time) int {
+	t = t.UTC()
+
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return round(float64(count) / float64(total))
+}
+
+// round keeps four decimals, enough for rates printed as percentages.
+func round(v float64) float64 {
+	const precision = 10000
+
+	return float64(int64(v*precision+0.5)) / precision
+}
@@ -0,0 +1,191 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	actionstorage "surf_challenge/internal/action/storage"
+	userstorage "surf_challenge/internal/user/storage"
+)
+
+const (
+	testUsers = `[
+		{"id":1,"name":"Ada","createdAt":"2021-01-01T00:00:00Z"},
+		{"id":2,"name":"Bob","createdAt":"2021-01-15T00:00:00Z"},
+		{"id":3,"name":"Cy","createdAt":"2021-02-01T00:00:00Z"}
+	]`
+	testActions = `[
+		{"id":1,"type":"WELCOME","userId":1,"createdAt":"2021-01-01T01:00:00Z"},
+		{"id":2,"type":"ADD_CONTACT","userId":1,"createdAt":"2021-01-02T01:00:00Z"},
+		{"id":3,"type":"REFER_USER","userId":1,"targetUser":2,"createdAt":"2021-01-03T01:00:00Z"},
+		{"id":4,"type":"WELCOME","userId":2,"createdAt":"2021-01-15T02:00:00Z"},
+		{"id":5,"type":"REFER_USER","userId":2,"targetUser":3,"createdAt":"2021-02-10T00:00:00Z"},
+		{"id":6,"type":"ADD_CONTACT","userId":1,"createdAt":"2021-03-01T00:00:00Z"}
+	]`
+)
+
+var generatedAt = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func writeDatasets(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "users.json")
+	actionsFile := filepath.Join(dir, "actions.json")
+
+	require.NoError(t, os.WriteFile(usersFile, []byte(testUsers), 0o600))
+	require.NoError(t, os.WriteFile(actionsFile, []byte(testActions), 0o600))
+
+	return usersFile, actionsFile
+}
+
+func newReport(t *testing.T, opts Options) *Report {
+	t.Helper()
+
+	usersFile, actionsFile := writeDatasets(t)
+
+	r, err := New(
+		t.Context(), zap.NewNop().Sugar(), userstorage.NewRepository(usersFile), actionstorage.NewRepository(actionsFile), opts,
+	)
+	require.NoError(t, err)
+
+	return r
+}
+
+func Test_New(t *testing.T) {
+	r := newReport(t, Options{GeneratedAt: generatedAt, Funnel: []string{"WELCOME", "REFER_USER"}, RetentionPeriods: 3})
+
+	t.Run(
+		"should describe the datasets", func(t *testing.T) {
+			assert.Equal(t, generatedAt, r.GeneratedAt)
+			assert.Equal(t, 3, r.Datasets.Users)
+			assert.Equal(t, 6, r.Datasets.Actions)
+			assert.Len(t, r.Datasets.ActionsVersion, 16)
+		},
+	)
+
+	t.Run(
+		"should summarize the activity", func(t *testing.T) {
+			first := time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC)
+			last := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+			assert.Equal(
+				t, Activity{
+					Users:          3,
+					ActiveUsers:    2,
+					Actions:        6,
+					FirstAction:    &first,
+					LastAction:     &last,
+					ActionsPerUser: Distribution{Mean: 3, Median: 2, P90: 4, Max: 4},
+					ByType: []TypeActivity{
+						{Type: "ADD_CONTACT", Actions: 2, Users: 1},
+						{Type: "REFER_USER", Actions: 2, Users: 2},
+						{Type: "WELCOME", Actions: 2, Users: 2},
+					},
+					ByMonth: []MonthActivity{
+						{Month: "2021-01", Signups: 2, Actions: 4, ActiveUsers: 2},
+						{Month: "2021-02", Signups: 1, Actions: 1, ActiveUsers: 1},
+						{Month: "2021-03", Actions: 1, ActiveUsers: 1},
+					},
+				}, r.Activity,
+			)
+		},
+	)
+
+	t.Run(
+		"should count the transitions between consecutive actions of a user", func(t *testing.T) {
+			assert.Equal(
+				t, TransitionMatrix{
+					Types: []string{"ADD_CONTACT", "REFER_USER", "WELCOME"},
+					Rows: []Transition{
+						{From: "ADD_CONTACT", Total: 1, Counts: []int{0, 1, 0}, Probabilities: []float64{0, 1, 0}},
+						{From: "REFER_USER", Total: 1, Counts: []int{1, 0, 0}, Probabilities: []float64{1, 0, 0}},
+						{From: "WELCOME", Total: 2, Counts: []int{1, 1, 0}, Probabilities: []float64{0.5, 0.5, 0}},
+					},
+				}, r.Transitions,
+			)
+		},
+	)
+
+	t.Run(
+		"should index direct and transitive referrals", func(t *testing.T) {
+			assert.Equal(t, []Referral{{UserID: 1, Direct: 1, Total: 2}, {UserID: 2, Direct: 1, Total: 1}}, r.Referrals)
+		},
+	)
+
+	t.Run(
+		"should follow the users through the funnel steps in order", func(t *testing.T) {
+			assert.Equal(
+				t, []FunnelStep{
+					{Step: StepSignup, Users: 3, FromPrevious: 1, FromFirstStep: 1},
+					{Step: "WELCOME", Users: 2, FromPrevious: 0.6667, FromFirstStep: 0.6667, MedianDuration: "1h0m0s"},
+					{Step: "REFER_USER", Users: 2, FromPrevious: 1, FromFirstStep: 0.6667, MedianDuration: "48h0m0s"},
+				}, r.Funnel,
+			)
+		},
+	)
+
+	t.Run(
+		"should measure the retention of the signup cohorts up to the last action", func(t *testing.T) {
+			assert.Equal(
+				t, []Cohort{
+					{
+						Month: "2021-01", Users: 2, Periods: []RetentionPeriod{
+							{Period: 0, ActiveUsers: 2, Rate: 1},
+							{Period: 1, ActiveUsers: 1, Rate: 0.5},
+							{Period: 2, ActiveUsers: 1, Rate: 0.5},
+						},
+					},
+					{
+						Month: "2021-02", Users: 1, Periods: []RetentionPeriod{
+							{Period: 0, ActiveUsers: 0, Rate: 0},
+							{Period: 1, ActiveUsers: 0, Rate: 0},
+						},
+					},
+				}, r.Retention,
+			)
+		},
+	)
+}
+
+func Test_New_invalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "should reject an empty funnel",
+			opts: Options{RetentionPeriods: 1},
+		},
+		{
+			name: "should reject non-positive retention periods",
+			opts: Options{Funnel: DefaultFunnel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := New(t.Context(), zap.NewNop().Sugar(), userstorage.NewRepository(""), actionstorage.NewRepository(""), tt.opts)
+
+				assert.ErrorIs(t, err, ErrInvalidOptions)
+			},
+		)
+	}
+}
+
+func Test_Build_emptyDatasets(t *testing.T) {
+	r := Build(nil, nil, Options{Funnel: DefaultFunnel, RetentionPeriods: 6})
+
+	assert.Equal(t, 0, r.Activity.Actions)
+	assert.Nil(t, r.Activity.FirstAction)
+	assert.Empty(t, r.Transitions.Rows)
+	assert.Empty(t, r.Retention)
+	assert.Len(t, r.Funnel, len(DefaultFunnel)+1)
+	assert.Zero(t, r.Funnel[1].FromPrevious)
+}
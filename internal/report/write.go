@@ -0,0 +1,364 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"surf_challenge/internal/api/response"
+)
+
+// SchemaVersion is the version of the report file layout, bumped on breaking changes.
+const SchemaVersion = 1
+
+// DirLayout formats the generation time into the name of the report directory.
+const DirLayout = "20060102T150405Z"
+
+// Files of a report directory, listed in the manifest.
+const (
+	FileManifest       = "manifest.json"
+	FileReport         = "report.json"
+	FileMarkdown       = "report.md"
+	FileActivityByType = "activity_by_type.csv"
+	FileActivityMonth  = "activity_by_month.csv"
+	FileTransitions    = "transitions.csv"
+	FileReferrals      = "referrals.csv"
+	FileFunnel         = "funnel.csv"
+	FileRetention      = "retention.csv"
+)
+
+// ErrReportExists is returned when a report was already written for the same time.
+var ErrReportExists = errors.New("report already exists")
+
+// Manifest describes a report directory.
+type Manifest struct {
+	SchemaVersion int      `json:"schemaVersion"`
+	GeneratedAt   string   `json:"generatedAt"`
+	Datasets      Datasets `json:"datasets"`
+	Files         []string `json:"files"`
+}
+
+type file struct {
+	name  string
+	write func(w io.Writer) error
+}
+
+// Write writes the report into a new directory of dir, named after the generation
+// time, and returns its path and manifest. Files are written to a temporary directory
+// renamed once complete, so readers never see a partial report.
+func Write(dir string, r *Report) (string, *Manifest, error) {
+	target := filepath.Join(dir, r.GeneratedAt.UTC().Format(DirLayout))
+
+	_, err := os.Stat(target)
+	if err == nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrReportExists, target)
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(dir, ".report-")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+
+	files := []file{
+		{FileReport, func(w io.Writer) error { return writeJSON(w, r) }},
+		{FileMarkdown, func(w io.Writer) error { return writeMarkdown(w, r) }},
+		{FileActivityByType, func(w io.Writer) error { return writeCSV(w, activityByTypeTable(r)) }},
+		{FileActivityMonth, func(w io.Writer) error { return writeCSV(w, activityByMonthTable(r)) }},
+		{FileTransitions, func(w io.Writer) error { return writeCSV(w, transitionsTable(r)) }},
+		{FileReferrals, func(w io.Writer) error { return writeCSV(w, referralsTable(r)) }},
+		{FileFunnel, func(w io.Writer) error { return writeCSV(w, funnelTable(r)) }},
+		{FileRetention, func(w io.Writer) error { return writeCSV(w, retentionTable(r)) }},
+	}
+
+	manifest := &Manifest{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   r.GeneratedAt.UTC().Format(time.RFC3339),
+		Datasets:      r.Datasets,
+		Files:         []string{FileManifest},
+	}
+
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.name)
+	}
+
+	files = append(files, file{FileManifest, func(w io.Writer) error { return writeJSON(w, manifest) }})
+
+	for _, f := range files {
+		err = writeFile(filepath.Join(tmp, f.name), f.write)
+		if err != nil {
+			return "", nil, fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+
+	err = os.Chmod(tmp, 0o755)
+	if err != nil {
+		return "", nil, fmt.Errorf("writing report: %w", err)
+	}
+
+	err = os.Rename(tmp, target)
+	if err != nil {
+		return "", nil, fmt.Errorf("writing report: %w", err)
+	}
+
+	return target, manifest, nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(f)
+	if err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// table is a CSV file or a Markdown table.
+type table struct {
+	columns []string
+	rows    [][]string
+}
+
+func writeCSV(w io.Writer, t table) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(t.columns)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range t.rows {
+		err = cw.Write(response.EscapeFormulas(row))
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func activityByTypeTable(r *Report) table {
+	t := table{columns: []string{"type", "actions", "users"}}
+	for _, a := range r.Activity.ByType {
+		t.rows = append(t.rows, []string{a.Type, strconv.Itoa(a.Actions), strconv.Itoa(a.Users)})
+	}
+
+	return t
+}
+
+func activityByMonthTable(r *Report) table {
+	t := table{columns: []string{"month", "signups", "actions", "activeUsers"}}
+	for _, m := range r.Activity.ByMonth {
+		t.rows = append(t.rows, []string{m.Month, strconv.Itoa(m.Signups), strconv.Itoa(m.Actions), strconv.Itoa(m.ActiveUsers)})
+	}
+
+	return t
+}
+
+// transitionsTable lays the matrix out as one row per pair, with zero counts, so that
+// it loads as a plain table.
+func transitionsTable(r *Report) table {
+	t := table{columns: []string{"from", "to", "count", "probability"}}
+
+	for _, row := range r.Transitions.Rows {
+		for i, to := range r.Transitions.Types {
+			t.rows = append(t.rows, []string{row.From, to, strconv.Itoa(row.Counts[i]), formatRate(row.Probabilities[i])})
+		}
+	}
+
+	return t
+}
+
+func referralsTable(r *Report) table {
+	t := table{columns: []string{"userId", "direct", "total"}}
+	for _, ref := range r.Referrals {
+		t.rows = append(t.rows, []string{strconv.Itoa(ref.UserID), strconv.Itoa(ref.Direct), strconv.Itoa(ref.Total)})
+	}
+
+	return t
+}
+
+func funnelTable(r *Report) table {
+	t := table{columns: []string{"step", "users", "fromPrevious", "fromFirstStep", "medianDuration"}}
+	for _, s := range r.Funnel {
+		t.rows = append(
+			t.rows, []string{s.Step, strconv.Itoa(s.Users), formatRate(s.FromPrevious), formatRate(s.FromFirstStep), s.MedianDuration},
+		)
+	}
+
+	return t
+}
+
+func retentionTable(r *Report) table {
+	t := table{columns: []string{"cohort", "users", "period", "activeUsers", "rate"}}
+
+	for _, c := range r.Retention {
+		for _, p := range c.Periods {
+			t.rows = append(
+				t.rows, []string{c.Month, strconv.Itoa(c.Users), strconv.Itoa(p.Period), strconv.Itoa(p.ActiveUsers), formatRate(p.Rate)},
+			)
+		}
+	}
+
+	return t
+}
+
+func formatRate(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func percent(v float64) string {
+	return strconv.FormatFloat(v*100, 'f', 1, 64) + "%"
+}
+
+// referralsListed bounds the referral index in the Markdown summary, the CSV file
+// holding all of it.
+const referralsListed = 20
+
+func writeMarkdown(w io.Writer, r *Report) error {
+	var b strings.Builder
+
+	a := r.Activity
+
+	fmt.Fprintf(&b, "# Surf report\n\nGenerated at %s", r.GeneratedAt.UTC().Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&b, " from %d users and %d actions", r.Datasets.Users, r.Datasets.Actions)
+
+	if r.Datasets.ActionsVersion != "" {
+		fmt.Fprintf(&b, " (actions version `%s`)", r.Datasets.ActionsVersion)
+	}
+
+	b.WriteString(".\n\n## Activity\n\n")
+
+	summary := table{columns: []string{"Metric", "Value"}, rows: [][]string{
+		{"Users", strconv.Itoa(a.Users)},
+		{"Active users", strconv.Itoa(a.ActiveUsers)},
+		{"Actions", strconv.Itoa(a.Actions)},
+		{"Actions per active user (mean)", strconv.FormatFloat(a.ActionsPerUser.Mean, 'f', 2, 64)},
+		{"Actions per active user (median)", strconv.Itoa(a.ActionsPerUser.Median)},
+		{"Actions per active user (p90)", strconv.Itoa(a.ActionsPerUser.P90)},
+		{"Actions per active user (max)", strconv.Itoa(a.ActionsPerUser.Max)},
+	}}
+
+	if a.FirstAction != nil {
+		summary.rows = append(
+			summary.rows,
+			[]string{"First action", a.FirstAction.UTC().Format("2006-01-02")},
+			[]string{"Last action", a.LastAction.UTC().Format("2006-01-02")},
+		)
+	}
+
+	writeMarkdownTable(&b, summary)
+	b.WriteString("\n### By type\n\n")
+	writeMarkdownTable(&b, activityByTypeTable(r))
+	b.WriteString("\n### By month\n\n")
+	writeMarkdownTable(&b, activityByMonthTable(r))
+
+	b.WriteString("\n## Transition matrix\n\nProbability of the next action of a user, per row.\n\n")
+
+	matrix := table{columns: append([]string{"From \\ To"}, r.Transitions.Types...)}
+	for _, row := range r.Transitions.Rows {
+		cells := []string{row.From}
+		for _, p := range row.Probabilities {
+			cells = append(cells, percent(p))
+		}
+
+		matrix.rows = append(matrix.rows, cells)
+	}
+
+	writeMarkdownTable(&b, matrix)
+
+	fmt.Fprintf(&b, "\n## Referral index\n\n%d users referred someone", len(r.Referrals))
+
+	if len(r.Referrals) > referralsListed {
+		fmt.Fprintf(&b, ", the top %d are listed, see `%s` for all of them", referralsListed, FileReferrals)
+	}
+
+	b.WriteString(".\n\n")
+
+	referrals := referralsTable(r)
+	referrals.rows = referrals.rows[:min(referralsListed, len(referrals.rows))]
+	writeMarkdownTable(&b, referrals)
+
+	b.WriteString("\n## Funnel\n\n")
+
+	funnel := table{columns: []string{"Step", "Users", "From previous", "From signup", "Median time from previous"}}
+	for _, s := range r.Funnel {
+		funnel.rows = append(
+			funnel.rows, []string{s.Step, strconv.Itoa(s.Users), percent(s.FromPrevious), percent(s.FromFirstStep), s.MedianDuration},
+		)
+	}
+
+	writeMarkdownTable(&b, funnel)
+
+	b.WriteString("\n## Retention\n\nShare of each signup cohort active in the months following signup, month 0 being the signup month.\n\n")
+
+	periods := 0
+	for _, c := range r.Retention {
+		periods = max(periods, len(c.Periods))
+	}
+
+	retention := table{columns: []string{"Cohort", "Users"}}
+	for p := range periods {
+		retention.columns = append(retention.columns, "M"+strconv.Itoa(p))
+	}
+
+	for _, c := range r.Retention {
+		cells := []string{c.Month, strconv.Itoa(c.Users)}
+		for p := range periods {
+			cell := ""
+			if p < len(c.Periods) {
+				cell = percent(c.Periods[p].Rate)
+			}
+
+			cells = append(cells, cell)
+		}
+
+		retention.rows = append(retention.rows, cells)
+	}
+
+	writeMarkdownTable(&b, retention)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeMarkdownTable(b *strings.Builder, t table) {
+	b.WriteString("| " + strings.Join(t.columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(t.columns)) + "\n")
+
+	for _, row := range t.rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Write(t *testing.T) {
+	r := newReport(t, Options{GeneratedAt: generatedAt, Funnel: []string{"WELCOME", "REFER_USER"}, RetentionPeriods: 3})
+	out := filepath.Join(t.TempDir(), "reports")
+
+	dir, manifest, err := Write(out, r)
+	require.NoError(t, err)
+
+	t.Run(
+		"should name the directory after the generation time", func(t *testing.T) {
+			assert.Equal(t, filepath.Join(out, "20220102T030405Z"), dir)
+
+			entries, err := os.ReadDir(out)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1, "the temporary directory is renamed")
+		},
+	)
+
+	t.Run(
+		"should write every file listed in the manifest", func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, FileManifest))
+			require.NoError(t, err)
+
+			var written Manifest
+			require.NoError(t, json.Unmarshal(data, &written))
+			assert.Equal(t, *manifest, written)
+			assert.Equal(t, SchemaVersion, written.SchemaVersion)
+			assert.Equal(t, "2022-01-02T03:04:05Z", written.GeneratedAt)
+
+			for _, name := range written.Files {
+				assert.FileExists(t, filepath.Join(dir, name))
+			}
+		},
+	)
+
+	t.Run(
+		"should write the report as JSON", func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, FileReport))
+			require.NoError(t, err)
+
+			var written Report
+			require.NoError(t, json.Unmarshal(data, &written))
+			assert.Equal(t, r.Funnel, written.Funnel)
+			assert.Equal(t, r.Transitions, written.Transitions)
+		},
+	)
+
+	t.Run(
+		"should write the tables as CSV", func(t *testing.T) {
+			tests := map[string]string{
+				FileReferrals: "userId,direct,total\n1,1,2\n2,1,1\n",
+				FileFunnel:    "step,users,fromPrevious,fromFirstStep,medianDuration\nSIGNUP,3,1.0000,1.0000,\n",
+				FileRetention: "cohort,users,period,activeUsers,rate\n2021-01,2,0,2,1.0000\n",
+				FileTransitions: "from,to,count,probability\nADD_CONTACT,ADD_CONTACT,0,0.0000\n" +
+					"ADD_CONTACT,REFER_USER,1,1.0000\n",
+			}
+			for name, prefix := range tests {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				require.NoError(t, err)
+				assert.True(t, strings.HasPrefix(string(data), prefix), "%s:\n%s", name, data)
+			}
+		},
+	)
+
+	t.Run(
+		"should summarize the report as Markdown", func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, FileMarkdown))
+			require.NoError(t, err)
+
+			md := string(data)
+			assert.Contains(t, md, "from 3 users and 6 actions")
+			assert.Contains(t, md, "| WELCOME | 50.0% | 50.0% | 0.0% |\n")
+			assert.Contains(t, md, "| REFER_USER | 2 | 100.0% | 66.7% | 48h0m0s |\n")
+			assert.Contains(t, md, "| Cohort | Users | M0 | M1 | M2 |\n")
+			assert.Contains(t, md, "| 2021-02 | 1 | 0.0% | 0.0% |  |\n")
+		},
+	)
+
+	t.Run(
+		"should refuse to overwrite a report", func(t *testing.T) {
+			_, _, err := Write(out, r)
+
+			assert.ErrorIs(t, err, ErrReportExists)
+		},
+	)
+}
+
+func Test_writeCSV(t *testing.T) {
+	var buf strings.Builder
+
+	err := writeCSV(&buf, table{columns: []string{"type", "actions"}, rows: [][]string{{"+CMD", "-3"}}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "type,actions\n'+CMD,-3\n", buf.String(), "should escape formulas")
+}
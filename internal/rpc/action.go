@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"surf_challenge/internal/action"
+	"surf_challenge/internal/action/domain"
+	"surf_challenge/internal/api/action/mapper"
+	"surf_challenge/internal/rpc/surfv1"
+)
+
+type actionServer struct {
+	surfv1.UnimplementedActionServiceServer
+
+	service action.Service
+}
+
+func newActionServer(service action.Service) surfv1.ActionServiceServer {
+	return &actionServer{service: service}
+}
+
+func (s *actionServer) GetNextActionProbability(
+	ctx context.Context,
+	req *surfv1.GetNextActionProbabilityRequest,
+) (*surfv1.GetNextActionProbabilityResponse, error) {
+	if req.GetNext() == "" {
+		return nil, status.Error(codes.InvalidArgument, "next action parameter is required")
+	}
+
+	probability, err := s.service.GetNextActionProbability(ctx, req.GetNext())
+	if err != nil {
+		return nil, toStatus(fmt.Errorf("getting next action probability: %w", err))
+	}
+
+	// The mapper orders the actions by decreasing probability, as in the REST API.
+	probabilityDTO, err := mapper.MapProbabilityToDTO(probability)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &surfv1.GetNextActionProbabilityResponse{
+		Probabilities: make([]*surfv1.ActionProbability, len(probabilityDTO.Keys)),
+	}
+
+	for i, key := range probabilityDTO.Keys {
+		resp.Probabilities[i] = &surfv1.ActionProbability{
+			Action:      key,
+			Probability: probabilityDTO.Data[key],
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *actionServer) GetUsersReferrals(
+	ctx context.Context,
+	_ *surfv1.GetUsersReferralsRequest,
+) (*surfv1.GetUsersReferralsResponse, error) {
+	referrals, err := s.service.GetUsersReferrals(ctx)
+	if err != nil {
+		return nil, toStatus(fmt.Errorf("getting users referrals: %w", err))
+	}
+
+	resp := &surfv1.GetUsersReferralsResponse{
+		Referrals: make([]*surfv1.Referral, 0, len(referrals)),
+	}
+
+	for _, userID := range slices.Sorted(maps.Keys(referrals)) {
+		resp.Referrals = append(
+			resp.Referrals, &surfv1.Referral{
+				UserId:    int64(userID),
+				Referrals: int64(referrals[userID]),
+			},
+		)
+	}
+
+	return resp, nil
+}
+
+// ListActions streams the matching actions in chronological order, one message each,
+// stopping early when the client goes away.
+func (s *actionServer) ListActions(
+	req *surfv1.ListActionsRequest,
+	stream grpc.ServerStreamingServer[surfv1.ListActionsResponse],
+) error {
+	ctx := stream.Context()
+
+	err := authorizeUser(ctx, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	actions, err := s.service.ListActions(ctx, domain.Filter{UserID: req.UserId, Type: req.GetType()})
+	if err != nil {
+		return toStatus(fmt.Errorf("listing actions: %w", err))
+	}
+
+	for _, a := range actions {
+		err = stream.Send(&surfv1.ListActionsResponse{Action: mapAction(a)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mapAction(a *domain.Action) *surfv1.Action {
+	return &surfv1.Action{
+		Id:         int64(a.ID),
+		Type:       a.Type,
+		UserId:     int64(a.UserID),
+		TargetUser: int64(a.TargetUser),
+		CreatedAt:  timestamppb.New(a.CreatedAt),
+	}
+}
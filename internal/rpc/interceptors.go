@@ -0,0 +1,279 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"surf_challenge/internal/api/middleware"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/logger"
+	"surf_challenge/internal/ratelimit"
+	"surf_challenge/internal/rpc/surfv1"
+)
+
+const (
+	// metadataRequestID carries the request ID, as X-Request-ID does over HTTP.
+	metadataRequestID = "x-request-id"
+	// metadataRetryAfter tells throttled callers when to retry, as Retry-After does.
+	metadataRetryAfter = "retry-after"
+)
+
+// serviceScopes is the scope each service requires, as its REST routes do. Services
+// left out, such as reflection, are public like the operational HTTP endpoints.
+var serviceScopes = map[string]auth.Scope{
+	surfv1.UserService_ServiceDesc.ServiceName:   auth.ScopeUsersRead,
+	surfv1.ActionService_ServiceDesc.ServiceName: auth.ScopeAnalyticsRead,
+}
+
+// gatedMethods run through the analytics gate, as their REST routes do. ListActions is
+// left out: a stream would hold a slot for its whole life.
+var gatedMethods = map[string]bool{
+	surfv1.ActionService_GetNextActionProbability_FullMethodName: true,
+	surfv1.ActionService_GetUsersReferrals_FullMethodName:        true,
+}
+
+// interceptor holds what every call needs before reaching a service.
+type interceptor struct {
+	logger        *zap.SugaredLogger
+	authenticator auth.Authenticator
+	// limiter and gate are nil when rate limiting is disabled.
+	limiter  *ratelimit.Limiter
+	gate     *ratelimit.Gate
+	onReject func(reason string)
+}
+
+func (i *interceptor) unary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	ctx, done := i.begin(ctx, info.FullMethod)
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = i.recovered(ctx, p)
+		}
+
+		done(ctx, err)
+	}()
+
+	ctx, err = i.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := i.acquire(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return handler(ctx, req)
+}
+
+func (i *interceptor) stream(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	ctx, done := i.begin(ss.Context(), info.FullMethod)
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = i.recovered(ctx, p)
+		}
+
+		done(ctx, err)
+	}()
+
+	ctx, err = i.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// begin propagates the caller request ID, or generates one, into a request-scoped
+// logger and the response header. done writes the access log entry of the call, with
+// the principal found in ctx.
+func (i *interceptor) begin(ctx context.Context, method string) (context.Context, func(ctx context.Context, err error)) {
+	start := time.Now()
+
+	var incoming string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataRequestID); len(values) > 0 {
+			incoming = values[0]
+		}
+	}
+
+	requestID := middleware.RequestIDOrNew(incoming)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID))
+
+	log := i.logger.With("requestId", requestID)
+	ctx = logger.WithContext(ctx, log)
+
+	return ctx, func(ctx context.Context, err error) {
+		fields := []any{
+			"method", method,
+			"code", status.Code(err).String(),
+			"duration", time.Since(start),
+		}
+
+		if principal, ok := auth.PrincipalFromContext(ctx); ok {
+			fields = append(fields, "principal", principal.Name)
+		}
+
+		log.Infow("rpc", fields...)
+	}
+}
+
+// authenticate resolves the caller of a service requiring a scope and stores it in ctx.
+// With rate limiting, failed authentications are counted per peer IP and calls per
+// principal, as the REST API does.
+func (i *interceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	scope, ok := serviceScopes[serviceName(method)]
+	if !ok {
+		return ctx, nil
+	}
+
+	r := requestFromMetadata(ctx)
+
+	if i.limiter != nil {
+		if exhausted, retryAfter := i.limiter.Exhausted(middleware.FailedAuthKey(r)); exhausted {
+			return ctx, i.reject(ctx, ratelimit.ReasonAuth, retryAfter, "Too many failed authentications")
+		}
+	}
+
+	principal, err := i.authenticator.Authenticate(r)
+	if err != nil {
+		if i.limiter != nil {
+			i.limiter.Allow(middleware.FailedAuthKey(r))
+		}
+
+		return ctx, toStatus(err)
+	}
+
+	ctx = auth.WithPrincipal(ctx, principal)
+
+	if i.limiter != nil {
+		if ok, retryAfter := i.limiter.Allow(middleware.ClientKey(r.WithContext(ctx))); !ok {
+			return ctx, i.reject(ctx, ratelimit.ReasonRate, retryAfter, "Rate limit exceeded")
+		}
+	}
+
+	if !principal.HasScope(scope) {
+		return ctx, status.Errorf(codes.PermissionDenied, "missing scope %s", scope)
+	}
+
+	return ctx, nil
+}
+
+// acquire takes a slot of the analytics gate for the gated methods. The returned
+// release function is never nil.
+func (i *interceptor) acquire(ctx context.Context, method string) (func(), error) {
+	if i.gate == nil || !gatedMethods[method] {
+		return func() {}, nil
+	}
+
+	release, ok := i.gate.Acquire(ctx)
+	if !ok {
+		return nil, i.reject(ctx, ratelimit.ReasonConcurrency, time.Second, "Too many concurrent analytics requests")
+	}
+
+	return release, nil
+}
+
+// reject returns the ResourceExhausted status of a throttled call, with a retry-after
+// metadata entry in seconds as the Retry-After header of the REST API.
+func (i *interceptor) reject(ctx context.Context, reason string, retryAfter time.Duration, message string) error {
+	i.onReject(reason)
+
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRetryAfter, strconv.Itoa(seconds)))
+
+	return status.Error(codes.ResourceExhausted, message)
+}
+
+// recovered logs the panic of a service and turns it into an Internal status, so that
+// it fails the call instead of the server.
+func (i *interceptor) recovered(ctx context.Context, p any) error {
+	logger.FromContext(ctx, i.logger).Errorw("rpc panicked", "panic", p, "stack", string(debug.Stack()))
+
+	return toStatus(fmt.Errorf("panic: %v", p))
+}
+
+// requestFromMetadata exposes the call metadata as the headers of a request, which is
+// what authenticators read credentials from.
+func requestFromMetadata(ctx context.Context) *http.Request {
+	header := http.Header{}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				header.Add(key, value)
+			}
+		}
+	}
+
+	r := &http.Request{Header: header}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	return r.WithContext(ctx)
+}
+
+// serviceName returns "surf.v1.UserService" for "/surf.v1.UserService/GetUser".
+func serviceName(fullMethod string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+
+	return service
+}
+
+// authorizeUser fails unless the caller may read the records of the user, or every user
+// when userID is nil.
+func authorizeUser(ctx context.Context, userID *int64) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return toStatus(auth.ErrUnauthenticated)
+	}
+
+	var id string
+	if userID != nil {
+		id = strconv.FormatInt(*userID, 10)
+	}
+
+	if !principal.CanAccessUser(id) {
+		return toStatus(auth.ErrForbidden)
+	}
+
+	return nil
+}
+
+// contextStream overrides the context of a server stream with the one built by the
+// interceptor.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,45 @@
+// Package rpc serves the user and action services over gRPC, next to the REST API.
+// Both APIs share the services, authenticators and error mapping of the container.
+package rpc
+
+import (
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"surf_challenge/internal/container"
+	"surf_challenge/internal/rpc/surfv1"
+)
+
+// NewServer registers the services enabled by the configuration. Credentials are read
+// from the call metadata, "authorization" or "x-api-key", as from HTTP headers, and calls
+// share the rate limiter and analytics gate of the REST API.
+func NewServer(sugar *zap.SugaredLogger, dependencies *container.AppContainer) *grpc.Server {
+	i := &interceptor{
+		logger:        sugar,
+		authenticator: dependencies.Authenticator,
+	}
+
+	if dependencies.Config.RateLimit.Enabled {
+		i.limiter = dependencies.RateLimiter
+		i.gate = dependencies.AnalyticsGate
+		i.onReject = dependencies.Metrics.ObserveRateLimited
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+	)
+
+	surfv1.RegisterUserServiceServer(server, newUserServer(dependencies.UserService))
+
+	if dependencies.Config.Features.Analytics {
+		surfv1.RegisterActionServiceServer(server, newActionServer(dependencies.ActionService))
+	}
+
+	if dependencies.Config.GRPC.Reflection {
+		reflection.Register(server)
+	}
+
+	return server
+}
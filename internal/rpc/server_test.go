@@ -0,0 +1,423 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"surf_challenge/internal/action"
+	actiondomain "surf_challenge/internal/action/domain"
+	"surf_challenge/internal/auth"
+	"surf_challenge/internal/config"
+	"surf_challenge/internal/container"
+	"surf_challenge/internal/metrics"
+	"surf_challenge/internal/ratelimit"
+	"surf_challenge/internal/rpc/surfv1"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/user/domain"
+)
+
+// newTestClient serves NewServer over an in-memory listener and returns a connection
+// to it.
+func newTestClient(t *testing.T, dependencies *container.AppContainer) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+
+	server := NewServer(zap.NewNop().Sugar(), dependencies)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(
+			func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			},
+		),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			_ = conn.Close()
+		},
+	)
+
+	return conn
+}
+
+func newTestAuthenticator(t *testing.T) auth.Authenticator {
+	t.Helper()
+
+	authenticator, err := auth.NewAPIKeyAuthenticator(
+		[]auth.APIKey{
+			{Name: "reader", SHA256: auth.HashAPIKey("reader-key"), Scopes: []string{"users:read"}},
+			{Name: "ops", SHA256: auth.HashAPIKey("ops-key"), Scopes: []string{"admin"}},
+		},
+	)
+	require.NoError(t, err)
+
+	return authenticator
+}
+
+func withAPIKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, auth.HeaderAPIKey, key)
+}
+
+func Test_UserService(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	userService := user.NewMockService(gomock.NewController(t))
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(7)).
+		Return(&domain.User{ID: 7, Name: "Jane", CreatedAt: createdAt, Version: 1}, nil).
+		AnyTimes()
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(8)).Return(nil, user.ErrNotFound).AnyTimes()
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        config.Default(),
+			UserService:   userService,
+			Authenticator: newTestAuthenticator(t),
+		},
+	)
+	client := surfv1.NewUserServiceClient(conn)
+
+	tests := []struct {
+		name        string
+		key         string
+		id          int64
+		wantCode    codes.Code
+		wantMessage string
+		wantName    string
+	}{
+		{
+			name:     "When the key has the service scope, should return the user",
+			key:      "reader-key",
+			id:       7,
+			wantCode: codes.OK,
+			wantName: "Jane",
+		},
+		{
+			name:        "When the user does not exist, should return NotFound",
+			key:         "reader-key",
+			id:          8,
+			wantCode:    codes.NotFound,
+			wantMessage: "Resource not found",
+		},
+		{
+			name:        "When no key is sent, should return Unauthenticated",
+			id:          7,
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "Missing or invalid credentials",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var header metadata.MD
+
+				resp, err := client.GetUser(
+					withAPIKey(t.Context(), tt.key),
+					&surfv1.GetUserRequest{Id: tt.id},
+					grpc.Header(&header),
+				)
+
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				assert.NotEmpty(t, header.Get(metadataRequestID), "should send the request ID")
+
+				if tt.wantCode != codes.OK {
+					assert.Equal(t, tt.wantMessage, status.Convert(err).Message())
+
+					return
+				}
+
+				assert.Equal(t, tt.wantName, resp.GetUser().GetName())
+				assert.Equal(t, createdAt, resp.GetUser().GetCreatedAt().AsTime())
+			},
+		)
+	}
+}
+
+func Test_UserService_QueryUsers(t *testing.T) {
+	userService := user.NewMockService(gomock.NewController(t))
+	userService.EXPECT().QueryUsers(gomock.Any(), domain.Query{Page: 2, PageSize: 10}).
+		Return([]*domain.User{{ID: 11, Name: "Joe"}}, &domain.Results{TotalItems: 11}, nil)
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        config.Default(),
+			UserService:   userService,
+			Authenticator: auth.NewAnonymousAuthenticator(),
+		},
+	)
+
+	resp, err := surfv1.NewUserServiceClient(conn).QueryUsers(t.Context(), &surfv1.QueryUsersRequest{Page: 2})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetUsers(), 1)
+	assert.Equal(t, int64(11), resp.GetUsers()[0].GetId())
+	assert.Equal(t, int32(2), resp.GetPagination().GetTotalPages())
+	assert.Equal(t, int32(10), resp.GetPagination().GetPageSize())
+}
+
+func Test_ActionService(t *testing.T) {
+	actionService := action.NewMockService(gomock.NewController(t))
+	actionService.EXPECT().GetNextActionProbability(gomock.Any(), "ADD_CONTACT").
+		Return(map[string]string{"EDIT_CONTACT": "0.30", "ADD_CONTACT": "0.70"}, nil).
+		AnyTimes()
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        config.Default(),
+			ActionService: actionService,
+			Authenticator: newTestAuthenticator(t),
+		},
+	)
+	client := surfv1.NewActionServiceClient(conn)
+
+	tests := []struct {
+		name        string
+		key         string
+		next        string
+		wantCode    codes.Code
+		wantMessage string
+		wantActions []string
+	}{
+		{
+			name:        "When the key is an admin key, should return the actions by decreasing probability",
+			key:         "ops-key",
+			next:        "ADD_CONTACT",
+			wantCode:    codes.OK,
+			wantActions: []string{"ADD_CONTACT", "EDIT_CONTACT"},
+		},
+		{
+			name:        "When the key lacks the service scope, should return PermissionDenied",
+			key:         "reader-key",
+			next:        "ADD_CONTACT",
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "missing scope analytics:read",
+		},
+		{
+			name:        "When next is missing, should return InvalidArgument",
+			key:         "ops-key",
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "next action parameter is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				resp, err := client.GetNextActionProbability(
+					withAPIKey(t.Context(), tt.key),
+					&surfv1.GetNextActionProbabilityRequest{Next: tt.next},
+				)
+
+				assert.Equal(t, tt.wantCode, status.Code(err))
+
+				if tt.wantCode != codes.OK {
+					assert.Equal(t, tt.wantMessage, status.Convert(err).Message())
+
+					return
+				}
+
+				var actions []string
+				for _, p := range resp.GetProbabilities() {
+					actions = append(actions, p.GetAction())
+				}
+
+				assert.Equal(t, tt.wantActions, actions)
+			},
+		)
+	}
+}
+
+func Test_ActionService_ListActions(t *testing.T) {
+	userID := int64(3)
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	actionService := action.NewMockService(gomock.NewController(t))
+	actionService.EXPECT().ListActions(gomock.Any(), actiondomain.Filter{UserID: &userID, Type: "REFER_USER"}).
+		Return(
+			[]*actiondomain.Action{
+				{ID: 1, Type: "REFER_USER", UserID: 3, TargetUser: 4, CreatedAt: createdAt},
+				{ID: 2, Type: "REFER_USER", UserID: 3, TargetUser: 5, CreatedAt: createdAt.Add(time.Hour)},
+			}, nil,
+		)
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        config.Default(),
+			ActionService: actionService,
+			Authenticator: auth.NewAnonymousAuthenticator(),
+		},
+	)
+
+	stream, err := surfv1.NewActionServiceClient(conn).ListActions(
+		t.Context(), &surfv1.ListActionsRequest{UserId: &userID, Type: "REFER_USER"},
+	)
+	require.NoError(t, err)
+
+	var targets []int64
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		targets = append(targets, resp.GetAction().GetTargetUser())
+	}
+
+	assert.Equal(t, []int64{4, 5}, targets)
+}
+
+func Test_NewServer_analyticsDisabled(t *testing.T) {
+	cfg := config.Default()
+	cfg.Features.Analytics = false
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        cfg,
+			Authenticator: auth.NewAnonymousAuthenticator(),
+		},
+	)
+
+	_, err := surfv1.NewActionServiceClient(conn).GetUsersReferrals(t.Context(), &surfv1.GetUsersReferralsRequest{})
+
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func Test_NewServer_rateLimit(t *testing.T) {
+	userService := user.NewMockService(gomock.NewController(t))
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(7)).Return(&domain.User{ID: 7, Name: "Jane"}, nil).AnyTimes()
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        config.Default(),
+			UserService:   userService,
+			Authenticator: newTestAuthenticator(t),
+			Metrics:       metrics.New(),
+			RateLimiter:   ratelimit.NewLimiter(0.001, 1, 10),
+		},
+	)
+	client := surfv1.NewUserServiceClient(conn)
+
+	tests := []struct {
+		name           string
+		key            string
+		wantCode       codes.Code
+		wantRetryAfter []string
+	}{
+		{
+			name:     "When the principal has tokens left, should serve the call",
+			key:      "reader-key",
+			wantCode: codes.OK,
+		},
+		{
+			name:           "When the principal exhausted its bucket, should return ResourceExhausted with retry-after",
+			key:            "reader-key",
+			wantCode:       codes.ResourceExhausted,
+			wantRetryAfter: []string{"1000"},
+		},
+		{
+			name:     "When the key is wrong, should return Unauthenticated",
+			key:      "guess",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:           "When the peer failed to authenticate too often, should return ResourceExhausted",
+			key:            "ops-key",
+			wantCode:       codes.ResourceExhausted,
+			wantRetryAfter: []string{"1000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var header metadata.MD
+
+				_, err := client.GetUser(withAPIKey(t.Context(), tt.key), &surfv1.GetUserRequest{Id: 7}, grpc.Header(&header))
+
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				assert.Equal(t, tt.wantRetryAfter, header.Get(metadataRetryAfter))
+			},
+		)
+	}
+}
+
+func Test_NewServer_analyticsGate(t *testing.T) {
+	gate := ratelimit.NewGate(1, time.Millisecond)
+	release, _ := gate.Acquire(t.Context())
+
+	defer release()
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        config.Default(),
+			ActionService: action.NewMockService(gomock.NewController(t)),
+			Authenticator: auth.NewAnonymousAuthenticator(),
+			Metrics:       metrics.New(),
+			RateLimiter:   ratelimit.NewLimiter(1000, 1000, 10),
+			AnalyticsGate: gate,
+		},
+	)
+
+	_, err := surfv1.NewActionServiceClient(conn).GetUsersReferrals(t.Context(), &surfv1.GetUsersReferralsRequest{})
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "Too many concurrent analytics requests", status.Convert(err).Message())
+}
+
+func Test_NewServer_recovers(t *testing.T) {
+	userService := user.NewMockService(gomock.NewController(t))
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(7)).DoAndReturn(
+		func(context.Context, int64) (*domain.User, error) {
+			panic("boom")
+		},
+	)
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(8)).Return(&domain.User{ID: 8, Name: "Joe"}, nil)
+
+	conn := newTestClient(
+		t, &container.AppContainer{
+			Config:        config.Default(),
+			UserService:   userService,
+			Authenticator: auth.NewAnonymousAuthenticator(),
+		},
+	)
+	client := surfv1.NewUserServiceClient(conn)
+
+	_, err := client.GetUser(t.Context(), &surfv1.GetUserRequest{Id: 7})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "Internal server error", status.Convert(err).Message())
+
+	resp, err := client.GetUser(t.Context(), &surfv1.GetUserRequest{Id: 8})
+	require.NoError(t, err, "the server should keep serving after a panic")
+	assert.Equal(t, "Joe", resp.GetUser().GetName())
+}
@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"surf_challenge/internal/api/apierror"
+)
+
+// httpCodes translates the statuses of the REST error mapping into gRPC codes.
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusUnauthorized:       codes.Unauthenticated,
+	http.StatusForbidden:          codes.PermissionDenied,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusPreconditionFailed: codes.FailedPrecondition,
+	http.StatusTooManyRequests:    codes.ResourceExhausted,
+	http.StatusServiceUnavailable: codes.Unavailable,
+}
+
+// toStatus maps a service error through apierror.MapErrors, so that both APIs expose
+// a failure with the same meaning and message. Status errors are returned untouched.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	apiErr := apierror.MapErrors(err)
+
+	code, ok := httpCodes[apiErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+
+	return status.Error(code, apiErr.Message)
+}
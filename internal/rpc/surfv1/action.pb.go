@@ -0,0 +1,581 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: surf/v1/action.proto
+
+package surfv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Action struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type   string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	UserId int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Set on REFER_USER actions only.
+	TargetUser    int64                  `protobuf:"varint,4,opt,name=target_user,json=targetUser,proto3" json:"target_user,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Action) Reset() {
+	*x = Action{}
+	mi := &file_surf_v1_action_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Action) ProtoMessage() {}
+
+func (x *Action) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Action) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Action) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Action) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Action) GetTargetUser() int64 {
+	if x != nil {
+		return x.TargetUser
+	}
+	return 0
+}
+
+func (x *Action) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetNextActionProbabilityRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Action type the probabilities follow, case-insensitive.
+	Next          string `protobuf:"bytes,1,opt,name=next,proto3" json:"next,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNextActionProbabilityRequest) Reset() {
+	*x = GetNextActionProbabilityRequest{}
+	mi := &file_surf_v1_action_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNextActionProbabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNextActionProbabilityRequest) ProtoMessage() {}
+
+func (x *GetNextActionProbabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNextActionProbabilityRequest.ProtoReflect.Descriptor instead.
+func (*GetNextActionProbabilityRequest) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetNextActionProbabilityRequest) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+type ActionProbability struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Action string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	// Rounded to two decimals.
+	Probability   float64 `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActionProbability) Reset() {
+	*x = ActionProbability{}
+	mi := &file_surf_v1_action_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActionProbability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionProbability) ProtoMessage() {}
+
+func (x *ActionProbability) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionProbability.ProtoReflect.Descriptor instead.
+func (*ActionProbability) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ActionProbability) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ActionProbability) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+type GetNextActionProbabilityResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// From the most to the least likely, as in the REST representations.
+	Probabilities []*ActionProbability `protobuf:"bytes,1,rep,name=probabilities,proto3" json:"probabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNextActionProbabilityResponse) Reset() {
+	*x = GetNextActionProbabilityResponse{}
+	mi := &file_surf_v1_action_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNextActionProbabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNextActionProbabilityResponse) ProtoMessage() {}
+
+func (x *GetNextActionProbabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNextActionProbabilityResponse.ProtoReflect.Descriptor instead.
+func (*GetNextActionProbabilityResponse) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetNextActionProbabilityResponse) GetProbabilities() []*ActionProbability {
+	if x != nil {
+		return x.Probabilities
+	}
+	return nil
+}
+
+type GetUsersReferralsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersReferralsRequest) Reset() {
+	*x = GetUsersReferralsRequest{}
+	mi := &file_surf_v1_action_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersReferralsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersReferralsRequest) ProtoMessage() {}
+
+func (x *GetUsersReferralsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersReferralsRequest.ProtoReflect.Descriptor instead.
+func (*GetUsersReferralsRequest) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{4}
+}
+
+type Referral struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Users referred directly or through the users they referred.
+	Referrals     int64 `protobuf:"varint,2,opt,name=referrals,proto3" json:"referrals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Referral) Reset() {
+	*x = Referral{}
+	mi := &file_surf_v1_action_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Referral) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Referral) ProtoMessage() {}
+
+func (x *Referral) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Referral.ProtoReflect.Descriptor instead.
+func (*Referral) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Referral) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Referral) GetReferrals() int64 {
+	if x != nil {
+		return x.Referrals
+	}
+	return 0
+}
+
+type GetUsersReferralsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In ascending user ID order; users without referrals are left out.
+	Referrals     []*Referral `protobuf:"bytes,1,rep,name=referrals,proto3" json:"referrals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersReferralsResponse) Reset() {
+	*x = GetUsersReferralsResponse{}
+	mi := &file_surf_v1_action_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersReferralsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersReferralsResponse) ProtoMessage() {}
+
+func (x *GetUsersReferralsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersReferralsResponse.ProtoReflect.Descriptor instead.
+func (*GetUsersReferralsResponse) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetUsersReferralsResponse) GetReferrals() []*Referral {
+	if x != nil {
+		return x.Referrals
+	}
+	return nil
+}
+
+type ListActionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only stream the actions of this user.
+	UserId *int64 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
+	// Only stream the actions of this type, case-insensitive.
+	Type          string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActionsRequest) Reset() {
+	*x = ListActionsRequest{}
+	mi := &file_surf_v1_action_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActionsRequest) ProtoMessage() {}
+
+func (x *ListActionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActionsRequest.ProtoReflect.Descriptor instead.
+func (*ListActionsRequest) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListActionsRequest) GetUserId() int64 {
+	if x != nil && x.UserId != nil {
+		return *x.UserId
+	}
+	return 0
+}
+
+func (x *ListActionsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type ListActionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        *Action                `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActionsResponse) Reset() {
+	*x = ListActionsResponse{}
+	mi := &file_surf_v1_action_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActionsResponse) ProtoMessage() {}
+
+func (x *ListActionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_surf_v1_action_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActionsResponse.ProtoReflect.Descriptor instead.
+func (*ListActionsResponse) Descriptor() ([]byte, []int) {
+	return file_surf_v1_action_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListActionsResponse) GetAction() *Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+var File_surf_v1_action_proto protoreflect.FileDescriptor
+
+const file_surf_v1_action_proto_rawDesc = "" +
+	"\n" +
+	"\x14surf/v1/action.proto\x12\asurf.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa1\x01\n" +
+	"\x06Action\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vtarget_user\x18\x04 \x01(\x03R\n" +
+	"targetUser\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"5\n" +
+	"\x1fGetNextActionProbabilityRequest\x12\x12\n" +
+	"\x04next\x18\x01 \x01(\tR\x04next\"M\n" +
+	"\x11ActionProbability\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12 \n" +
+	"\vprobability\x18\x02 \x01(\x01R\vprobability\"d\n" +
+	" GetNextActionProbabilityResponse\x12@\n" +
+	"\rprobabilities\x18\x01 \x03(\v2\x1a.surf.v1.ActionProbabilityR\rprobabilities\"\x1a\n" +
+	"\x18GetUsersReferralsRequest\"A\n" +
+	"\bReferral\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1c\n" +
+	"\treferrals\x18\x02 \x01(\x03R\treferrals\"L\n" +
+	"\x19GetUsersReferralsResponse\x12/\n" +
+	"\treferrals\x18\x01 \x03(\v2\x11.surf.v1.ReferralR\treferrals\"R\n" +
+	"\x12ListActionsRequest\x12\x1c\n" +
+	"\auser_id\x18\x01 \x01(\x03H\x00R\x06userId\x88\x01\x01\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04typeB\n" +
+	"\n" +
+	"\b_user_id\">\n" +
+	"\x13ListActionsResponse\x12'\n" +
+	"\x06action\x18\x01 \x01(\v2\x0f.surf.v1.ActionR\x06action2\xa8\x02\n" +
+	"\rActionService\x12o\n" +
+	"\x18GetNextActionProbability\x12(.surf.v1.GetNextActionProbabilityRequest\x1a).surf.v1.GetNextActionProbabilityResponse\x12Z\n" +
+	"\x11GetUsersReferrals\x12!.surf.v1.GetUsersReferralsRequest\x1a\".surf.v1.GetUsersReferralsResponse\x12J\n" +
+	"\vListActions\x12\x1b.surf.v1.ListActionsRequest\x1a\x1c.surf.v1.ListActionsResponse0\x01B+Z)surf_challenge/internal/rpc/surfv1;surfv1b\x06proto3"
+
+var (
+	file_surf_v1_action_proto_rawDescOnce sync.Once
+	file_surf_v1_action_proto_rawDescData []byte
+)
+
+func file_surf_v1_action_proto_rawDescGZIP() []byte {
+	file_surf_v1_action_proto_rawDescOnce.Do(func() {
+		file_surf_v1_action_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_surf_v1_action_proto_rawDesc), len(file_surf_v1_action_proto_rawDesc)))
+	})
+	return file_surf_v1_action_proto_rawDescData
+}
+
+var file_surf_v1_action_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_surf_v1_action_proto_goTypes = []any{
+	(*Action)(nil),                           // 0: surf.v1.Action
+	(*GetNextActionProbabilityRequest)(nil),  // 1: surf.v1.GetNextActionProbabilityRequest
+	(*ActionProbability)(nil),                // 2: surf.v1.ActionProbability
+	(*GetNextActionProbabilityResponse)(nil), // 3: surf.v1.GetNextActionProbabilityResponse
+	(*GetUsersReferralsRequest)(nil),         // 4: surf.v1.GetUsersReferralsRequest
+	(*Referral)(nil),                         // 5: surf.v1.Referral
+	(*GetUsersReferralsResponse)(nil),        // 6: surf.v1.GetUsersReferralsResponse
+	(*ListActionsRequest)(nil),               // 7: surf.v1.ListActionsRequest
+	(*ListActionsResponse)(nil),              // 8: surf.v1.ListActionsResponse
+	(*timestamppb.Timestamp)(nil),            // 9: google.protobuf.Timestamp
+}
+var file_surf_v1_action_proto_depIdxs = []int32{
+	9, // 0: surf.v1.Action.created_at:type_name -> google.protobuf.Timestamp
+	2, // 1: surf.v1.GetNextActionProbabilityResponse.probabilities:type_name -> surf.v1.ActionProbability
+	5, // 2: surf.v1.GetUsersReferralsResponse.referrals:type_name -> surf.v1.Referral
+	0, // 3: surf.v1.ListActionsResponse.action:type_name -> surf.v1.Action
+	1, // 4: surf.v1.ActionService.GetNextActionProbability:input_type -> surf.v1.GetNextActionProbabilityRequest
+	4, // 5: surf.v1.ActionService.GetUsersReferrals:input_type -> surf.v1.GetUsersReferralsRequest
+	7, // 6: surf.v1.ActionService.ListActions:input_type -> surf.v1.ListActionsRequest
+	3, // 7: surf.v1.ActionService.GetNextActionProbability:output_type -> surf.v1.GetNextActionProbabilityResponse
+	6, // 8: surf.v1.ActionService.GetUsersReferrals:output_type -> surf.v1.GetUsersReferralsResponse
+	8, // 9: surf.v1.ActionService.ListActions:output_type -> surf.v1.ListActionsResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_surf_v1_action_proto_init() }
+func file_surf_v1_action_proto_init() {
+	if File_surf_v1_action_proto != nil {
+		return
+	}
+	file_surf_v1_action_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_surf_v1_action_proto_rawDesc), len(file_surf_v1_action_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_surf_v1_action_proto_goTypes,
+		DependencyIndexes: file_surf_v1_action_proto_depIdxs,
+		MessageInfos:      file_surf_v1_action_proto_msgTypes,
+	}.Build()
+	File_surf_v1_action_proto = out.File
+	file_surf_v1_action_proto_goTypes = nil
+	file_surf_v1_action_proto_depIdxs = nil
+}
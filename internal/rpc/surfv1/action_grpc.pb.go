@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: surf/v1/action.proto
+
+package surfv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ActionService_GetNextActionProbability_FullMethodName = "/surf.v1.ActionService/GetNextActionProbability"
+	ActionService_GetUsersReferrals_FullMethodName        = "/surf.v1.ActionService/GetUsersReferrals"
+	ActionService_ListActions_FullMethodName              = "/surf.v1.ActionService/ListActions"
+)
+
+// ActionServiceClient is the client API for ActionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ActionService exposes the actions dataset and its analytics, as the /api/v1/actions
+// REST routes do.
+type ActionServiceClient interface {
+	GetNextActionProbability(ctx context.Context, in *GetNextActionProbabilityRequest, opts ...grpc.CallOption) (*GetNextActionProbabilityResponse, error)
+	GetUsersReferrals(ctx context.Context, in *GetUsersReferralsRequest, opts ...grpc.CallOption) (*GetUsersReferralsResponse, error)
+	// ListActions streams the matching actions in chronological order.
+	ListActions(ctx context.Context, in *ListActionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListActionsResponse], error)
+}
+
+type actionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewActionServiceClient(cc grpc.ClientConnInterface) ActionServiceClient {
+	return &actionServiceClient{cc}
+}
+
+func (c *actionServiceClient) GetNextActionProbability(ctx context.Context, in *GetNextActionProbabilityRequest, opts ...grpc.CallOption) (*GetNextActionProbabilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNextActionProbabilityResponse)
+	err := c.cc.Invoke(ctx, ActionService_GetNextActionProbability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *actionServiceClient) GetUsersReferrals(ctx context.Context, in *GetUsersReferralsRequest, opts ...grpc.CallOption) (*GetUsersReferralsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsersReferralsResponse)
+	err := c.cc.Invoke(ctx, ActionService_GetUsersReferrals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *actionServiceClient) ListActions(ctx context.Context, in *ListActionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListActionsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ActionService_ServiceDesc.Streams[0], ActionService_ListActions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListActionsRequest, ListActionsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ActionService_ListActionsClient = grpc.ServerStreamingClient[ListActionsResponse]
+
+// ActionServiceServer is the server API for ActionService service.
+// All implementations must embed UnimplementedActionServiceServer
+// for forward compatibility.
+//
+// ActionService exposes the actions dataset and its analytics, as the /api/v1/actions
+// REST routes do.
+type ActionServiceServer interface {
+	GetNextActionProbability(context.Context, *GetNextActionProbabilityRequest) (*GetNextActionProbabilityResponse, error)
+	GetUsersReferrals(context.Context, *GetUsersReferralsRequest) (*GetUsersReferralsResponse, error)
+	// ListActions streams the matching actions in chronological order.
+	ListActions(*ListActionsRequest, grpc.ServerStreamingServer[ListActionsResponse]) error
+	mustEmbedUnimplementedActionServiceServer()
+}
+
+// UnimplementedActionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedActionServiceServer struct{}
+
+func (UnimplementedActionServiceServer) GetNextActionProbability(context.Context, *GetNextActionProbabilityRequest) (*GetNextActionProbabilityResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNextActionProbability not implemented")
+}
+func (UnimplementedActionServiceServer) GetUsersReferrals(context.Context, *GetUsersReferralsRequest) (*GetUsersReferralsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUsersReferrals not implemented")
+}
+func (UnimplementedActionServiceServer) ListActions(*ListActionsRequest, grpc.ServerStreamingServer[ListActionsResponse]) error {
+	return status.Error(codes.Unimplemented, "method ListActions not implemented")
+}
+func (UnimplementedActionServiceServer) mustEmbedUnimplementedActionServiceServer() {}
+func (UnimplementedActionServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeActionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ActionServiceServer will
+// result in compilation errors.
+type UnsafeActionServiceServer interface {
+	mustEmbedUnimplementedActionServiceServer()
+}
+
+func RegisterActionServiceServer(s grpc.ServiceRegistrar, srv ActionServiceServer) {
+	// If the following call panics, it indicates UnimplementedActionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ActionService_ServiceDesc, srv)
+}
+
+func _ActionService_GetNextActionProbability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNextActionProbabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActionServiceServer).GetNextActionProbability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ActionService_GetNextActionProbability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActionServiceServer).GetNextActionProbability(ctx, req.(*GetNextActionProbabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActionService_GetUsersReferrals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsersReferralsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActionServiceServer).GetUsersReferrals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ActionService_GetUsersReferrals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActionServiceServer).GetUsersReferrals(ctx, req.(*GetUsersReferralsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActionService_ListActions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListActionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ActionServiceServer).ListActions(m, &grpc.GenericServerStream[ListActionsRequest, ListActionsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ActionService_ListActionsServer = grpc.ServerStreamingServer[ListActionsResponse]
+
+// ActionService_ServiceDesc is the grpc.ServiceDesc for ActionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ActionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "surf.v1.ActionService",
+	HandlerType: (*ActionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNextActionProbability",
+			Handler:    _ActionService_GetNextActionProbability_Handler,
+		},
+		{
+			MethodName: "GetUsersReferrals",
+			Handler:    _ActionService_GetUsersReferrals_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListActions",
+			Handler:       _ActionService_ListActions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "surf/v1/action.proto",
+}
@@ -0,0 +1,4 @@
+// Package surfv1 holds the messages and service stubs generated from proto/surf/v1.
+package surfv1
+
+//go:generate go run github.com/bufbuild/buf/cmd/buf@v1.73.0 generate ../../../proto --template ../../../proto/buf.gen.yaml
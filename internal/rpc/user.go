@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"surf_challenge/internal/api/user/mapper"
+	"surf_challenge/internal/rpc/surfv1"
+	"surf_challenge/internal/user"
+	"surf_challenge/internal/user/domain"
+)
+
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+type userServer struct {
+	surfv1.UnimplementedUserServiceServer
+
+	service user.Service
+}
+
+func newUserServer(service user.Service) surfv1.UserServiceServer {
+	return &userServer{service: service}
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *surfv1.GetUserRequest) (*surfv1.GetUserResponse, error) {
+	id := req.GetId()
+
+	err := authorizeUser(ctx, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.service.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, toStatus(fmt.Errorf("getting user: %w", err))
+	}
+
+	return &surfv1.GetUserResponse{User: mapUser(u)}, nil
+}
+
+func (s *userServer) QueryUsers(ctx context.Context, req *surfv1.QueryUsersRequest) (*surfv1.QueryUsersResponse, error) {
+	err := authorizeUser(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	page, pageSize := int(req.GetPage()), int(req.GetPageSize())
+
+	switch {
+	case page < 0:
+		return nil, status.Error(codes.InvalidArgument, "invalid page parameter")
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "invalid page_size parameter")
+	}
+
+	if page == 0 {
+		page = defaultPage
+	}
+
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	users, results, err := s.service.QueryUsers(
+		ctx,
+		domain.Query{
+			ID:       req.Id,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	)
+	if err != nil {
+		return nil, toStatus(fmt.Errorf("querying users: %w", err))
+	}
+
+	resp := &surfv1.QueryUsersResponse{
+		Users:      make([]*surfv1.User, len(users)),
+		Pagination: mapPagination(results, page, pageSize),
+	}
+
+	for i, u := range users {
+		resp.Users[i] = mapUser(u)
+	}
+
+	return resp, nil
+}
+
+func (s *userServer) GetUserActionCount(
+	ctx context.Context,
+	req *surfv1.GetUserActionCountRequest,
+) (*surfv1.GetUserActionCountResponse, error) {
+	id := req.GetUserId()
+
+	err := authorizeUser(ctx, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.service.GetUserActionCount(ctx, id)
+	if err != nil {
+		return nil, toStatus(fmt.Errorf("getting user action count: %w", err))
+	}
+
+	return &surfv1.GetUserActionCountResponse{Count: int64(count)}, nil
+}
+
+func mapUser(u *domain.User) *surfv1.User {
+	msg := &surfv1.User{
+		Id:        u.ID,
+		Name:      u.Name,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		Version:   u.Version,
+	}
+
+	if !u.UpdatedAt.IsZero() {
+		msg.UpdatedAt = timestamppb.New(u.UpdatedAt)
+	}
+
+	return msg
+}
+
+// mapPagination computes the page count like the REST API does.
+func mapPagination(results *domain.Results, page, pageSize int) *surfv1.Pagination {
+	pagination := mapper.MapPaginationToDTO(results, page, pageSize)
+
+	return &surfv1.Pagination{
+		TotalItems: int32(pagination.TotalItems),
+		TotalPages: int32(pagination.TotalPages),
+		Page:       int32(pagination.Page),
+		PageSize:   int32(pagination.PageSize),
+	}
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_Provider_Middleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantStatus  codes.Code
+		wantAttrs   []attribute.KeyValue
+	}{
+		{
+			name:       "When route matches, should name the span after the route pattern",
+			path:       "/users/7",
+			wantName:   "GET /users/{userId}",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("http.route", "/users/{userId}"),
+				attribute.Int("http.response.status_code", http.StatusOK),
+				attribute.String("url.path", "/users/7"),
+			},
+		},
+		{
+			name:       "When handler fails, should mark the span as error",
+			path:       "/fail",
+			wantName:   "GET /fail",
+			wantStatus: codes.Error,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int("http.response.status_code", http.StatusInternalServerError),
+			},
+		},
+		{
+			name:        "When traceparent is provided, should continue the caller's trace",
+			path:        "/users/7",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantName:    "GET /users/{userId}",
+			wantStatus:  codes.Unset,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				recorder := tracetest.NewSpanRecorder()
+				p := NewWithProcessor(recorder, sdktrace.AlwaysSample())
+
+				router := chi.NewRouter()
+				router.Use(p.Middleware)
+				router.Get("/users/{userId}", func(http.ResponseWriter, *http.Request) {})
+				router.Get(
+					"/fail", func(w http.ResponseWriter, _ *http.Request) {
+						w.WriteHeader(http.StatusInternalServerError)
+					},
+				)
+
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.path, nil)
+				if tt.traceparent != "" {
+					req.Header.Set("traceparent", tt.traceparent)
+				}
+
+				router.ServeHTTP(httptest.NewRecorder(), req)
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+
+				span := spans[0]
+				assert.Equal(t, tt.wantName, span.Name())
+				assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+				assert.Equal(t, tt.wantStatus, span.Status().Code)
+
+				for _, attr := range tt.wantAttrs {
+					assert.Contains(t, span.Attributes(), attr)
+				}
+
+				if tt.traceparent != "" {
+					assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+					assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+				}
+			},
+		)
+	}
+}
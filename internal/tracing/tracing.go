@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"surf_challenge/internal/buildinfo"
+	"surf_challenge/internal/config"
+)
+
+const (
+	ServiceName = "surf-challenge"
+	tracerName  = "surf_challenge"
+)
+
+// Provider hands out tracers and flushes pending spans on shutdown.
+type Provider struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	shutdown   func(ctx context.Context) error
+}
+
+// New builds a provider from cfg. When tracing is disabled every span is a no-op.
+func New(cfg config.Tracing) (*Provider, error) {
+	if !cfg.Enabled {
+		return NewNoop(), nil
+	}
+
+	var (
+		out     io.Writer = os.Stdout
+		closeFn           = func() error { return nil }
+	)
+
+	if cfg.Exporter == config.TracingExporterFile {
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening trace file: %w", err)
+		}
+
+		out, closeFn = file, file.Close
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+	if err != nil {
+		_ = closeFn()
+
+		return nil, fmt.Errorf("creating trace exporter: %w", err)
+	}
+
+	p := NewWithProcessor(
+		sdktrace.NewBatchSpanProcessor(exporter),
+		sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio)),
+	)
+
+	flush := p.shutdown
+	p.shutdown = func(ctx context.Context) error {
+		return errors.Join(flush(ctx), closeFn())
+	}
+
+	return p, nil
+}
+
+// NewWithProcessor builds a provider exporting through processor, e.g. a
+// tracetest.SpanRecorder in tests.
+func NewWithProcessor(processor sdktrace.SpanProcessor, sampler sdktrace.Sampler) *Provider {
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(
+			resource.NewSchemaless(
+				semconv.ServiceName(ServiceName),
+				semconv.ServiceVersion(buildinfo.Version),
+			),
+		),
+	)
+
+	return &Provider{
+		provider:   tp,
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		shutdown:   tp.Shutdown,
+	}
+}
+
+func NewNoop() *Provider {
+	return &Provider{
+		provider:   noop.NewTracerProvider(),
+		propagator: propagation.NewCompositeTextMapPropagator(),
+		shutdown:   func(context.Context) error { return nil },
+	}
+}
+
+func (p *Provider) Tracer() trace.Tracer {
+	return p.provider.Tracer(tracerName)
+}
+
+// Shutdown flushes the spans still buffered and releases the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
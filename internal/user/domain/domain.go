@@ -6,10 +6,17 @@ type Results struct {
 	TotalItems int
 }
 
+// InitialVersion is the version of a record that was never updated.
+const InitialVersion = 1
+
 type User struct {
 	ID        int64
 	Name      string
 	CreatedAt time.Time
+	// Version increases with every update and identifies the record state for caching
+	// and optimistic concurrency.
+	Version   int64
+	UpdatedAt time.Time
 }
 
 type Query struct {
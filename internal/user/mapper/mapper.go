@@ -27,9 +27,25 @@ func MapUserEntToDomain(u *entity.User) (*domain.User, error) {
 		return nil, err
 	}
 
+	version := u.Version
+	if version == 0 {
+		version = domain.InitialVersion
+	}
+
+	updatedAt := createdAt
+
+	if u.UpdatedAt != "" {
+		updatedAt, err = time.Parse(time.RFC3339, u.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &domain.User{
 		ID:        u.ID,
 		Name:      u.Name,
 		CreatedAt: createdAt,
+		Version:   version,
+		UpdatedAt: updatedAt,
 	}, nil
 }
@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"surf_challenge/internal/action"
+	"surf_challenge/internal/logger"
 	"surf_challenge/internal/user/domain"
 	"surf_challenge/internal/user/mapper"
 	"surf_challenge/internal/user/storage"
@@ -20,6 +21,9 @@ type Service interface {
 	QueryUsers(ctx context.Context, query domain.Query) ([]*domain.User, *domain.Results, error)
 	GetUserActionCount(ctx context.Context, userID int64) (int, error)
 	GetUserByID(ctx context.Context, id int64) (*domain.User, error)
+	// GetUsersByIDs returns the users among ids, unknown ones being left out, in a
+	// single repository lookup.
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]*domain.User, error)
 }
 
 type userService struct {
@@ -41,7 +45,7 @@ func NewService(
 }
 
 func (s *userService) QueryUsers(ctx context.Context, query domain.Query) ([]*domain.User, *domain.Results, error) {
-	s.logger.Infow("QueryUsers called", "query", query)
+	logger.FromContext(ctx, s.logger).Infow("QueryUsers called", "query", query)
 
 	users, totalResults, err := s.repo.QueryUsers(ctx, query.ID, query.Page, query.PageSize)
 	if err != nil {
@@ -57,7 +61,7 @@ func (s *userService) QueryUsers(ctx context.Context, query domain.Query) ([]*do
 }
 
 func (s *userService) GetUserActionCount(ctx context.Context, userID int64) (int, error) {
-	s.logger.Infow("GetUserActionCount called", "userID", userID)
+	logger.FromContext(ctx, s.logger).Infow("GetUserActionCount called", "userID", userID)
 
 	_, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
@@ -77,7 +81,7 @@ func (s *userService) GetUserActionCount(ctx context.Context, userID int64) (int
 }
 
 func (s *userService) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
-	s.logger.Infow("GetUserByID called", "id", id)
+	logger.FromContext(ctx, s.logger).Infow("GetUserByID called", "id", id)
 
 	userEnt, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
@@ -95,3 +99,19 @@ func (s *userService) GetUserByID(ctx context.Context, id int64) (*domain.User,
 
 	return userDomain, nil
 }
+
+func (s *userService) GetUsersByIDs(ctx context.Context, ids []int64) ([]*domain.User, error) {
+	logger.FromContext(ctx, s.logger).Infow("GetUsersByIDs called", "ids", ids)
+
+	userEnts, err := s.repo.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by IDs: %w", err)
+	}
+
+	usersDomain, err := mapper.MapUsersEntToDomain(userEnts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map user entities to domain: %w", err)
+	}
+
+	return usersDomain, nil
+}
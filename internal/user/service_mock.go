@@ -71,6 +71,21 @@ func (mr *MockServiceMockRecorder) GetUserByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockService)(nil).GetUserByID), ctx, id)
 }
 
+// GetUsersByIDs mocks base method.
+func (m *MockService) GetUsersByIDs(ctx context.Context, ids []int64) ([]*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByIDs indicates an expected call of GetUsersByIDs.
+func (mr *MockServiceMockRecorder) GetUsersByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByIDs", reflect.TypeOf((*MockService)(nil).GetUsersByIDs), ctx, ids)
+}
+
 // QueryUsers mocks base method.
 func (m *MockService) QueryUsers(ctx context.Context, query domain.Query) ([]*domain.User, *domain.Results, error) {
 	m.ctrl.T.Helper()
@@ -48,6 +48,8 @@ func Test_userService_QueryUsers(t *testing.T) {
 							ID:        2,
 							Name:      "Jane Smith",
 							CreatedAt: "2023-10-02T11:00:00Z",
+							Version:   3,
+							UpdatedAt: "2023-11-05T08:30:00Z",
 						},
 					}, 2, nil,
 				)
@@ -57,11 +59,15 @@ func Test_userService_QueryUsers(t *testing.T) {
 					ID:        1,
 					Name:      "John Doe",
 					CreatedAt: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+					Version:   domain.InitialVersion,
+					UpdatedAt: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
 				},
 				{
 					ID:        2,
 					Name:      "Jane Smith",
 					CreatedAt: time.Date(2023, 10, 2, 11, 0, 0, 0, time.UTC),
+					Version:   3,
+					UpdatedAt: time.Date(2023, 11, 5, 8, 30, 0, 0, time.UTC),
 				},
 			},
 			wantResults: &domain.Results{
@@ -158,6 +164,27 @@ func Test_userService_GetUserActionCount(t *testing.T) {
 			want:    1,
 			wantErr: assert.NoError,
 		},
+		{
+			name:   "should return zero when user has no actions",
+			userID: 1,
+			mock: func(m *mocks) {
+				m.repo.EXPECT().
+					GetUserByID(gomock.Any(), int64(1)).
+					Return(
+						&entity.User{
+							ID:        1,
+							Name:      "John Doe",
+							CreatedAt: "2023-10-01T10:00:00Z",
+						}, nil,
+					)
+
+				m.actionService.EXPECT().
+					GetActionByUserID(gomock.Any(), int64(1)).
+					Return([]*actiondomain.Action{}, nil)
+			},
+			want:    0,
+			wantErr: assert.NoError,
+		},
 		{
 			name:   "should return not found error when user does not exist",
 			userID: 99,
@@ -198,10 +225,12 @@ func Test_userService_GetUserActionCount(t *testing.T) {
 
 				m.actionService.EXPECT().
 					GetActionByUserID(gomock.Any(), int64(1)).
-					Return(nil, assert.AnError)
+					Return(nil, action.ErrDataUnavailable)
+			},
+			want: 0,
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, action.ErrDataUnavailable)
 			},
-			want:    0,
-			wantErr: assert.Error,
 		},
 	}
 	for _, tt := range tests {
@@ -261,6 +290,8 @@ func Test_userService_GetUserByID(t *testing.T) {
 				ID:        1,
 				Name:      "John Doe",
 				CreatedAt: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+				Version:   domain.InitialVersion,
+				UpdatedAt: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
 			},
 			wantErr: assert.NoError,
 		},
@@ -312,3 +343,58 @@ func Test_userService_GetUserByID(t *testing.T) {
 		)
 	}
 }
+
+func Test_userService_GetUsersByIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []int64
+		stored  []*entity.User
+		repoErr error
+		want    []*domain.User
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "should return the users found",
+			ids:  []int64{1, 99},
+			stored: []*entity.User{
+				{ID: 1, Name: "John Doe", CreatedAt: "2023-10-01T10:00:00Z"},
+			},
+			want: []*domain.User{
+				{
+					ID:        1,
+					Name:      "John Doe",
+					CreatedAt: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+					Version:   domain.InitialVersion,
+					UpdatedAt: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "should return error when repository fails",
+			ids:     []int64{1},
+			repoErr: assert.AnError,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repo := storage.NewMockRepository(ctrl)
+				repo.EXPECT().GetUsersByIDs(gomock.Any(), tt.ids).Return(tt.stored, tt.repoErr)
+
+				s := &userService{
+					logger: zap.NewNop().Sugar(),
+					repo:   repo,
+				}
+				got, err := s.GetUsersByIDs(t.Context(), tt.ids)
+
+				assert.Equal(t, tt.want, got)
+				tt.wantErr(t, err)
+			},
+		)
+	}
+}
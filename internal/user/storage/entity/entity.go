@@ -4,4 +4,8 @@ type User struct {
 	ID        int64  `json:"id"`
 	Name      string `json:"name"`
 	CreatedAt string `json:"createdAt"` // in ISO 8601 format (e.g., "2022-04-14T11:12:22.758Z") RFC3339
+	// Version is incremented on every update of the record, absent until the first one.
+	Version int64 `json:"version,omitempty"`
+	// UpdatedAt is the time of the last update in RFC3339, absent until the first one.
+	UpdatedAt string `json:"updatedAt,omitempty"`
 }
@@ -1,9 +1,13 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"sync"
 
 	_ "embed"
@@ -11,29 +15,58 @@ import (
 	"surf_challenge/internal/user/storage/entity"
 )
 
-var ErrUserNotFound = errors.New("user not found")
-
 var (
-	usersOnce  sync.Once
-	usersCache []*entity.User
-	totalUsers int
-	errUsers   error
+	ErrUserNotFound = errors.New("user not found")
+	// ErrLoadUsers signals that the users dataset could not be loaded.
+	ErrLoadUsers = errors.New("failed to load users")
 )
 
 //go:generate mockgen -source=repository.go -destination=repository_mock.go -package=storage
 type Repository interface {
 	QueryUsers(ctx context.Context, id *int64, page int, size int) ([]*entity.User, int, error)
 	GetUserByID(ctx context.Context, id int64) (*entity.User, error)
+	// GetUsersByIDs returns the users among ids in dataset order, skipping unknown ones.
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error)
+	Count(ctx context.Context) (int, error)
+}
+
+type userRepository struct {
+	path   string
+	onLoad func(records int, err error)
+
+	once       sync.Once
+	users      []*entity.User
+	totalUsers int
+	err        error
 }
 
-type userRepository struct{}
+type Option func(*userRepository)
+
+// WithLoadObserver registers a hook called after every dataset load with the number
+// of records read or the load error.
+func WithLoadObserver(onLoad func(records int, err error)) Option {
+	return func(ur *userRepository) {
+		ur.onLoad = onLoad
+	}
+}
+
+// NewRepository returns a repository reading users from the JSON file at path,
+// or from the embedded dataset when path is empty.
+func NewRepository(path string, opts ...Option) Repository {
+	ur := &userRepository{
+		path:   path,
+		onLoad: func(int, error) {},
+	}
+
+	for _, opt := range opts {
+		opt(ur)
+	}
 
-func NewRepository() Repository {
-	return &userRepository{}
+	return ur
 }
 
 func (ur *userRepository) QueryUsers(_ context.Context, id *int64, page int, size int) ([]*entity.User, int, error) {
-	users, totalResults, err := loadFileWithUsers()
+	users, totalResults, err := ur.loadFileWithUsers()
 	if err != nil {
 		return nil, 0, err
 	}
@@ -60,7 +93,7 @@ func (ur *userRepository) QueryUsers(_ context.Context, id *int64, page int, siz
 }
 
 func (ur *userRepository) GetUserByID(_ context.Context, id int64) (*entity.User, error) {
-	users, _, err := loadFileWithUsers()
+	users, _, err := ur.loadFileWithUsers()
 	if err != nil {
 		return nil, err
 	}
@@ -74,25 +107,83 @@ func (ur *userRepository) GetUserByID(_ context.Context, id int64) (*entity.User
 	return nil, ErrUserNotFound
 }
 
+func (ur *userRepository) GetUsersByIDs(_ context.Context, ids []int64) ([]*entity.User, error) {
+	users, _, err := ur.loadFileWithUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		wanted[id] = struct{}{}
+	}
+
+	found := make([]*entity.User, 0, len(wanted))
+
+	for _, user := range users {
+		if _, ok := wanted[user.ID]; ok {
+			found = append(found, user)
+		}
+	}
+
+	return found, nil
+}
+
+// Count loads the dataset if needed and returns the number of stored users.
+func (ur *userRepository) Count(_ context.Context) (int, error) {
+	_, total, err := ur.loadFileWithUsers()
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 //go:embed db/users.json
 var usersFile []byte
 
-func loadFileWithUsers() ([]*entity.User, int, error) {
-	usersOnce.Do(
+// Open returns the raw dataset at path, or the embedded one when path is empty.
+func Open(path string) (io.ReadCloser, error) {
+	if path == "" {
+		return io.NopCloser(bytes.NewReader(usersFile)), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadUsers, err)
+	}
+
+	return f, nil
+}
+
+func (ur *userRepository) loadFileWithUsers() ([]*entity.User, int, error) {
+	ur.once.Do(
 		func() {
-			usersCache, totalUsers, errUsers = parseUsersFile()
+			ur.users, ur.totalUsers, ur.err = parseUsersFile(ur.path)
+			ur.onLoad(ur.totalUsers, ur.err)
 		},
 	)
 
-	return usersCache, totalUsers, errUsers
+	return ur.users, ur.totalUsers, ur.err
 }
 
-func parseUsersFile() ([]*entity.User, int, error) {
+func parseUsersFile(path string) ([]*entity.User, int, error) {
+	data := usersFile
+
+	if path != "" {
+		var err error
+
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", ErrLoadUsers, err)
+		}
+	}
+
 	var users []*entity.User
 
-	err := json.Unmarshal(usersFile, &users)
+	err := json.Unmarshal(data, &users)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, fmt.Errorf("%w: %w", ErrLoadUsers, err)
 	}
 
 	return users, len(users), nil
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"surf_challenge/internal/tracing"
+	"surf_challenge/internal/user/storage/entity"
+)
+
+// tracedRepository decorates a Repository with one span per call.
+type tracedRepository struct {
+	next   Repository
+	tracer trace.Tracer
+}
+
+func NewTracedRepository(next Repository, tracer trace.Tracer) Repository {
+	return &tracedRepository{
+		next:   next,
+		tracer: tracer,
+	}
+}
+
+func (r *tracedRepository) QueryUsers(
+	ctx context.Context,
+	id *int64,
+	page int,
+	size int,
+) (users []*entity.User, total int, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.Int("query.page", page),
+		attribute.Int("query.page_size", size),
+	}
+	if id != nil {
+		attrs = append(attrs, attribute.Int64("user.id", *id))
+	}
+
+	ctx, span := r.tracer.Start(ctx, "user.Repository/QueryUsers", trace.WithAttributes(attrs...))
+	defer tracing.End(span, &err)
+
+	users, total, err = r.next.QueryUsers(ctx, id, page, size)
+	span.SetAttributes(attribute.Int("result.size", len(users)), attribute.Int("result.total", total))
+
+	return users, total, err
+}
+
+func (r *tracedRepository) GetUserByID(ctx context.Context, id int64) (user *entity.User, err error) {
+	ctx, span := r.tracer.Start(
+		ctx, "user.Repository/GetUserByID",
+		trace.WithAttributes(attribute.Int64("user.id", id)),
+	)
+	defer tracing.End(span, &err)
+
+	return r.next.GetUserByID(ctx, id)
+}
+
+func (r *tracedRepository) GetUsersByIDs(ctx context.Context, ids []int64) (users []*entity.User, err error) {
+	ctx, span := r.tracer.Start(
+		ctx, "user.Repository/GetUsersByIDs",
+		trace.WithAttributes(attribute.Int("batch.size", len(ids))),
+	)
+	defer tracing.End(span, &err)
+
+	users, err = r.next.GetUsersByIDs(ctx, ids)
+	span.SetAttributes(attribute.Int("result.size", len(users)))
+
+	return users, err
+}
+
+func (r *tracedRepository) Count(ctx context.Context) (count int, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.Repository/Count")
+	defer tracing.End(span, &err)
+
+	count, err = r.next.Count(ctx)
+	span.SetAttributes(attribute.Int("result.size", count))
+
+	return count, err
+}
@@ -0,0 +1,85 @@
+package user
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"surf_challenge/internal/tracing"
+	"surf_challenge/internal/user/domain"
+)
+
+// tracedService decorates a Service with one span per call.
+type tracedService struct {
+	next   Service
+	tracer trace.Tracer
+}
+
+func NewTracedService(next Service, tracer trace.Tracer) Service {
+	return &tracedService{
+		next:   next,
+		tracer: tracer,
+	}
+}
+
+func (s *tracedService) QueryUsers(
+	ctx context.Context,
+	query domain.Query,
+) (users []*domain.User, results *domain.Results, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.Int("query.page", query.Page),
+		attribute.Int("query.page_size", query.PageSize),
+	}
+	if query.ID != nil {
+		attrs = append(attrs, attribute.Int64("user.id", *query.ID))
+	}
+
+	ctx, span := s.tracer.Start(ctx, "user.Service/QueryUsers", trace.WithAttributes(attrs...))
+	defer tracing.End(span, &err)
+
+	users, results, err = s.next.QueryUsers(ctx, query)
+	span.SetAttributes(attribute.Int("result.size", len(users)))
+
+	if results != nil {
+		span.SetAttributes(attribute.Int("result.total", results.TotalItems))
+	}
+
+	return users, results, err
+}
+
+func (s *tracedService) GetUserActionCount(ctx context.Context, userID int64) (count int, err error) {
+	ctx, span := s.tracer.Start(
+		ctx, "user.Service/GetUserActionCount",
+		trace.WithAttributes(attribute.Int64("user.id", userID)),
+	)
+	defer tracing.End(span, &err)
+
+	count, err = s.next.GetUserActionCount(ctx, userID)
+	span.SetAttributes(attribute.Int("result.size", count))
+
+	return count, err
+}
+
+func (s *tracedService) GetUserByID(ctx context.Context, id int64) (user *domain.User, err error) {
+	ctx, span := s.tracer.Start(
+		ctx, "user.Service/GetUserByID",
+		trace.WithAttributes(attribute.Int64("user.id", id)),
+	)
+	defer tracing.End(span, &err)
+
+	return s.next.GetUserByID(ctx, id)
+}
+
+func (s *tracedService) GetUsersByIDs(ctx context.Context, ids []int64) (users []*domain.User, err error) {
+	ctx, span := s.tracer.Start(
+		ctx, "user.Service/GetUsersByIDs",
+		trace.WithAttributes(attribute.Int("batch.size", len(ids))),
+	)
+	defer tracing.End(span, &err)
+
+	users, err = s.next.GetUsersByIDs(ctx, ids)
+	span.SetAttributes(attribute.Int("result.size", len(users)))
+
+	return users, err
+}
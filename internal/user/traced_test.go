@@ -0,0 +1,99 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+
+	"surf_challenge/internal/converter"
+	"surf_challenge/internal/tracing"
+	"surf_challenge/internal/user/domain"
+)
+
+func Test_tracedService(t *testing.T) {
+	tests := []struct {
+		name       string
+		mock       func(m *MockService)
+		call       func(s Service)
+		wantName   string
+		wantStatus codes.Code
+		wantAttrs  []attribute.KeyValue
+	}{
+		{
+			name: "should trace users query with pagination and results",
+			mock: func(m *MockService) {
+				m.EXPECT().QueryUsers(gomock.Any(), gomock.Any()).
+					Return([]*domain.User{{ID: 1}}, &domain.Results{TotalItems: 30}, nil)
+			},
+			call: func(s Service) {
+				_, _, _ = s.QueryUsers(t.Context(), domain.Query{ID: converter.ToPtr(int64(1)), Page: 2, PageSize: 5})
+			},
+			wantName:   "user.Service/QueryUsers",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int("query.page", 2),
+				attribute.Int("query.page_size", 5),
+				attribute.Int64("user.id", 1),
+				attribute.Int("result.size", 1),
+				attribute.Int("result.total", 30),
+			},
+		},
+		{
+			name: "should trace action count with the user ID",
+			mock: func(m *MockService) {
+				m.EXPECT().GetUserActionCount(gomock.Any(), int64(4)).Return(12, nil)
+			},
+			call: func(s Service) {
+				_, _ = s.GetUserActionCount(t.Context(), 4)
+			},
+			wantName:   "user.Service/GetUserActionCount",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int64("user.id", 4),
+				attribute.Int("result.size", 12),
+			},
+		},
+		{
+			name: "should record not found errors on the span",
+			mock: func(m *MockService) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(9)).Return(nil, ErrNotFound)
+			},
+			call: func(s Service) {
+				_, _ = s.GetUserByID(t.Context(), 9)
+			},
+			wantName:   "user.Service/GetUserByID",
+			wantStatus: codes.Error,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int64("user.id", 9),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				next := NewMockService(ctrl)
+				tt.mock(next)
+
+				recorder := tracetest.NewSpanRecorder()
+				provider := tracing.NewWithProcessor(recorder, sdktrace.AlwaysSample())
+
+				tt.call(NewTracedService(next, provider.Tracer()))
+
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+				assert.Equal(t, tt.wantName, spans[0].Name())
+				assert.Equal(t, tt.wantStatus, spans[0].Status().Code)
+				assert.ElementsMatch(t, tt.wantAttrs, spans[0].Attributes())
+			},
+		)
+	}
+}
@@ -0,0 +1,323 @@
+// Package validation checks the users and actions datasets for the inconsistencies the
+// services assume away, and reports them in a machine-readable form.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"surf_challenge/internal/action/domain"
+	"surf_challenge/internal/jsonstream"
+)
+
+// Files an issue can be found in.
+const (
+	FileUsers   = "users"
+	FileActions = "actions"
+)
+
+// Issue codes.
+const (
+	// CodeMalformedJSON is reported for a syntax error, which stops the reading of the file.
+	CodeMalformedJSON = "malformed_json"
+	// CodeInvalidRecord is reported for a record whose fields have the wrong JSON type.
+	CodeInvalidRecord      = "invalid_record"
+	CodeMissingField       = "missing_field"
+	CodeDuplicateID        = "duplicate_id"
+	CodeInvalidCreatedAt   = "invalid_created_at"
+	CodeUnknownActionType  = "unknown_action_type"
+	CodeUnknownUser        = "unknown_user"
+	CodeUnknownTargetUser  = "unknown_target_user"
+	CodeActionBeforeSignup = "action_before_signup"
+	CodeMissingTargetUser  = "missing_target_user"
+)
+
+// DefaultMaxIssues bounds the issues listed in a report, all of them being counted.
+const DefaultMaxIssues = 1000
+
+// Options configure the checks.
+type Options struct {
+	// ActionTypes are the known action types, compared case-insensitively, domain.ActionTypes
+	// being used when empty.
+	ActionTypes []string
+	// MaxIssues bounds the issues listed, DefaultMaxIssues being used when not positive.
+	MaxIssues int
+}
+
+// Issue is a problem found in a record.
+type Issue struct {
+	File string `json:"file"`
+	// Record is the index of the record in the file, -1 when the issue is not about a
+	// single record.
+	Record  int    `json:"record"`
+	Line    int    `json:"line"`
+	Offset  int64  `json:"offset"`
+	ID      *int64 `json:"id,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// FileSummary counts the records read from a file and the issues found in them.
+type FileSummary struct {
+	Records int `json:"records"`
+	Issues  int `json:"issues"`
+	// Complete is false when a syntax error stopped the reading of the file.
+	Complete bool `json:"complete"`
+}
+
+// Report is the result of a validation.
+type Report struct {
+	Valid   bool           `json:"valid"`
+	Users   FileSummary    `json:"users"`
+	Actions FileSummary    `json:"actions"`
+	Counts  map[string]int `json:"counts"`
+	Issues  []Issue        `json:"issues"`
+	// Truncated is set when more issues were found than listed.
+	Truncated bool `json:"truncated"`
+}
+
+type userRecord struct {
+	ID        *int64  `json:"id"`
+	CreatedAt *string `json:"createdAt"`
+}
+
+type actionRecord struct {
+	ID         *int64  `json:"id"`
+	Type       *string `json:"type"`
+	UserID     *int64  `json:"userId"`
+	TargetUser *int64  `json:"targetUser"`
+	CreatedAt  *string `json:"createdAt"`
+}
+
+type validator struct {
+	opts   Options
+	report *Report
+	// signups maps the user IDs to their signup time, zero when it is invalid.
+	signups map[int64]time.Time
+}
+
+// Validate checks the users and actions datasets, reading each record once. The
+// references to users are only checked when the users file was read completely.
+// Errors are returned for failed reads, problems in the data being reported.
+func Validate(users, actions io.Reader, opts Options) (*Report, error) {
+	if opts.MaxIssues < 1 {
+		opts.MaxIssues = DefaultMaxIssues
+	}
+
+	if len(opts.ActionTypes) == 0 {
+		opts.ActionTypes = domain.ActionTypes
+	}
+
+	v := &validator{
+		opts:    opts,
+		report:  &Report{Counts: map[string]int{}, Issues: []Issue{}},
+		signups: make(map[int64]time.Time),
+	}
+
+	err := v.users(users)
+	if err != nil {
+		return nil, fmt.Errorf("reading users: %w", err)
+	}
+
+	err = v.actions(actions)
+	if err != nil {
+		return nil, fmt.Errorf("reading actions: %w", err)
+	}
+
+	v.report.Valid = v.report.Users.Issues == 0 && v.report.Actions.Issues == 0
+
+	return v.report, nil
+}
+
+func (v *validator) users(r io.Reader) error {
+	summary := &v.report.Users
+	firstSeen := make(map[int64]int)
+
+	return v.each(
+		r, FileUsers, summary, func() any { return &userRecord{} }, func(index int, pos jsonstream.Position, rec any) {
+			u := rec.(*userRecord)
+			report := v.reporter(FileUsers, summary, index, pos, u.ID)
+
+			if u.ID == nil {
+				report(CodeMissingField, "missing id")
+			} else if first, ok := firstSeen[*u.ID]; ok {
+				report(CodeDuplicateID, fmt.Sprintf("user ID %d already used by record %d", *u.ID, first))
+			} else {
+				firstSeen[*u.ID] = index
+			}
+
+			createdAt, _ := v.createdAt(u.CreatedAt, report)
+
+			if u.ID == nil {
+				return
+			}
+
+			if _, known := v.signups[*u.ID]; !known {
+				v.signups[*u.ID] = createdAt
+			}
+		},
+	)
+}
+
+func (v *validator) actions(r io.Reader) error {
+	summary := &v.report.Actions
+	firstSeen := make(map[int64]int)
+	checkUsers := v.report.Users.Complete
+
+	return v.each(
+		r, FileActions, summary, func() any { return &actionRecord{} }, func(index int, pos jsonstream.Position, rec any) {
+			a := rec.(*actionRecord)
+			report := v.reporter(FileActions, summary, index, pos, a.ID)
+
+			if a.ID == nil {
+				report(CodeMissingField, "missing id")
+			} else if first, ok := firstSeen[*a.ID]; ok {
+				report(CodeDuplicateID, fmt.Sprintf("action ID %d already used by record %d", *a.ID, first))
+			} else {
+				firstSeen[*a.ID] = index
+			}
+
+			createdAt, dated := v.createdAt(a.CreatedAt, report)
+
+			switch {
+			case a.Type == nil:
+				report(CodeMissingField, "missing type")
+			case !v.knownType(*a.Type):
+				report(CodeUnknownActionType, fmt.Sprintf("unknown action type %q", *a.Type))
+			case strings.EqualFold(*a.Type, domain.ActionTypeReferUser) && a.TargetUser == nil:
+				report(CodeMissingTargetUser, "REFER_USER action without targetUser")
+			}
+
+			if a.UserID == nil {
+				report(CodeMissingField, "missing userId")
+			}
+
+			if !checkUsers {
+				return
+			}
+
+			if a.UserID != nil {
+				signup, known := v.signups[*a.UserID]
+
+				switch {
+				case !known:
+					report(CodeUnknownUser, fmt.Sprintf("userId %d does not match any user", *a.UserID))
+				case dated && createdAt.Before(signup):
+					report(
+						CodeActionBeforeSignup, fmt.Sprintf(
+							"action created at %s, before user %d signed up at %s",
+							createdAt.Format(time.RFC3339), *a.UserID, signup.Format(time.RFC3339),
+						),
+					)
+				}
+			}
+
+			if a.TargetUser != nil {
+				if _, known := v.signups[*a.TargetUser]; !known {
+					report(CodeUnknownTargetUser, fmt.Sprintf("targetUser %d does not match any user", *a.TargetUser))
+				}
+			}
+		},
+	)
+}
+
+// each decodes the records of a file with newRecord and checks them with check,
+// reporting the records that cannot be decoded.
+func (v *validator) each(
+	r io.Reader, file string, summary *FileSummary, newRecord func() any, check func(int, jsonstream.Position, any),
+) error {
+	dec := jsonstream.NewDecoder(r)
+
+	for index := 0; ; index++ {
+		rec := newRecord()
+
+		pos, err := dec.Next(rec)
+		if errors.Is(err, io.EOF) {
+			summary.Complete = true
+
+			return nil
+		}
+
+		var streamErr *jsonstream.Error
+		if err != nil && !errors.As(err, &streamErr) {
+			return err
+		}
+
+		if err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				v.add(
+					file, summary, Issue{
+						Record: -1, Line: streamErr.Line, Offset: streamErr.Offset, Code: CodeMalformedJSON, Message: streamErr.Err.Error(),
+					},
+				)
+
+				return nil
+			}
+
+			summary.Records++
+			v.reporter(file, summary, index, pos, nil)(CodeInvalidRecord, streamErr.Err.Error())
+
+			continue
+		}
+
+		summary.Records++
+		check(index, pos, rec)
+	}
+}
+
+// reporter returns a function adding the issues of a record.
+func (v *validator) reporter(
+	file string, summary *FileSummary, index int, pos jsonstream.Position, id *int64,
+) func(code, message string) {
+	return func(code, message string) {
+		v.add(file, summary, Issue{Record: index, Line: pos.Line, Offset: pos.Offset, ID: id, Code: code, Message: message})
+	}
+}
+
+func (v *validator) add(file string, summary *FileSummary, issue Issue) {
+	issue.File = file
+	summary.Issues++
+	v.report.Counts[issue.Code]++
+
+	if len(v.report.Issues) == v.opts.MaxIssues {
+		v.report.Truncated = true
+
+		return
+	}
+
+	v.report.Issues = append(v.report.Issues, issue)
+}
+
+// createdAt parses the createdAt field like the mappers do, reporting it when it
+// is missing or invalid.
+func (v *validator) createdAt(value *string, report func(code, message string)) (time.Time, bool) {
+	if value == nil {
+		report(CodeMissingField, "missing createdAt")
+
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		report(CodeInvalidCreatedAt, fmt.Sprintf("createdAt %q is not an RFC 3339 time", *value))
+
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+func (v *validator) knownType(typ string) bool {
+	for _, known := range v.opts.ActionTypes {
+		if strings.EqualFold(typ, known) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,196 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	actionstorage "surf_challenge/internal/action/storage"
+	userstorage "surf_challenge/internal/user/storage"
+)
+
+const validUsers = `[
+	{"id":0,"name":"Ada","createdAt":"2021-01-01T00:00:00Z"},
+	{"id":1,"name":"Bob","createdAt":"2021-01-02T00:00:00Z"}
+]`
+
+func ptr(v int64) *int64 {
+	return &v
+}
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		users      string
+		actions    string
+		opts       Options
+		wantIssues []Issue
+		wantUsers  FileSummary
+	}{
+		{
+			name:  "should accept consistent datasets",
+			users: validUsers,
+			actions: `[
+				{"id":1,"type":"WELCOME","userId":0,"createdAt":"2021-01-01T00:00:01Z"},
+				{"id":2,"type":"REFER_USER","userId":0,"targetUser":1,"createdAt":"2021-01-03T00:00:00Z"}
+			]`,
+			wantUsers: FileSummary{Records: 2, Complete: true},
+		},
+		{
+			name: "should report duplicate IDs and invalid createdAt in users",
+			users: `[
+	{"id":0,"createdAt":"2021-01-01T00:00:00Z"},
+	{"id":0,"createdAt":"yesterday"},
+	{"createdAt":"2021-01-01T00:00:00Z"}
+]`,
+			actions:   `[]`,
+			wantUsers: FileSummary{Records: 3, Issues: 3, Complete: true},
+			wantIssues: []Issue{
+				{
+					File: FileUsers, Record: 1, Line: 3, Offset: 49, ID: ptr(0), Code: CodeDuplicateID,
+					Message: "user ID 0 already used by record 0",
+				},
+				{
+					File: FileUsers, Record: 1, Line: 3, Offset: 49, ID: ptr(0), Code: CodeInvalidCreatedAt,
+					Message: `createdAt "yesterday" is not an RFC 3339 time`,
+				},
+				{File: FileUsers, Record: 2, Line: 4, Offset: 84, Code: CodeMissingField, Message: "missing id"},
+			},
+		},
+		{
+			name:  "should report inconsistent actions",
+			users: validUsers,
+			actions: `[
+{"id":1,"type":"WELCOME","userId":0,"createdAt":"2020-12-31T00:00:00Z"},
+{"id":1,"type":"CHECKOUT","userId":7,"createdAt":"2021-01-05T00:00:00Z"},
+{"id":2,"type":"refer_user","userId":1,"createdAt":"2021-01-05T00:00:00Z"},
+{"id":3,"type":"REFER_USER","userId":1,"targetUser":9,"createdAt":"2021-01-05"}
+]`,
+			wantUsers: FileSummary{Records: 2, Complete: true},
+			wantIssues: []Issue{
+				{
+					File: FileActions, Record: 0, Line: 2, Offset: 2, ID: ptr(1), Code: CodeActionBeforeSignup,
+					Message: "action created at 2020-12-31T00:00:00Z, before user 0 signed up at 2021-01-01T00:00:00Z",
+				},
+				{
+					File: FileActions, Record: 1, Line: 3, Offset: 75, ID: ptr(1), Code: CodeDuplicateID,
+					Message: "action ID 1 already used by record 0",
+				},
+				{
+					File: FileActions, Record: 1, Line: 3, Offset: 75, ID: ptr(1), Code: CodeUnknownActionType,
+					Message: `unknown action type "CHECKOUT"`,
+				},
+				{
+					File: FileActions, Record: 1, Line: 3, Offset: 75, ID: ptr(1), Code: CodeUnknownUser,
+					Message: "userId 7 does not match any user",
+				},
+				{
+					File: FileActions, Record: 2, Line: 4, Offset: 149, ID: ptr(2), Code: CodeMissingTargetUser,
+					Message: "REFER_USER action without targetUser",
+				},
+				{
+					File: FileActions, Record: 3, Line: 5, Offset: 225, ID: ptr(3), Code: CodeInvalidCreatedAt,
+					Message: `createdAt "2021-01-05" is not an RFC 3339 time`,
+				},
+				{
+					File: FileActions, Record: 3, Line: 5, Offset: 225, ID: ptr(3), Code: CodeUnknownTargetUser,
+					Message: "targetUser 9 does not match any user",
+				},
+			},
+		},
+		{
+			name:  "should accept the action types given",
+			users: validUsers,
+			actions: `[
+				{"id":1,"type":"CHECKOUT","userId":0,"createdAt":"2021-01-05T00:00:00Z"}
+			]`,
+			opts:      Options{ActionTypes: []string{"CHECKOUT"}},
+			wantUsers: FileSummary{Records: 2, Complete: true},
+		},
+		{
+			name:  "should report records of the wrong type and go on",
+			users: validUsers,
+			actions: `[
+{"id":"one","type":"WELCOME","userId":0,"createdAt":"2021-01-05T00:00:00Z"},
+{"id":2,"type":"WELCOME","userId":0,"createdAt":"2021-01-05T00:00:00Z"}
+]`,
+			wantUsers: FileSummary{Records: 2, Complete: true},
+			wantIssues: []Issue{
+				{
+					File: FileActions, Record: 0, Line: 2, Offset: 2, Code: CodeInvalidRecord,
+					Message: "json: cannot unmarshal string into Go struct field actionRecord.id of type int64",
+				},
+			},
+		},
+		{
+			name: "should stop at malformed JSON and skip the user references",
+			users: `[
+	{"id":0,"createdAt":"2021-01-01T00:00:00Z"},
+	{"id":1 "createdAt":"2021-01-01T00:00:00Z"}
+]`,
+			actions: `[
+				{"id":1,"type":"WELCOME","userId":5,"createdAt":"2021-01-05T00:00:00Z"}
+			]`,
+			wantUsers: FileSummary{Records: 1, Issues: 1},
+			wantIssues: []Issue{
+				{
+					File: FileUsers, Record: -1, Line: 3, Offset: 57, Code: CodeMalformedJSON,
+					Message: "invalid character '\"' after object key:value pair",
+				},
+			},
+		},
+		{
+			name:      "should list a bounded number of issues",
+			users:     `[{"id":0},{"id":1},{"id":2}]`,
+			actions:   `[]`,
+			opts:      Options{MaxIssues: 2},
+			wantUsers: FileSummary{Records: 3, Issues: 3, Complete: true},
+			wantIssues: []Issue{
+				{File: FileUsers, Record: 0, Line: 1, Offset: 1, ID: ptr(0), Code: CodeMissingField, Message: "missing createdAt"},
+				{File: FileUsers, Record: 1, Line: 1, Offset: 10, ID: ptr(1), Code: CodeMissingField, Message: "missing createdAt"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := Validate(strings.NewReader(tt.users), strings.NewReader(tt.actions), tt.opts)
+				require.NoError(t, err)
+
+				want := tt.wantIssues
+				if want == nil {
+					want = []Issue{}
+				}
+
+				assert.Equal(t, want, got.Issues)
+				assert.Equal(t, tt.wantUsers, got.Users)
+				assert.Equal(t, got.Users.Issues == 0 && got.Actions.Issues == 0, got.Valid)
+				assert.Equal(t, tt.opts.MaxIssues > 0 && got.Users.Issues+got.Actions.Issues > tt.opts.MaxIssues, got.Truncated)
+
+				total := 0
+				for _, count := range got.Counts {
+					total += count
+				}
+
+				assert.Equal(t, got.Users.Issues+got.Actions.Issues, total)
+			},
+		)
+	}
+}
+
+func Test_Validate_embeddedDatasets(t *testing.T) {
+	users, err := userstorage.Open("")
+	require.NoError(t, err)
+
+	actions, err := actionstorage.Open("")
+	require.NoError(t, err)
+
+	got, err := Validate(users, actions, Options{})
+	require.NoError(t, err)
+
+	assert.True(t, got.Valid, got.Issues)
+	assert.Equal(t, FileSummary{Records: 1000, Complete: true}, got.Users)
+	assert.Equal(t, FileSummary{Records: 22938, Complete: true}, got.Actions)
+}
@@ -0,0 +1,597 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	actiondomain "surf_challenge/internal/action/domain"
+	"surf_challenge/internal/feed"
+	"surf_challenge/internal/webhook/domain"
+	"surf_challenge/internal/webhook/mapper"
+	"surf_challenge/internal/webhook/storage"
+)
+
+// userAgent identifies deliveries to receivers.
+const userAgent = "surf-webhooks/1"
+
+var (
+	// ErrQueueFull is recorded on deliveries that could not be queued, which are
+	// dead-lettered straight away.
+	ErrQueueFull = errors.New("delivery queue full")
+	// ErrUnexpectedStatus fails the attempts answered with anything but a 2xx.
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+)
+
+// Options tunes the dispatcher. LogSize bounds both the delivery log and the
+// dead-letter list, and sizes the delivery queue.
+type Options struct {
+	Workers        int
+	Timeout        time.Duration
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	LogSize        int
+	Milestones     []int
+	// AllowPrivateHosts lets endpoints reach loopback, link-local and private addresses,
+	// for local testing only.
+	AllowPrivateHosts bool
+}
+
+// Dispatcher turns the actions published on the feed into events, and delivers them to
+// the subscribed endpoints. Failed deliveries are retried with an exponential backoff,
+// then dead-lettered.
+type Dispatcher struct {
+	logger    *zap.SugaredLogger
+	repo      storage.Repository
+	referrals func(ctx context.Context) (map[int]int, error)
+	opts      Options
+	client    *http.Client
+	queue     chan *record
+
+	// baseline is the referral count of every user at the last milestone check. It is
+	// only used by the Run goroutine.
+	baseline map[int]int
+
+	mu          sync.Mutex
+	ctx         context.Context
+	log         []*record
+	deadLetters []*record
+}
+
+// record is a delivery along with what is needed to send it.
+type record struct {
+	delivery domain.Delivery
+	body     []byte
+	// firstAttempt is the index in delivery.Attempts of the first attempt counted
+	// against MaxAttempts, moved on when a dead letter is retried.
+	firstAttempt int
+}
+
+// NewDispatcher returns a dispatcher delivering to the endpoints of repo. referrals
+// returns the referral count of every user, compared between feed events to detect
+// referral milestones.
+func NewDispatcher(
+	logger *zap.SugaredLogger,
+	repo storage.Repository,
+	referrals func(ctx context.Context) (map[int]int, error),
+	opts Options,
+) *Dispatcher {
+	opts.Milestones = slices.Sorted(slices.Values(opts.Milestones))
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !opts.AllowPrivateHosts {
+		// A proxy would connect on our behalf, out of reach of the address check.
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{Timeout: opts.Timeout, Control: dialPublic}).DialContext
+	}
+
+	return &Dispatcher{
+		logger:    logger,
+		repo:      repo,
+		referrals: referrals,
+		opts:      opts,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			// A redirect is not an acknowledgement, and following it would send the
+			// signed payload to a URL that was never registered.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		queue: make(chan *record, opts.LogSize),
+	}
+}
+
+// Run delivers the events derived from f until ctx is done. Deliveries still waiting
+// for a retry at that point are abandoned.
+func (d *Dispatcher) Run(ctx context.Context, f *feed.Feed) {
+	d.mu.Lock()
+	d.ctx = ctx
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	for range d.opts.Workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			d.work(ctx)
+		}()
+	}
+
+	d.checkMilestones(ctx)
+	d.consume(ctx, f)
+
+	wg.Wait()
+}
+
+// consume reads the feed, subscribing again from the last event received whenever the
+// subscription is dropped for lagging behind. The first subscription replays the
+// retained events, published before Run got to subscribe.
+func (d *Dispatcher) consume(ctx context.Context, f *feed.Feed) {
+	var lastID uint64
+
+	for {
+		sub := f.Subscribe(actiondomain.Filter{}, lastID, true)
+
+		referred := false
+
+		for _, event := range sub.Backlog {
+			referred = d.publish(ctx, event.Action) || referred
+			lastID = event.ID
+		}
+
+		if referred {
+			d.checkMilestones(ctx)
+		}
+
+		lastID = d.drain(ctx, sub, lastID)
+
+		sub.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.logger.Warnw("Webhook dispatcher lagged behind the feed, resuming", "lastEventId", lastID)
+	}
+}
+
+// drain publishes the events of sub until it is closed or ctx is done, and returns the
+// ID of the last one. Referral milestones are checked once the pending events are
+// published, rather than after every referral.
+func (d *Dispatcher) drain(ctx context.Context, sub *feed.Subscription, lastID uint64) uint64 {
+	for {
+		select {
+		case <-ctx.Done():
+			return lastID
+		case event, ok := <-sub.Events():
+			if !ok {
+				return lastID
+			}
+
+			referred := d.publish(ctx, event.Action)
+			lastID = event.ID
+
+			for pending := true; pending; {
+				select {
+				case event, ok := <-sub.Events():
+					if !ok {
+						pending = false
+
+						break
+					}
+
+					referred = d.publish(ctx, event.Action) || referred
+					lastID = event.ID
+				default:
+					pending = false
+				}
+			}
+
+			if referred {
+				d.checkMilestones(ctx)
+			}
+		}
+	}
+}
+
+// publish dispatches the events of an ingested action, and tells whether it is a
+// referral.
+func (d *Dispatcher) publish(ctx context.Context, a *actiondomain.Action) bool {
+	events := []*domain.Event{newEvent(domain.EventActionCreated, a)}
+
+	referral := strings.EqualFold(a.Type, actiondomain.ActionTypeReferUser) && a.TargetUser != 0
+	if referral {
+		events = append(events, newEvent(domain.EventUserReferred, a))
+	}
+
+	d.dispatch(ctx, events...)
+
+	return referral
+}
+
+// checkMilestones dispatches a referral.milestone event for every milestone a user
+// reached since the previous check. The first check only records the baseline.
+func (d *Dispatcher) checkMilestones(ctx context.Context) {
+	if len(d.opts.Milestones) == 0 {
+		return
+	}
+
+	counts, err := d.referrals(ctx)
+	if err != nil {
+		d.logger.Warnw("failed to count referrals for webhook milestones", "error", err)
+
+		return
+	}
+
+	previous := d.baseline
+	d.baseline = counts
+
+	if previous == nil {
+		return
+	}
+
+	var events []*domain.Event
+
+	for _, userID := range slices.Sorted(maps.Keys(counts)) {
+		for _, milestone := range d.opts.Milestones {
+			if previous[userID] < milestone && milestone <= counts[userID] {
+				event := newEvent(domain.EventReferralMilestone, nil)
+				event.UserID, event.Referrals, event.Milestone = userID, counts[userID], milestone
+
+				events = append(events, event)
+			}
+		}
+	}
+
+	d.dispatch(ctx, events...)
+}
+
+// dispatch queues a delivery of every event to each endpoint subscribing to it.
+func (d *Dispatcher) dispatch(ctx context.Context, events ...*domain.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	webhooks, err := d.repo.List(ctx)
+	if err != nil {
+		d.logger.Errorw("failed to list webhooks", "error", err)
+
+		return
+	}
+
+	endpoints, err := mapper.MapWebhooksEntToDomain(webhooks)
+	if err != nil {
+		d.logger.Errorw("failed to map webhooks to domain", "error", err)
+
+		return
+	}
+
+	for _, event := range events {
+		body, err := encodePayload(event)
+		if err != nil {
+			d.logger.Errorw("failed to encode webhook payload", "event", event.Type, "error", err)
+
+			continue
+		}
+
+		for _, endpoint := range endpoints {
+			if !endpoint.Subscribes(event) {
+				continue
+			}
+
+			rec := &record{
+				delivery: domain.Delivery{
+					ID:         newID("dlv_"),
+					EndpointID: endpoint.ID,
+					Event:      event,
+					Status:     domain.DeliveryPending,
+					CreatedAt:  time.Now(),
+				},
+				body: body,
+			}
+
+			d.mu.Lock()
+			d.log = appendBounded(d.log, rec, d.opts.LogSize)
+			d.mu.Unlock()
+
+			d.enqueue(rec)
+		}
+	}
+}
+
+// enqueue hands rec to the workers, or dead-letters it when the queue is full.
+func (d *Dispatcher) enqueue(rec *record) {
+	select {
+	case d.queue <- rec:
+	default:
+		d.logger.Warnw("Webhook delivery queue full", "delivery", rec.delivery.ID)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		rec.delivery.Attempts = append(
+			rec.delivery.Attempts, domain.Attempt{
+				Number: len(rec.delivery.Attempts) + 1,
+				At:     time.Now(),
+				Error:  ErrQueueFull.Error(),
+			},
+		)
+		d.fail(rec)
+	}
+}
+
+func (d *Dispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rec := <-d.queue:
+			d.attempt(ctx, rec)
+		}
+	}
+}
+
+// attempt sends rec once, then records the outcome and schedules a retry if needed.
+func (d *Dispatcher) attempt(ctx context.Context, rec *record) {
+	webhook, err := d.repo.Get(ctx, rec.delivery.EndpointID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrWebhookNotFound) {
+			d.logger.Errorw("failed to get webhook", "webhook", rec.delivery.EndpointID, "error", err)
+		}
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		// The endpoint is gone: there is nothing left to retry.
+		rec.delivery.Status = domain.DeliveryFailed
+		rec.delivery.NextAttemptAt = nil
+
+		return
+	}
+
+	at := time.Now()
+	statusCode, err := d.send(ctx, webhook.URL, webhook.Secret, rec, at)
+
+	attempt := domain.Attempt{At: at, Duration: time.Since(at), StatusCode: statusCode}
+	if err != nil {
+		attempt.Error = attemptError(err)
+	}
+
+	log := d.logger.With(
+		"delivery", rec.delivery.ID, "webhook", rec.delivery.EndpointID, "event", rec.delivery.Event.Type,
+		"statusCode", statusCode,
+	)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	attempt.Number = len(rec.delivery.Attempts) + 1
+	rec.delivery.Attempts = append(rec.delivery.Attempts, attempt)
+	rec.delivery.NextAttemptAt = nil
+
+	if err == nil {
+		rec.delivery.Status = domain.DeliverySucceeded
+
+		return
+	}
+
+	attempts := len(rec.delivery.Attempts) - rec.firstAttempt
+	if attempts >= d.opts.MaxAttempts {
+		log.Warnw("Webhook delivery failed, dead-lettering", "attempts", attempts, "error", err)
+		d.fail(rec)
+
+		return
+	}
+
+	delay := d.backoff(attempts)
+	next := at.Add(delay)
+	rec.delivery.NextAttemptAt = &next
+
+	log.Infow("Webhook delivery failed, retrying", "attempts", attempts, "retryIn", delay, "error", err)
+
+	time.AfterFunc(
+		delay, func() {
+			if ctx.Err() == nil {
+				d.enqueue(rec)
+			}
+		},
+	)
+}
+
+// send posts the payload of rec, signed at at, and returns the response status code.
+// Any response but a 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, url, secret string, rec *record, at time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(rec.body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, rec.delivery.Event.Type)
+	req.Header.Set(HeaderDelivery, rec.delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(at.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(secret, at, rec.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// Draining a bounded amount lets the connection be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// attemptError describes a failed attempt in the delivery log, which the admin API
+// serves: network errors are summed up, so that the log does not tell how the hosts
+// behind a URL answered. The full error is logged.
+func attemptError(err error) string {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, ErrUnexpectedStatus):
+		return err.Error()
+	case errors.Is(err, ErrPrivateHost):
+		return ErrPrivateHost.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
+
+// backoff returns the wait before the retry following the given number of attempts,
+// doubling from InitialBackoff up to MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.InitialBackoff
+
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= d.opts.MaxBackoff {
+			return d.opts.MaxBackoff
+		}
+	}
+
+	return min(delay, d.opts.MaxBackoff)
+}
+
+// fail moves rec to the dead-letter list. It must be called with the lock held.
+func (d *Dispatcher) fail(rec *record) {
+	rec.delivery.Status = domain.DeliveryFailed
+	rec.delivery.NextAttemptAt = nil
+	d.deadLetters = appendBounded(d.deadLetters, rec, d.opts.LogSize)
+}
+
+// Deliveries returns the logged deliveries to endpointID, most recent first.
+func (d *Dispatcher) Deliveries(endpointID string) []*domain.Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deliveries []*domain.Delivery
+
+	for _, rec := range slices.Backward(d.log) {
+		if rec.delivery.EndpointID == endpointID {
+			deliveries = append(deliveries, rec.snapshot())
+		}
+	}
+
+	return deliveries
+}
+
+// DeadLetters returns the deliveries that ran out of attempts, most recent first.
+func (d *Dispatcher) DeadLetters() []*domain.Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]*domain.Delivery, 0, len(d.deadLetters))
+	for _, rec := range slices.Backward(d.deadLetters) {
+		deliveries = append(deliveries, rec.snapshot())
+	}
+
+	return deliveries
+}
+
+// Redeliver takes a delivery out of the dead-letter list and queues it again, with a
+// fresh MaxAttempts budget. It reports false when deliveryID is not dead-lettered.
+func (d *Dispatcher) Redeliver(deliveryID string) (*domain.Delivery, bool) {
+	d.mu.Lock()
+
+	i := slices.IndexFunc(
+		d.deadLetters, func(rec *record) bool {
+			return rec.delivery.ID == deliveryID
+		},
+	)
+	if i < 0 || d.ctx == nil || d.ctx.Err() != nil {
+		d.mu.Unlock()
+
+		return nil, false
+	}
+
+	rec := d.deadLetters[i]
+	d.deadLetters = slices.Delete(d.deadLetters, i, i+1)
+
+	rec.delivery.Status = domain.DeliveryPending
+	rec.firstAttempt = len(rec.delivery.Attempts)
+
+	if !slices.Contains(d.log, rec) {
+		d.log = appendBounded(d.log, rec, d.opts.LogSize)
+	}
+
+	snapshot := rec.snapshot()
+	d.mu.Unlock()
+
+	d.enqueue(rec)
+
+	return snapshot, true
+}
+
+// snapshot copies the delivery so that it can be read without the lock. It must be
+// called with the lock held.
+func (rec *record) snapshot() *domain.Delivery {
+	delivery := rec.delivery
+	delivery.Attempts = slices.Clone(rec.delivery.Attempts)
+
+	if rec.delivery.NextAttemptAt != nil {
+		next := *rec.delivery.NextAttemptAt
+		delivery.NextAttemptAt = &next
+	}
+
+	return &delivery
+}
+
+// appendBounded appends rec to records, dropping the oldest ones beyond size.
+func appendBounded(records []*record, rec *record, size int) []*record {
+	records = append(records, rec)
+	if len(records) > size {
+		records = slices.Delete(records, 0, len(records)-size)
+	}
+
+	return records
+}
+
+func newEvent(eventType string, a *actiondomain.Action) *domain.Event {
+	return &domain.Event{
+		ID:        newID("evt_"),
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Action:    a,
+	}
+}
+
+// newID returns prefix followed by 16 random bytes in hex.
+func newID(prefix string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return prefix + hex.EncodeToString(b)
+}
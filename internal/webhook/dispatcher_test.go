@@ -0,0 +1,504 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	actiondomain "surf_challenge/internal/action/domain"
+	"surf_challenge/internal/feed"
+	"surf_challenge/internal/webhook/domain"
+	"surf_challenge/internal/webhook/storage"
+	"surf_challenge/internal/webhook/storage/entity"
+)
+
+// receiver is a webhook endpoint answering with the next of its statuses, then 200,
+// and recording the verified payloads.
+type receiver struct {
+	t        *testing.T
+	secret   string
+	statuses chan int
+
+	mu       sync.Mutex
+	payloads []map[string]any
+	received chan struct{}
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	rcv := &receiver{t: t, statuses: make(chan int, len(statuses)), received: make(chan struct{}, 100)}
+	for _, status := range statuses {
+		rcv.statuses <- status
+	}
+
+	server := httptest.NewServer(rcv)
+	t.Cleanup(server.Close)
+
+	return rcv, server
+}
+
+func (rcv *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rcv.t, err)
+
+	assert.True(
+		rcv.t, Verify(rcv.secret, r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body),
+		"delivery should be signed with the endpoint secret",
+	)
+	assert.NotEmpty(rcv.t, r.Header.Get(HeaderDelivery))
+
+	var payload map[string]any
+	require.NoError(rcv.t, json.Unmarshal(body, &payload))
+	assert.Equal(rcv.t, r.Header.Get(HeaderEvent), payload["type"])
+
+	status := http.StatusOK
+
+	select {
+	case status = <-rcv.statuses:
+	default:
+	}
+
+	if status == http.StatusOK {
+		rcv.mu.Lock()
+		rcv.payloads = append(rcv.payloads, payload)
+		rcv.mu.Unlock()
+	}
+
+	w.WriteHeader(status)
+	rcv.received <- struct{}{}
+}
+
+// wait blocks until n requests were received.
+func (rcv *receiver) wait(n int) {
+	rcv.t.Helper()
+
+	for range n {
+		select {
+		case <-rcv.received:
+		case <-time.After(5 * time.Second):
+			rcv.t.Fatal("timed out waiting for a delivery")
+		}
+	}
+}
+
+func (rcv *receiver) delivered() []map[string]any {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	return rcv.payloads
+}
+
+func testOptions() Options {
+	return Options{
+		Workers:        2,
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+		LogSize:        100,
+		Milestones:     []int{2, 3},
+		// The receivers listen on the loopback interface.
+		AllowPrivateHosts: true,
+	}
+}
+
+// startDispatcher registers an endpoint and runs a dispatcher on a new feed. Actions
+// published before the dispatcher subscribes are replayed from the feed buffer.
+func startDispatcher(
+	t *testing.T,
+	registration domain.Registration,
+	referrals func(context.Context) (map[int]int, error),
+) (*Dispatcher, Service, *domain.Endpoint, *feed.Feed) {
+	t.Helper()
+
+	repo, err := storage.NewRepository("")
+	require.NoError(t, err)
+
+	dispatcher := NewDispatcher(zap.NewNop().Sugar(), repo, referrals, testOptions())
+	svc := NewService(zap.NewNop().Sugar(), repo, dispatcher)
+
+	endpoint, err := svc.Register(t.Context(), registration)
+	require.NoError(t, err)
+
+	f := feed.New(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		dispatcher.Run(ctx, f)
+	}()
+
+	t.Cleanup(
+		func() {
+			cancel()
+			<-done
+		},
+	)
+
+	return dispatcher, svc, endpoint, f
+}
+
+func noReferrals(context.Context) (map[int]int, error) {
+	return map[int]int{}, nil
+}
+
+func Test_Dispatcher_delivers(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		events       []string
+		actionTypes  []string
+		action       *actiondomain.Action
+		wantPayloads []map[string]any
+	}{
+		{
+			name:   "should deliver a signed action.created event",
+			events: []string{domain.EventActionCreated},
+			action: &actiondomain.Action{ID: 1, Type: "VIEW_CONTACTS", UserID: 7, CreatedAt: createdAt},
+			wantPayloads: []map[string]any{
+				{
+					"type": domain.EventActionCreated,
+					"data": map[string]any{
+						"id": 1.0, "type": "VIEW_CONTACTS", "userId": 7.0, "createdAt": "2024-01-02T03:04:05Z",
+					},
+				},
+			},
+		},
+		{
+			name:   "should deliver a user.referred event for a referral",
+			events: []string{domain.EventUserReferred},
+			action: &actiondomain.Action{ID: 2, Type: "REFER_USER", UserID: 7, TargetUser: 9, CreatedAt: createdAt},
+			wantPayloads: []map[string]any{
+				{
+					"type": domain.EventUserReferred,
+					"data": map[string]any{
+						"userId": 7.0, "referredUserId": 9.0, "actionId": 2.0, "referredAt": "2024-01-02T03:04:05Z",
+					},
+				},
+			},
+		},
+		{
+			name:   "should compare the referral type case-insensitively",
+			events: []string{domain.EventUserReferred},
+			action: &actiondomain.Action{ID: 4, Type: "refer_user", UserID: 7, TargetUser: 9, CreatedAt: createdAt},
+			wantPayloads: []map[string]any{
+				{
+					"type": domain.EventUserReferred,
+					"data": map[string]any{
+						"userId": 7.0, "referredUserId": 9.0, "actionId": 4.0, "referredAt": "2024-01-02T03:04:05Z",
+					},
+				},
+			},
+		},
+		{
+			name:        "should filter action.created events by type",
+			events:      []string{domain.EventActionCreated},
+			actionTypes: []string{"refer_user"},
+			action:      &actiondomain.Action{ID: 3, Type: "VIEW_CONTACTS", UserID: 7, CreatedAt: createdAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rcv, server := newReceiver(t)
+
+				dispatcher, _, endpoint, f := startDispatcher(
+					t, domain.Registration{URL: server.URL, Events: tt.events, ActionTypes: tt.actionTypes}, noReferrals,
+				)
+				rcv.secret = endpoint.Secret
+
+				f.Publish(tt.action)
+
+				if tt.wantPayloads == nil {
+					time.Sleep(20 * time.Millisecond)
+					assert.Empty(t, dispatcher.Deliveries(endpoint.ID))
+
+					return
+				}
+
+				rcv.wait(len(tt.wantPayloads))
+
+				payloads := rcv.delivered()
+				require.Len(t, payloads, len(tt.wantPayloads))
+
+				for i, want := range tt.wantPayloads {
+					assert.Equal(t, want["type"], payloads[i]["type"])
+					assert.Equal(t, want["data"], payloads[i]["data"])
+					assert.NotEmpty(t, payloads[i]["id"])
+				}
+
+				require.Eventually(
+					t, func() bool {
+						deliveries := dispatcher.Deliveries(endpoint.ID)
+
+						return len(deliveries) == 1 && deliveries[0].Status == domain.DeliverySucceeded
+					}, time.Second, time.Millisecond,
+				)
+			},
+		)
+	}
+}
+
+func Test_Dispatcher_retries(t *testing.T) {
+	rcv, server := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable)
+
+	dispatcher, _, endpoint, f := startDispatcher(
+		t, domain.Registration{URL: server.URL, Events: []string{domain.EventActionCreated}}, noReferrals,
+	)
+	rcv.secret = endpoint.Secret
+
+	f.Publish(&actiondomain.Action{ID: 1, Type: "VIEW_CONTACTS", UserID: 7, CreatedAt: time.Now()})
+
+	rcv.wait(3)
+
+	require.Eventually(
+		t, func() bool {
+			deliveries := dispatcher.Deliveries(endpoint.ID)
+
+			return len(deliveries) == 1 && deliveries[0].Status == domain.DeliverySucceeded
+		}, time.Second, time.Millisecond,
+	)
+
+	attempts := dispatcher.Deliveries(endpoint.ID)[0].Attempts
+	require.Len(t, attempts, 3)
+	assert.Equal(t, http.StatusInternalServerError, attempts[0].StatusCode)
+	assert.Equal(t, "unexpected response status 500", attempts[0].Error)
+	assert.Equal(t, http.StatusServiceUnavailable, attempts[1].StatusCode)
+	assert.Equal(t, http.StatusOK, attempts[2].StatusCode)
+	assert.Empty(t, attempts[2].Error)
+	assert.Empty(t, dispatcher.DeadLetters())
+}
+
+func Test_Dispatcher_deadLetters(t *testing.T) {
+	var failing atomic.Bool
+
+	failing.Store(true)
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+
+				if failing.Load() {
+					w.WriteHeader(http.StatusBadGateway)
+
+					return
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	dispatcher, svc, endpoint, f := startDispatcher(
+		t, domain.Registration{URL: server.URL, Events: []string{domain.EventActionCreated}}, noReferrals,
+	)
+
+	f.Publish(&actiondomain.Action{ID: 1, Type: "VIEW_CONTACTS", UserID: 7, CreatedAt: time.Now()})
+
+	require.Eventually(
+		t, func() bool {
+			return len(dispatcher.DeadLetters()) == 1
+		}, time.Second, time.Millisecond,
+	)
+
+	dead := dispatcher.DeadLetters()[0]
+	assert.Equal(t, domain.DeliveryFailed, dead.Status)
+	assert.Len(t, dead.Attempts, 3, "should stop after MaxAttempts")
+	assert.Nil(t, dead.NextAttemptAt)
+	assert.EqualValues(t, 3, requests.Load())
+
+	failing.Store(false)
+
+	retried, err := svc.RetryDeadLetter(t.Context(), dead.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryPending, retried.Status)
+
+	require.Eventually(
+		t, func() bool {
+			deliveries := dispatcher.Deliveries(endpoint.ID)
+
+			return len(deliveries) == 1 && deliveries[0].Status == domain.DeliverySucceeded
+		}, time.Second, time.Millisecond,
+	)
+
+	assert.Len(t, dispatcher.Deliveries(endpoint.ID)[0].Attempts, 4)
+	assert.Empty(t, dispatcher.DeadLetters())
+
+	_, err = svc.RetryDeadLetter(t.Context(), dead.ID)
+	require.ErrorIs(t, err, ErrNotFound, "a redelivered delivery should leave the dead-letter list")
+}
+
+func Test_Dispatcher_referralMilestones(t *testing.T) {
+	rcv, server := newReceiver(t)
+
+	baseline := make(chan struct{})
+
+	var calls atomic.Int32
+
+	referrals := func(context.Context) (map[int]int, error) {
+		if calls.Add(1) == 1 {
+			defer close(baseline)
+
+			return map[int]int{7: 1, 8: 2}, nil
+		}
+
+		return map[int]int{7: 3, 8: 3}, nil
+	}
+
+	_, _, endpoint, f := startDispatcher(
+		t, domain.Registration{URL: server.URL, Events: []string{domain.EventReferralMilestone}}, referrals,
+	)
+	rcv.secret = endpoint.Secret
+
+	<-baseline
+
+	f.Publish(&actiondomain.Action{ID: 1, Type: "REFER_USER", UserID: 7, TargetUser: 20, CreatedAt: time.Now()})
+
+	// User 7 went from 1 to 3 referrals, past both milestones; user 8 from 2 to 3.
+	rcv.wait(3)
+	time.Sleep(20 * time.Millisecond)
+
+	var got []map[string]any
+	for _, payload := range rcv.delivered() {
+		got = append(got, payload["data"].(map[string]any))
+	}
+
+	assert.ElementsMatch(
+		t, []map[string]any{
+			{"userId": 7.0, "referrals": 3.0, "milestone": 2.0},
+			{"userId": 7.0, "referrals": 3.0, "milestone": 3.0},
+			{"userId": 8.0, "referrals": 3.0, "milestone": 3.0},
+		}, got,
+	)
+}
+
+func Test_Dispatcher_backoff(t *testing.T) {
+	d := &Dispatcher{opts: Options{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 3, want: 4 * time.Second},
+		{attempts: 4, want: 5 * time.Second},
+		{attempts: 60, want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, d.backoff(tt.attempts), "after %d attempts", tt.attempts)
+	}
+}
+
+func Test_Dispatcher_refusesPrivateHosts(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusNoContent)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	repo, err := storage.NewRepository("")
+	require.NoError(t, err)
+
+	// Stored as if the name of the endpoint had been pointed at the loopback interface
+	// after its registration.
+	require.NoError(
+		t, repo.Save(
+			t.Context(), &entity.Webhook{
+				ID: "wh_1", URL: server.URL, Events: []string{domain.EventActionCreated}, Secret: "whsec_1",
+				CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			},
+		),
+	)
+
+	opts := testOptions()
+	opts.AllowPrivateHosts = false
+	opts.MaxAttempts = 1
+
+	dispatcher := NewDispatcher(zap.NewNop().Sugar(), repo, noReferrals, opts)
+
+	f := feed.New(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go dispatcher.Run(ctx, f)
+
+	f.Publish(&actiondomain.Action{ID: 1, Type: "VIEW_CONTACTS", UserID: 7, CreatedAt: time.Now()})
+
+	require.Eventually(
+		t, func() bool {
+			return len(dispatcher.DeadLetters()) == 1
+		}, time.Second, time.Millisecond,
+	)
+
+	attempts := dispatcher.DeadLetters()[0].Attempts
+	require.Len(t, attempts, 1)
+	assert.Equal(t, "host is not publicly routable", attempts[0].Error)
+	assert.Zero(t, requests.Load(), "the receiver should not be reached")
+}
+
+func Test_attemptError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "should keep the response status",
+			err:  fmt.Errorf("%w %d", ErrUnexpectedStatus, http.StatusBadGateway),
+			want: "unexpected response status 502",
+		},
+		{
+			name: "should name a refused host",
+			err:  &url.Error{Op: "Post", URL: "http://x", Err: fmt.Errorf("dial: %w: 10.0.0.1", ErrPrivateHost)},
+			want: "host is not publicly routable",
+		},
+		{
+			name: "should sum up a timeout",
+			err:  &url.Error{Op: "Post", URL: "http://x", Err: context.DeadlineExceeded},
+			want: "request timed out",
+		},
+		{
+			name: "should hide how the connection failed",
+			err:  &url.Error{Op: "Post", URL: "http://x", Err: errors.New("dial tcp 10.0.0.1:22: connect: connection refused")},
+			want: "request failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, attemptError(tt.err))
+			},
+		)
+	}
+}
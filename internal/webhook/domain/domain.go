@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	actiondomain "surf_challenge/internal/action/domain"
+)
+
+// Event types an endpoint may subscribe to.
+const (
+	EventUserReferred      = "user.referred"
+	EventActionCreated     = "action.created"
+	EventReferralMilestone = "referral.milestone"
+)
+
+// EventTypes lists every event type, in documentation order.
+var EventTypes = []string{EventUserReferred, EventActionCreated, EventReferralMilestone}
+
+// Endpoint is a registered webhook receiver.
+type Endpoint struct {
+	ID     string
+	URL    string
+	Events []string
+	// ActionTypes restricts action.created events to these types, compared
+	// case-insensitively. Empty means every type.
+	ActionTypes []string
+	// Secret signs every delivery to the endpoint.
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Registration is the caller-provided part of an endpoint.
+type Registration struct {
+	URL         string
+	Events      []string
+	ActionTypes []string
+}
+
+// Subscribes tells whether event should be delivered to the endpoint.
+func (e *Endpoint) Subscribes(event *Event) bool {
+	if !slices.Contains(e.Events, event.Type) {
+		return false
+	}
+
+	if event.Type != EventActionCreated || len(e.ActionTypes) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(
+		e.ActionTypes, func(t string) bool {
+			return strings.EqualFold(t, event.Action.Type)
+		},
+	)
+}
+
+// Event is something that happened to the dataset. Action is set for user.referred
+// and action.created events, UserID, Referrals and Milestone for referral.milestone
+// ones.
+type Event struct {
+	ID        string
+	Type      string
+	CreatedAt time.Time
+	Action    *actiondomain.Action
+	UserID    int
+	Referrals int
+	Milestone int
+}
+
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are queued, or waiting for a retry.
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed deliveries ran out of attempts and are in the dead-letter list.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is the sending of one event to one endpoint, over one or more attempts.
+type Delivery struct {
+	ID         string
+	EndpointID string
+	Event      *Event
+	Status     DeliveryStatus
+	Attempts   []Attempt
+	// NextAttemptAt is set while a retry is scheduled.
+	NextAttemptAt *time.Time
+	CreatedAt     time.Time
+}
+
+// Attempt is one request made for a delivery. StatusCode is zero when no response
+// was received, Error being set instead.
+type Attempt struct {
+	Number     int
+	At         time.Time
+	Duration   time.Duration
+	StatusCode int
+	Error      string
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// ErrPrivateHost rejects webhook URLs reaching loopback, link-local or private
+// addresses, through which registrations could probe the internal network or the cloud
+// metadata endpoints (169.254.169.254). Options.AllowPrivateHosts lifts it for local
+// testing.
+var ErrPrivateHost = errors.New("host is not publicly routable")
+
+func privateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast()
+}
+
+// checkHost rejects a host naming or resolving to a private address. Names that do not
+// resolve pass: every address is checked again when dialled.
+func checkHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if privateAddr(addr) {
+			return fmt.Errorf("%w: %s", ErrPrivateHost, host)
+		}
+
+		return nil
+	}
+
+	if name := strings.ToLower(strings.TrimSuffix(host, ".")); name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrPrivateHost, host)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if privateAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateHost, host, addr)
+		}
+	}
+
+	return nil
+}
+
+// dialPublic refuses connections to private addresses. It checks the address actually
+// dialled, so that a name registered while public cannot be pointed at the internal
+// network later on.
+func dialPublic(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if privateAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateHost, addrPort.Addr())
+	}
+
+	return nil
+}
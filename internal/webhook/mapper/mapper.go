@@ -0,0 +1,50 @@
+package mapper
+
+import (
+	"time"
+
+	"surf_challenge/internal/webhook/domain"
+	"surf_challenge/internal/webhook/storage/entity"
+)
+
+func MapWebhooksEntToDomain(webhooks []*entity.Webhook) ([]*domain.Endpoint, error) {
+	endpoints := make([]*domain.Endpoint, 0, len(webhooks))
+
+	for _, w := range webhooks {
+		endpoint, err := MapWebhookEntToDomain(w)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, nil
+}
+
+func MapWebhookEntToDomain(w *entity.Webhook) (*domain.Endpoint, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Endpoint{
+		ID:          w.ID,
+		URL:         w.URL,
+		Events:      w.Events,
+		ActionTypes: w.ActionTypes,
+		Secret:      w.Secret,
+		CreatedAt:   createdAt,
+	}, nil
+}
+
+func MapEndpointToEnt(e *domain.Endpoint) *entity.Webhook {
+	return &entity.Webhook{
+		ID:          e.ID,
+		URL:         e.URL,
+		Events:      e.Events,
+		ActionTypes: e.ActionTypes,
+		Secret:      e.Secret,
+		CreatedAt:   e.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"surf_challenge/internal/webhook/domain"
+)
+
+// payload is the JSON body of a delivery. Data depends on the event type.
+type payload struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	CreatedAt string `json:"createdAt"`
+	Data      any    `json:"data"`
+}
+
+type actionData struct {
+	ID         int    `json:"id"`
+	Type       string `json:"type"`
+	UserID     int    `json:"userId"`
+	TargetUser int    `json:"targetUser,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type referralData struct {
+	UserID         int    `json:"userId"`
+	ReferredUserID int    `json:"referredUserId"`
+	ActionID       int    `json:"actionId"`
+	ReferredAt     string `json:"referredAt"`
+}
+
+type milestoneData struct {
+	UserID    int `json:"userId"`
+	Referrals int `json:"referrals"`
+	Milestone int `json:"milestone"`
+}
+
+func encodePayload(event *domain.Event) ([]byte, error) {
+	p := payload{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339),
+	}
+
+	switch event.Type {
+	case domain.EventActionCreated:
+		p.Data = actionData{
+			ID:         event.Action.ID,
+			Type:       event.Action.Type,
+			UserID:     event.Action.UserID,
+			TargetUser: event.Action.TargetUser,
+			CreatedAt:  event.Action.CreatedAt.Format(time.RFC3339),
+		}
+	case domain.EventUserReferred:
+		p.Data = referralData{
+			UserID:         event.Action.UserID,
+			ReferredUserID: event.Action.TargetUser,
+			ActionID:       event.Action.ID,
+			ReferredAt:     event.Action.CreatedAt.Format(time.RFC3339),
+		}
+	case domain.EventReferralMilestone:
+		p.Data = milestoneData{
+			UserID:    event.UserID,
+			Referrals: event.Referrals,
+			Milestone: event.Milestone,
+		}
+	}
+
+	return json.Marshal(p)
+}
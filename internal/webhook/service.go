@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/logger"
+	"surf_challenge/internal/webhook/domain"
+	"surf_challenge/internal/webhook/mapper"
+	"surf_challenge/internal/webhook/storage"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidWebhook matches every InvalidError.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// InvalidError rejects a registration, Reason being meant for the caller.
+type InvalidError struct {
+	Reason string
+}
+
+func (e *InvalidError) Error() string {
+	return "invalid webhook: " + e.Reason
+}
+
+func (e *InvalidError) Is(target error) bool {
+	return target == ErrInvalidWebhook
+}
+
+//go:generate mockgen -source=service.go -destination=service_mock.go -package=webhook
+type Service interface {
+	// Register stores a new endpoint with a generated ID and signing secret.
+	Register(ctx context.Context, registration domain.Registration) (*domain.Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]*domain.Endpoint, error)
+	GetEndpoint(ctx context.Context, id string) (*domain.Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	// ListDeliveries returns the logged deliveries to an endpoint, most recent first.
+	ListDeliveries(ctx context.Context, endpointID string) ([]*domain.Delivery, error)
+	// ListDeadLetters returns the deliveries that ran out of attempts, most recent first.
+	ListDeadLetters(ctx context.Context) ([]*domain.Delivery, error)
+	// RetryDeadLetter queues a dead-lettered delivery again.
+	RetryDeadLetter(ctx context.Context, deliveryID string) (*domain.Delivery, error)
+}
+
+type service struct {
+	logger     *zap.SugaredLogger
+	repo       storage.Repository
+	dispatcher *Dispatcher
+}
+
+func NewService(logger *zap.SugaredLogger, repo storage.Repository, dispatcher *Dispatcher) Service {
+	return &service{
+		logger:     logger,
+		repo:       repo,
+		dispatcher: dispatcher,
+	}
+}
+
+func (s *service) Register(ctx context.Context, registration domain.Registration) (*domain.Endpoint, error) {
+	logger.FromContext(ctx, s.logger).Infow("Register called", "url", registration.URL, "events", registration.Events)
+
+	err := s.validateRegistration(ctx, registration)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &domain.Endpoint{
+		ID:          newID("wh_"),
+		URL:         registration.URL,
+		Events:      slices.Compact(slices.Sorted(slices.Values(registration.Events))),
+		ActionTypes: registration.ActionTypes,
+		Secret:      newID("whsec_"),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	err = s.repo.Save(ctx, mapper.MapEndpointToEnt(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("saving webhook: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func (s *service) validateRegistration(ctx context.Context, registration domain.Registration) error {
+	u, err := url.Parse(registration.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &InvalidError{Reason: "url must be an absolute http or https URL"}
+	}
+
+	if s.dispatcher == nil || !s.dispatcher.opts.AllowPrivateHosts {
+		err = checkHost(ctx, u.Hostname())
+		if err != nil {
+			return &InvalidError{Reason: "url " + err.Error()}
+		}
+	}
+
+	if len(registration.Events) == 0 {
+		return &InvalidError{Reason: "events must not be empty"}
+	}
+
+	for _, event := range registration.Events {
+		if !slices.Contains(domain.EventTypes, event) {
+			return &InvalidError{
+				Reason: fmt.Sprintf("unknown event %q, expected one of %s", event, strings.Join(domain.EventTypes, ", ")),
+			}
+		}
+	}
+
+	if len(registration.ActionTypes) > 0 && !slices.Contains(registration.Events, domain.EventActionCreated) {
+		return &InvalidError{Reason: "actionTypes requires the " + domain.EventActionCreated + " event"}
+	}
+
+	if slices.Contains(registration.ActionTypes, "") {
+		return &InvalidError{Reason: "actionTypes must not contain empty types"}
+	}
+
+	return nil
+}
+
+func (s *service) ListEndpoints(ctx context.Context) ([]*domain.Endpoint, error) {
+	logger.FromContext(ctx, s.logger).Infow("ListEndpoints called")
+
+	webhooks, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing webhooks: %w", err)
+	}
+
+	return mapper.MapWebhooksEntToDomain(webhooks)
+}
+
+func (s *service) GetEndpoint(ctx context.Context, id string) (*domain.Endpoint, error) {
+	logger.FromContext(ctx, s.logger).Infow("GetEndpoint called", "id", id)
+
+	webhook, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("getting webhook: %w", err)
+	}
+
+	return mapper.MapWebhookEntToDomain(webhook)
+}
+
+func (s *service) DeleteEndpoint(ctx context.Context, id string) error {
+	logger.FromContext(ctx, s.logger).Infow("DeleteEndpoint called", "id", id)
+
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) ListDeliveries(ctx context.Context, endpointID string) ([]*domain.Delivery, error) {
+	logger.FromContext(ctx, s.logger).Infow("ListDeliveries called", "endpointID", endpointID)
+
+	_, err := s.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dispatcher.Deliveries(endpointID), nil
+}
+
+func (s *service) ListDeadLetters(ctx context.Context) ([]*domain.Delivery, error) {
+	logger.FromContext(ctx, s.logger).Infow("ListDeadLetters called")
+
+	return s.dispatcher.DeadLetters(), nil
+}
+
+func (s *service) RetryDeadLetter(ctx context.Context, deliveryID string) (*domain.Delivery, error) {
+	logger.FromContext(ctx, s.logger).Infow("RetryDeadLetter called", "deliveryID", deliveryID)
+
+	delivery, ok := s.dispatcher.Redeliver(deliveryID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return delivery, nil
+}
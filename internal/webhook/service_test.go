@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"surf_challenge/internal/webhook/domain"
+	"surf_challenge/internal/webhook/storage"
+	"surf_challenge/internal/webhook/storage/entity"
+)
+
+func Test_service_Register(t *testing.T) {
+	tests := []struct {
+		name         string
+		registration domain.Registration
+		mock         func(repo *storage.MockRepository)
+		wantEvents   []string
+		wantErr      string
+	}{
+		{
+			name: "should store the endpoint with a generated ID and secret",
+			registration: domain.Registration{
+				URL:    "https://example.com/hooks",
+				Events: []string{domain.EventUserReferred, domain.EventActionCreated, domain.EventUserReferred},
+			},
+			mock: func(repo *storage.MockRepository) {
+				repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, w *entity.Webhook) error {
+						assert.Equal(t, "https://example.com/hooks", w.URL)
+						assert.NotEmpty(t, w.Secret)
+
+						return nil
+					},
+				)
+			},
+			wantEvents: []string{domain.EventActionCreated, domain.EventUserReferred},
+		},
+		{
+			name:         "should reject a relative URL",
+			registration: domain.Registration{URL: "/hooks", Events: []string{domain.EventActionCreated}},
+			wantErr:      "invalid webhook: url must be an absolute http or https URL",
+		},
+		{
+			name:         "should reject a non HTTP URL",
+			registration: domain.Registration{URL: "ftp://example.com", Events: []string{domain.EventActionCreated}},
+			wantErr:      "invalid webhook: url must be an absolute http or https URL",
+		},
+		{
+			name:         "should reject an empty event list",
+			registration: domain.Registration{URL: "https://example.com/hooks"},
+			wantErr:      "invalid webhook: events must not be empty",
+		},
+		{
+			name:         "should reject an unknown event",
+			registration: domain.Registration{URL: "https://example.com/hooks", Events: []string{"user.deleted"}},
+			wantErr: `invalid webhook: unknown event "user.deleted", ` +
+				"expected one of user.referred, action.created, referral.milestone",
+		},
+		{
+			name: "should reject action types without the action.created event",
+			registration: domain.Registration{
+				URL: "https://example.com/hooks", Events: []string{domain.EventUserReferred}, ActionTypes: []string{"REFER_USER"},
+			},
+			wantErr: "invalid webhook: actionTypes requires the action.created event",
+		},
+		{
+			name:         "should reject the cloud metadata address",
+			registration: domain.Registration{URL: "http://169.254.169.254/latest", Events: []string{domain.EventActionCreated}},
+			wantErr:      "invalid webhook: url host is not publicly routable: 169.254.169.254",
+		},
+		{
+			name:         "should reject a loopback address",
+			registration: domain.Registration{URL: "http://[::1]:9000", Events: []string{domain.EventActionCreated}},
+			wantErr:      "invalid webhook: url host is not publicly routable: ::1",
+		},
+		{
+			name:         "should reject a private address",
+			registration: domain.Registration{URL: "https://10.0.0.8/hooks", Events: []string{domain.EventActionCreated}},
+			wantErr:      "invalid webhook: url host is not publicly routable: 10.0.0.8",
+		},
+		{
+			name:         "should reject localhost",
+			registration: domain.Registration{URL: "http://LocalHost:9000", Events: []string{domain.EventActionCreated}},
+			wantErr:      "invalid webhook: url host is not publicly routable: LocalHost",
+		},
+		{
+			name: "should fail when the endpoint cannot be stored",
+			registration: domain.Registration{
+				URL: "https://example.com/hooks", Events: []string{domain.EventActionCreated},
+			},
+			mock: func(repo *storage.MockRepository) {
+				repo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(assert.AnError)
+			},
+			wantErr: "saving webhook: " + assert.AnError.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repo := storage.NewMockRepository(ctrl)
+				if tt.mock != nil {
+					tt.mock(repo)
+				}
+
+				svc := NewService(zap.NewNop().Sugar(), repo, nil)
+
+				endpoint, err := svc.Register(t.Context(), tt.registration)
+				if tt.wantErr != "" {
+					require.EqualError(t, err, tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Regexp(t, `^wh_[0-9a-f]{32}$`, endpoint.ID)
+				assert.Regexp(t, `^whsec_[0-9a-f]{32}$`, endpoint.Secret)
+				assert.Equal(t, tt.wantEvents, endpoint.Events)
+			},
+		)
+	}
+}
+
+func Test_service_GetEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(repo *storage.MockRepository)
+		wantErr error
+	}{
+		{
+			name: "should map the stored webhook",
+			mock: func(repo *storage.MockRepository) {
+				repo.EXPECT().Get(gomock.Any(), "wh_1").Return(
+					&entity.Webhook{
+						ID: "wh_1", URL: "https://example.com", Events: []string{domain.EventActionCreated},
+						CreatedAt: "2024-01-02T03:04:05Z",
+					}, nil,
+				)
+			},
+		},
+		{
+			name: "should return not found for an unknown webhook",
+			mock: func(repo *storage.MockRepository) {
+				repo.EXPECT().Get(gomock.Any(), "wh_1").Return(nil, storage.ErrWebhookNotFound)
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "should wrap repository errors",
+			mock: func(repo *storage.MockRepository) {
+				repo.EXPECT().Get(gomock.Any(), "wh_1").Return(nil, assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				repo := storage.NewMockRepository(ctrl)
+				tt.mock(repo)
+
+				endpoint, err := NewService(zap.NewNop().Sugar(), repo, nil).GetEndpoint(t.Context(), "wh_1")
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, "wh_1", endpoint.ID)
+				assert.Equal(t, 2024, endpoint.CreatedAt.Year())
+			},
+		)
+	}
+}
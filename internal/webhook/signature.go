@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Surf-Event"
+	HeaderDelivery  = "X-Surf-Delivery"
+	HeaderTimestamp = "X-Surf-Timestamp"
+	HeaderSignature = "X-Surf-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the X-Surf-Signature value of body sent at timestamp: the hex encoded
+// HMAC-SHA256 of "<unix timestamp>.<body>" keyed with the endpoint secret. Signing the
+// timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the X-Surf-Timestamp and X-Surf-Signature headers of a delivery
+// against body, in constant time. It is what receivers are expected to run.
+func Verify(secret, timestamp, signature string, body []byte) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	want := Sign(secret, time.Unix(unix, 0), body)
+
+	return hmac.Equal([]byte(want), []byte(signature))
+}
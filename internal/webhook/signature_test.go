@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sign(t *testing.T) {
+	// echo -n '1700000000.{"id":"evt_1"}' | openssl dgst -sha256 -hmac whsec_test
+	got := Sign("whsec_test", time.Unix(1700000000, 0), []byte(`{"id":"evt_1"}`))
+
+	assert.Equal(t, "sha256=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925", got)
+}
+
+func Test_Verify(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	at := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	signature := Sign("whsec_test", at, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		want      bool
+	}{
+		{name: "should accept a valid signature", secret: "whsec_test", timestamp: timestamp, signature: signature, body: body, want: true},
+		{name: "should reject another secret", secret: "whsec_other", timestamp: timestamp, signature: signature, body: body},
+		{name: "should reject a tampered body", secret: "whsec_test", timestamp: timestamp, signature: signature, body: []byte(`{"id":"evt_2"}`)},
+		{name: "should reject another timestamp", secret: "whsec_test", timestamp: "1700000001", signature: signature, body: body},
+		{name: "should reject an invalid timestamp", secret: "whsec_test", timestamp: "now", signature: signature, body: body},
+		{name: "should reject a signature without prefix", secret: "whsec_test", timestamp: timestamp, signature: signature[len("sha256="):], body: body},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, Verify(tt.secret, tt.timestamp, tt.signature, tt.body))
+			},
+		)
+	}
+}
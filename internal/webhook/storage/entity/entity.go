@@ -0,0 +1,10 @@
+package entity
+
+type Webhook struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	ActionTypes []string `json:"actionTypes,omitempty"`
+	Secret      string   `json:"secret"`
+	CreatedAt   string   `json:"createdAt"`
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"surf_challenge/internal/webhook/storage/entity"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+//go:generate mockgen -source=repository.go -destination=repository_mock.go -package=storage
+type Repository interface {
+	// List returns the webhooks in registration order.
+	List(ctx context.Context) ([]*entity.Webhook, error)
+	Get(ctx context.Context, id string) (*entity.Webhook, error)
+	Save(ctx context.Context, webhook *entity.Webhook) error
+	Delete(ctx context.Context, id string) error
+}
+
+type webhookRepository struct {
+	path string
+
+	mu       sync.RWMutex
+	webhooks []*entity.Webhook
+}
+
+// NewRepository returns a repository persisting webhooks to the JSON file at path,
+// loading the ones already there. With an empty path, webhooks are kept in memory only.
+func NewRepository(path string) (Repository, error) {
+	wr := &webhookRepository{path: path}
+
+	if path == "" {
+		return wr, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return wr, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading webhooks file: %w", err)
+	}
+
+	err = json.Unmarshal(data, &wr.webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("decoding webhooks file: %w", err)
+	}
+
+	return wr, nil
+}
+
+func (wr *webhookRepository) List(_ context.Context) ([]*entity.Webhook, error) {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+
+	return slices.Clone(wr.webhooks), nil
+}
+
+func (wr *webhookRepository) Get(_ context.Context, id string) (*entity.Webhook, error) {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+
+	i := wr.index(id)
+	if i < 0 {
+		return nil, ErrWebhookNotFound
+	}
+
+	return wr.webhooks[i], nil
+}
+
+// Save adds webhook, or replaces the one with the same ID.
+func (wr *webhookRepository) Save(_ context.Context, webhook *entity.Webhook) error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	webhooks := slices.Clone(wr.webhooks)
+
+	if i := wr.index(webhook.ID); i >= 0 {
+		webhooks[i] = webhook
+	} else {
+		webhooks = append(webhooks, webhook)
+	}
+
+	return wr.commit(webhooks)
+}
+
+func (wr *webhookRepository) Delete(_ context.Context, id string) error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	i := wr.index(id)
+	if i < 0 {
+		return ErrWebhookNotFound
+	}
+
+	return wr.commit(slices.Delete(slices.Clone(wr.webhooks), i, i+1))
+}
+
+// index must be called with the lock held.
+func (wr *webhookRepository) index(id string) int {
+	return slices.IndexFunc(
+		wr.webhooks, func(w *entity.Webhook) bool {
+			return w.ID == id
+		},
+	)
+}
+
+// commit writes webhooks to the file, then makes them current. The file is replaced
+// atomically so that a crash never leaves it half written. It must be called with the
+// lock held.
+func (wr *webhookRepository) commit(webhooks []*entity.Webhook) error {
+	if wr.path != "" {
+		data, err := json.MarshalIndent(webhooks, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding webhooks: %w", err)
+		}
+
+		err = writeFileAtomic(wr.path, append(data, '\n'))
+		if err != nil {
+			return fmt.Errorf("writing webhooks file: %w", err)
+		}
+	}
+
+	wr.webhooks = webhooks
+
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over
+// path. The file holds signing secrets, hence the owner-only permissions.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
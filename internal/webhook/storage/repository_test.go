@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"surf_challenge/internal/webhook/storage/entity"
+)
+
+func Test_webhookRepository_persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+
+	repo, err := NewRepository(path)
+	require.NoError(t, err)
+
+	first := &entity.Webhook{ID: "wh_1", URL: "https://a.example", Events: []string{"action.created"}, Secret: "s1"}
+	second := &entity.Webhook{ID: "wh_2", URL: "https://b.example", Events: []string{"user.referred"}, Secret: "s2"}
+
+	require.NoError(t, repo.Save(t.Context(), first))
+	require.NoError(t, repo.Save(t.Context(), second))
+	require.NoError(t, repo.Delete(t.Context(), "wh_1"))
+	require.ErrorIs(t, repo.Delete(t.Context(), "wh_1"), ErrWebhookNotFound)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the file holds signing secrets")
+
+	reopened, err := NewRepository(path)
+	require.NoError(t, err)
+
+	webhooks, err := reopened.List(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Webhook{second}, webhooks)
+
+	_, err = reopened.Get(t.Context(), "wh_1")
+	require.ErrorIs(t, err, ErrWebhookNotFound)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should be cleaned up")
+}
+
+func Test_NewRepository(t *testing.T) {
+	corrupt := filepath.Join(t.TempDir(), "webhooks.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte(`[{"id":`), 0o600))
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "should keep webhooks in memory without a path"},
+		{name: "should start empty when the file does not exist", path: filepath.Join(t.TempDir(), "missing.json")},
+		{name: "should fail on a corrupt file", path: corrupt, wantErr: "decoding webhooks file"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				repo, err := NewRepository(tt.path)
+				if tt.wantErr != "" {
+					require.ErrorContains(t, err, tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+
+				webhooks, err := repo.List(t.Context())
+				require.NoError(t, err)
+				assert.Empty(t, webhooks)
+			},
+		)
+	}
+}